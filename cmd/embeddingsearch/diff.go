@@ -0,0 +1,80 @@
+// Package main implements the diff command, which summarizes how the tables
+// of two embedding databases differ.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/cache"
+	"github.com/eda-labs/eda-embeddingsearch/internal/embedding"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// runDiff loads the databases at oldPath and newPath and prints how their
+// tables differ, as text or JSON
+func runDiff(oldPath, newPath string, jsonOutput bool, loaderOpts ...embedding.LoaderOption) error {
+	loader := embedding.NewLoader(cache.NewCacheManager(), loaderOpts...)
+	before, err := loader.Load(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", oldPath, err)
+	}
+	after, err := loader.Load(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", newPath, err)
+	}
+
+	diff := models.DiffDBs(before, after)
+	if jsonOutput {
+		jsonData, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	printDiff(diff)
+	return nil
+}
+
+func printDiff(diff models.DBDiff) {
+	if diff.Empty() {
+		fmt.Println("No differences")
+		return
+	}
+
+	printKeys("Added", "+", diff.Added)
+	printKeys("Removed", "-", diff.Removed)
+	if len(diff.Changed) > 0 {
+		fmt.Printf("Changed tables (%d):\n", len(diff.Changed))
+		for _, change := range diff.Changed {
+			printChange(change)
+		}
+	}
+	fmt.Printf("\n%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+}
+
+func printKeys(heading, marker string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	fmt.Printf("%s tables (%d):\n", heading, len(keys))
+	for _, key := range keys {
+		fmt.Printf("  %s %s\n", marker, key)
+	}
+}
+
+func printChange(change models.TableChange) {
+	fmt.Printf("  ~ %s\n", change.Key)
+	if len(change.AddedFields) > 0 {
+		fmt.Printf("      fields added: %s\n", strings.Join(change.AddedFields, ", "))
+	}
+	if len(change.RemovedFields) > 0 {
+		fmt.Printf("      fields removed: %s\n", strings.Join(change.RemovedFields, ", "))
+	}
+	if change.OldDescription != change.NewDescription {
+		fmt.Printf("      description: %q → %q\n", change.OldDescription, change.NewDescription)
+	}
+}
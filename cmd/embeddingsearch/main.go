@@ -2,28 +2,81 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/eda-labs/eda-embeddingsearch/internal/cache"
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
 	"github.com/eda-labs/eda-embeddingsearch/internal/download"
 	"github.com/eda-labs/eda-embeddingsearch/internal/embedding"
+	"github.com/eda-labs/eda-embeddingsearch/internal/eql"
+	"github.com/eda-labs/eda-embeddingsearch/internal/output"
+	"github.com/eda-labs/eda-embeddingsearch/internal/querylog"
 	"github.com/eda-labs/eda-embeddingsearch/internal/search"
 	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
 )
 
+// stringList is a flag value that can be repeated to collect several strings
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
 func main() {
 	dbPath := flag.String("db", "", "path to embedding db (auto-downloads if not specified)")
-	jsonOutput := flag.Bool("json", false, "output results as JSON")
+	jsonOutput := flag.Bool("json", false, "output results as JSON (same as -format json)")
+	format := flag.String("format", "text", "output format: "+strings.Join(output.Names, ", "))
+	templateText := flag.String("template", "", "render results with this Go text/template instead of -format")
 	platformStr := flag.String("platform", "", "force platform type (srl or sros)")
+	version := flag.String("version", "", "embeddings release version (default: newest known for the platform; only the newest release of each platform is listed so far)")
 	setup := flag.Bool("setup", false, "download all embeddings and build caches")
+	extract := flag.Bool("extract", false, "print only the EQL query of the top match")
+	gnmi := flag.Bool("gnmi", false, "print only the gNMI path of the top match")
+	dryRun := flag.Bool("dry-run", false, "report which embeddings would be downloaded and exit")
+	minScore := flag.Float64("min-score", 0, "drop results scoring below this; exit 1 if none remain")
+	maxFields := flag.Int("max-fields", constants.MaxExtractedFields, "maximum fields in the EQL projection (0 = unlimited)")
+	fullScan := flag.Bool("full", false, "score every table instead of using the inverted index")
+	all := flag.Bool("all", false, "print every scored result instead of the top 10")
+	noColor := flag.Bool("no-color", false, "disable colored text output")
+	showStats := flag.Bool("stats", false, "print search timing and candidate counts to stderr")
+	multi := flag.Bool("multi", false, "search each concept of a query joined by and/plus/along with separately")
+	referenceText := flag.Bool("reference-text", false, "include the text each matched table was indexed from")
+	namespace := flag.String("namespace", "", "replace the .namespace prefix of EQL paths with this namespace")
+	strict := flag.Bool("strict", false, "fail on misspelled words instead of correcting them")
+	verbose := flag.Bool("verbose", false, "report database statistics and problems on stderr")
+	noCache := flag.Bool("no-cache", false, "parse the JSON database and rebuild the index without reading or writing caches")
+	timeout := flag.Duration("timeout", 0, "abort the whole command after this long, e.g. 30s (0 = no limit)")
+	queryLogPath := flag.String("query-log", "", "append each query and its top match as JSON to this file (default: off)")
+	queryLogSize := flag.Int("query-log-size", constants.DefaultQueryLogSizeMB, "rotate the query log after this many megabytes (0 = never)")
+	embedDir := flag.String("embed-dir", "", "embeddings directory (default: $"+download.EmbeddingsDirEnv+" or ~/.eda/vscode/embeddings)")
+	var include, exclude stringList
+	flag.Var(&include, "include", "only return table paths containing this substring (repeatable)")
+	flag.Var(&exclude, "exclude", "drop table paths containing this substring (repeatable)")
 	flag.Parse()
 
+	ctx, cancel := withDeadline(*timeout)
+	defer cancel()
+
+	var loaderOpts []embedding.LoaderOption
+	if *noCache {
+		loaderOpts = append(loaderOpts, embedding.WithoutCache())
+	}
+
 	if *setup || (flag.NArg() > 0 && flag.Arg(0) == "setup") {
-		if err := runSetup(); err != nil {
+		if err := runSetup(ctx, *embedDir); err != nil {
+			exitIfTimedOut(ctx, *timeout)
 			fmt.Fprintf(os.Stderr, "setup failed: %v\n", err)
 			os.Exit(1)
 		}
@@ -31,34 +84,100 @@ func main() {
 		return
 	}
 
-	if flag.NArg() == 0 {
-		fmt.Println("usage: embeddingsearch [-json] [-platform srl|sros] <query>")
+	args := flag.Args()
+	if len(args) > 0 && args[0] == "versions" {
+		printVersions(*embedDir)
+		return
+	}
+	if len(args) > 0 && args[0] == "preload" {
+		platforms := []models.EmbeddingType{models.SRL, models.SROS}
+		if *platformStr != "" {
+			platform, err := resolvePlatform(*platformStr, "")
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			platforms = []models.EmbeddingType{platform}
+		}
+		if err := runPreload(ctx, *embedDir, *version, platforms); err != nil {
+			exitIfTimedOut(ctx, *timeout)
+			fmt.Fprintf(os.Stderr, "preload failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "schema" {
+		printSchema()
+		return
+	}
+	if len(args) > 0 && args[0] == "diff" {
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "usage: embeddingsearch [-json] diff <old.json> <new.json>")
+			os.Exit(1)
+		}
+		if err := runDiff(args[1], args[2], *jsonOutput, loaderOpts...); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(args) > 0 && args[0] == "extract" {
+		*extract = true
+		args = args[1:]
+	}
+
+	// A dry run only needs the platform, which the query may imply
+	if *dryRun {
+		platform, err := resolvePlatform(*platformStr, strings.Join(args, " "))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := printDryRun(*dbPath, *embedDir, platform, *version); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) == 0 {
+		fmt.Println("usage: embeddingsearch [-json|-format text|json|yaml|-template tmpl|-extract|-gnmi] [-dry-run] [-version v] [-embed-dir dir] [-verbose] [-no-color] [-no-cache] [-timeout d] [-stats] [-full] [-multi] [-strict] [-reference-text] [-namespace name] [-min-score s] [-max-fields n] [-query-log file] [-platform srl|sros] [-include path] [-exclude path] <query>")
 		fmt.Println("\nExamples:")
 		fmt.Println("  embeddingsearch 'show interface statistics for leaf1'")
 		fmt.Println("  embeddingsearch 'get top 5 processes by memory usage'")
 		fmt.Println("  embeddingsearch 'critical alarms from the last hour'")
 		fmt.Println("  embeddingsearch 'interface traffic on spine1 every 5 seconds'")
 		fmt.Println("  embeddingsearch -json 'show interfaces'  # Output as JSON")
+		fmt.Println("  embeddingsearch -format yaml 'show interfaces'  # Output as YAML")
+		fmt.Println("  embeddingsearch -template '{{(index .Results 0).EQLQuery.String}}' 'show interfaces'")
+		fmt.Println("  embeddingsearch -include .state. -exclude protocols 'interfaces'")
+		fmt.Println("  embeddingsearch extract 'show interfaces'  # Print only the EQL")
+		fmt.Println("  embeddingsearch versions  # List known embeddings releases")
+		fmt.Println("  embeddingsearch schema  # Print the JSON Schema of -json output")
+		fmt.Println("  embeddingsearch diff old.json new.json  # Compare two embeddings releases")
 		return
 	}
 
-	query := strings.Join(flag.Args(), " ")
+	query := strings.Join(args, " ")
+	if strings.TrimSpace(query) == "" {
+		fmt.Fprintln(os.Stderr, "query is empty")
+		os.Exit(1)
+	}
 
-	// Determine platform
-	var platform models.EmbeddingType
-	if *platformStr != "" {
-		switch strings.ToLower(*platformStr) {
-		case "sros":
-			platform = models.SROS
-		case "srl":
-			platform = models.SRL
-		default:
-			fmt.Fprintf(os.Stderr, "Invalid platform: %s (must be 'srl' or 'sros')\n", *platformStr)
-			os.Exit(1)
-		}
-	} else {
-		// Auto-detect from query if not specified
-		platform = download.DetectPlatformFromQuery(query)
+	formatter, err := selectFormatter(*format, *templateText, *jsonOutput)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if _, ok := formatter.(output.MultiFormatter); *multi && (!ok || *extract || *gnmi) {
+		fmt.Fprintln(os.Stderr, "-multi prints text, json or yaml and cannot be combined with -template, -extract or -gnmi")
+		os.Exit(1)
+	}
+
+	platform, err := resolvePlatform(*platformStr, query)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	// Determine the database path
@@ -67,104 +186,322 @@ func main() {
 		finalDBPath = *dbPath
 	} else {
 		// Auto-download embeddings if not specified
-		downloader := download.NewDownloader()
-		var err error
-		finalDBPath, err = downloader.EnsureEmbeddings(platform)
+		downloader := download.NewDownloaderWithDir(*embedDir)
+		finalDBPath, err = downloader.EnsureEmbeddingsContext(ctx, platform, *version)
 		if err != nil {
+			exitIfTimedOut(ctx, *timeout)
 			fmt.Fprintf(os.Stderr, "failed to download embeddings: %v\n", err)
 			os.Exit(1)
 		}
 	}
 
-	loader := embedding.NewLoader(cache.NewCacheManager())
+	loader := embedding.NewLoader(cache.NewCacheManager(), loaderOpts...)
 	db, err := loader.Load(finalDBPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load db: %v\n", err)
 		os.Exit(1)
 	}
 
+	if *verbose {
+		printLoadStats(finalDBPath, db)
+	}
+
 	// Create search engine and perform search
-	engine := search.NewEngine(db)
-	results := engine.IndexedSearch(query)
+	opts := []search.Option{
+		search.WithPathFilter(search.PathFilter{
+			Include: include,
+			Exclude: exclude,
+		}),
+		search.WithMaxFields(*maxFields),
+		search.WithMinScore(*minScore),
+	}
+	if *referenceText {
+		opts = append(opts, search.WithReferenceText())
+	}
+	if *all {
+		opts = append(opts, search.WithMaxResults(0))
+	}
+	engine := search.NewEngine(db, opts...)
+	queryLog := openQueryLog(*queryLogPath, *queryLogSize)
+	defer closeQueryLog(queryLog)
+	if *strict {
+		rejectTypos(engine.TypoCorrections(query))
+	}
 
-	if len(results) == 0 {
-		if *jsonOutput {
-			fmt.Println(`{"error": "No matches found", "results": []}`)
-		} else {
-			fmt.Println("No matches found")
+	if *multi {
+		concepts := engine.MultiSearch(query)
+		for _, concept := range concepts {
+			queryLog.Log(concept.Concept, platform, concept.Results)
+		}
+		if *namespace != "" {
+			for _, concept := range concepts {
+				qualifyNamespace(concept.Results, *namespace)
+			}
+		}
+		multiOpts := output.Options{Color: output.UseColor(*noColor), All: *all}
+		if err := formatter.(output.MultiFormatter).FormatMulti(os.Stdout, concepts, multiOpts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 		return
 	}
 
-	if *jsonOutput {
-		outputJSON(results)
-	} else {
-		outputText(results)
+	run := engine.SearchWithStats
+	if *fullScan {
+		run = engine.FullSearchWithStats
+	}
+	results, stats := run(query)
+	queryLog.Log(query, platform, results)
+	if *showStats {
+		fmt.Fprintf(os.Stderr, "Search stats: %s\n", stats)
 	}
-}
 
-func outputJSON(results []models.SearchResult) {
-	type JSONOutput struct {
-		TopMatch *models.SearchResult   `json:"topMatch"`
-		Others   []*models.SearchResult `json:"others,omitempty"`
+	if len(results) > 0 {
+		warnInvalid(&results[0])
+		warnUnappliedThresholds(query, results[0].Key)
+	}
+	if *namespace != "" {
+		qualifyNamespace(results, *namespace)
 	}
 
-	output := JSONOutput{TopMatch: &results[0]}
+	if *extract {
+		outputExtract(results)
+		return
+	}
+	if *gnmi {
+		outputGNMI(results)
+		return
+	}
 
-	// Add other matches (limit to 9 more for total of 10)
-	maxOthers := 9
-	if len(results)-1 < maxOthers {
-		maxOthers = len(results) - 1
+	unmatched := eql.UnmatchedNodeTokens(query)
+	synonyms := engine.AppliedSynonyms(query)
+	if *verbose {
+		printSynonyms(synonyms)
 	}
-	if maxOthers > 0 {
-		output.Others = make([]*models.SearchResult, maxOthers)
-		for i := 0; i < maxOthers; i++ {
-			output.Others[i] = &results[i+1]
-		}
+
+	if _, isText := formatter.(output.Text); isText && len(results) > 0 {
+		warnUnmatched(unmatched)
+	}
+	formatOpts := output.Options{UnmatchedTokens: unmatched, AppliedSynonyms: synonyms, Color: output.UseColor(*noColor), All: *all}
+	if err := formatter.Format(os.Stdout, results, formatOpts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	jsonData, err := json.MarshalIndent(output, "", "  ")
+	// Scripts setting a quality bar need to tell "nothing good enough"
+	// apart from a match
+	if len(results) == 0 && *minScore > 0 {
+		os.Exit(1)
+	}
+}
+
+// openQueryLog opens the query log at path, or returns nil, which logs
+// nothing, when path is empty
+func openQueryLog(path string, sizeMB int) *querylog.Logger {
+	if path == "" {
+		return nil
+	}
+	queryLog, err := querylog.Open(path, int64(sizeMB)<<20, constants.QueryLogBackups)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to marshal JSON: %v\n", err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-	fmt.Println(string(jsonData))
+	return queryLog
 }
 
-func outputText(results []models.SearchResult) {
-	// Display top match
-	top := results[0]
-	fmt.Printf("Top match (score: %.2f):\n%s\n", top.Score, top.EQLQuery.String())
+func closeQueryLog(queryLog *querylog.Logger) {
+	if err := queryLog.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to close query log: %v\n", err)
+	}
+}
 
-	if top.Description != "" {
-		fmt.Printf("\nDescription: %s\n", top.Description)
+// selectFormatter picks the output formatter from -template, -json and
+// -format, in that order of precedence
+func selectFormatter(format, templateText string, jsonOutput bool) (output.Formatter, error) {
+	switch {
+	case templateText != "":
+		return output.NewTemplate(templateText)
+	case jsonOutput:
+		return output.JSON{}, nil
+	default:
+		return output.ForName(format)
 	}
-	if len(top.AvailableFields) > 0 {
-		fmt.Printf("Available fields: %s\n", strings.Join(top.AvailableFields, ", "))
+}
+
+// resolvePlatform picks the platform from the -platform flag, falling back to
+// detection from the query text
+func resolvePlatform(platformStr, query string) (models.EmbeddingType, error) {
+	switch strings.ToLower(platformStr) {
+	case "":
+		return download.DetectPlatformFromQuery(query), nil
+	case "sros":
+		return models.SROS, nil
+	case "srl":
+		return models.SRL, nil
+	default:
+		return models.SRL, fmt.Errorf("invalid platform: %s (must be 'srl' or 'sros')", platformStr)
 	}
+}
 
-	// Show other matches (limit to 9 more for total of 10)
-	if len(results) > 1 {
-		fmt.Println("\nOther possible matches:")
-		maxOthers := 9
-		if len(results)-1 < maxOthers {
-			maxOthers = len(results) - 1
+// printDryRun reports where embeddings would come from without downloading
+func printDryRun(dbPath, embedDir string, platform models.EmbeddingType, version string) error {
+	if dbPath != "" {
+		fmt.Printf("Using local database %s; nothing would be downloaded\n", dbPath)
+		return nil
+	}
+
+	source, err := download.NewDownloaderWithDir(embedDir).Describe(platform, version)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Platform:  %s\n", source.Platform)
+	fmt.Printf("Version:   %s\n", source.Version)
+	fmt.Printf("URL:       %s\n", source.URL)
+	fmt.Printf("File:      %s\n", source.FileName)
+	fmt.Printf("Directory: %s\n", source.Dir)
+	if source.Present {
+		fmt.Printf("Status:    %s already exists; nothing would be downloaded\n", source.Path)
+	} else {
+		fmt.Printf("Status:    would download and extract to %s\n", source.Path)
+	}
+	return nil
+}
+
+// printVersions lists every known release and whether it is downloaded
+func printVersions(embedDir string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PLATFORM\tVERSION\tSTATUS\tSIZE\tMODIFIED\tFILE")
+	for _, source := range download.NewDownloaderWithDir(embedDir).DescribeAll() {
+		status, size, modified := "not downloaded", "-", "-"
+		if source.Present {
+			status = "downloaded"
+			size = fmt.Sprintf("%.1f MB", float64(source.Size)/(1<<20))
+			modified = source.ModTime.Format("2006-01-02 15:04")
 		}
-		for i := 1; i <= maxOthers; i++ {
-			other := results[i]
-			fmt.Printf("%d. %s (score: %.2f)\n", i, other.EQLQuery.String(), other.Score)
-			if other.Description != "" {
-				fmt.Printf("   Description: %s\n", other.Description)
-			}
-			if len(other.AvailableFields) > 0 {
-				fmt.Printf("   Available fields: %s\n", strings.Join(other.AvailableFields, ", "))
-			}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", source.Platform, source.Version, status, size, modified, source.Path)
+	}
+	_ = w.Flush()
+}
+
+// printLoadStats reports on stderr what was loaded and whether the database
+// passed validation
+func printLoadStats(path string, db *models.EmbeddingDB) {
+	stats := embedding.ComputeStats(db)
+	fmt.Fprintf(os.Stderr, "Loaded %d entries (%d indexed terms) from %s\n", stats.Entries, stats.IndexedTerms, path)
+	fmt.Fprintf(os.Stderr, "Fields per entry: min %d, max %d, median %.1f\n", stats.MinFields, stats.MaxFields, stats.MedianFields)
+	if stats.ParseFailures > 0 {
+		fmt.Fprintf(os.Stderr, "Entries with unparseable Text: %d\n", stats.ParseFailures)
+	}
+	if err := db.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// outputExtract prints only the top match's EQL so scripts can capture it,
+// exiting non-zero when nothing matched
+func outputExtract(results []models.SearchResult) {
+	err := output.Extract(os.Stdout, results)
+	switch {
+	case errors.Is(err, output.ErrNoMatches):
+		fmt.Fprintln(os.Stderr, "No matches found")
+		os.Exit(1)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// outputGNMI prints only the top match's gNMI path, exiting non-zero when
+// nothing matched or the table has no device path
+func outputGNMI(results []models.SearchResult) {
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, "No matches found")
+		os.Exit(1)
+	}
+	path := models.EQLTableToGNMIPath(results[0].Key)
+	if path == "" {
+		fmt.Fprintf(os.Stderr, "%s has no gNMI path\n", results[0].Key)
+		os.Exit(1)
+	}
+	fmt.Println(path)
+}
+
+// warnUnmatched tells the user about node-like tokens that did not become a
+// node filter
+func warnUnmatched(tokens []string) {
+	if len(tokens) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: no node filter applied for unrecognized node name(s): %s\n", strings.Join(tokens, ", "))
+	}
+}
+
+// rejectTypos lists misspelled words on stderr and exits non-zero, so
+// callers in strict mode can clean their input instead of having it
+// corrected
+func rejectTypos(typos []search.Synonym) {
+	if len(typos) == 0 {
+		return
+	}
+	corrections := make([]string, len(typos))
+	for i, typo := range typos {
+		corrections[i] = fmt.Sprintf("%s (did you mean %s?)", typo.From, typo.To)
+	}
+	fmt.Fprintf(os.Stderr, "Misspelled word(s): %s\n", strings.Join(corrections, ", "))
+	os.Exit(1)
+}
+
+// printSynonyms reports on stderr which query words were replaced before
+// searching
+func printSynonyms(synonyms []search.Synonym) {
+	if len(synonyms) == 0 {
+		return
+	}
+	mappings := make([]string, len(synonyms))
+	for i, synonym := range synonyms {
+		mappings[i] = synonym.From + " → " + synonym.To
+	}
+	fmt.Fprintf(os.Stderr, "Applied synonyms: %s\n", strings.Join(mappings, ", "))
+}
+
+// warnInvalid reports on stderr problems EQLQuery.Validate finds in the top
+// match
+func warnInvalid(result *models.SearchResult) {
+	for _, problem := range result.EQLQuery.Validate(result.AvailableFields) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", problem)
+	}
+}
+
+// warnUnappliedThresholds tells the user about thresholds with a unit that
+// the top match has no field for, which add no condition
+func warnUnappliedThresholds(query, table string) {
+	if phrases := eql.UnappliedUnitThresholds(query, table); len(phrases) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: no condition applied for threshold(s) without a field on %s: %s\n", table, strings.Join(phrases, ", "))
+	}
+}
+
+// qualifyNamespace rewrites the EQL of results, including per-node
+// comparison queries, to address the given namespace
+func qualifyNamespace(results []models.SearchResult, namespace string) {
+	for i := range results {
+		results[i].EQLQuery = results[i].EQLQuery.WithNamespace(namespace)
+		for j := range results[i].Comparison {
+			results[i].Comparison[j].EQLQuery = results[i].Comparison[j].EQLQuery.WithNamespace(namespace)
 		}
 	}
 }
 
-func runSetup() error {
-	downloader := download.NewDownloader()
+// printSchema prints the JSON Schema of the -json output, including the
+// no-match and -multi documents
+func printSchema() {
+	jsonData, err := json.MarshalIndent(output.Schema(), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal JSON: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(jsonData))
+}
+
+func runSetup(ctx context.Context, embedDir string) error {
+	downloader := download.NewDownloaderWithDir(embedDir)
 	loader := embedding.NewLoader(cache.NewCacheManager())
 
 	platforms := []models.EmbeddingType{models.SRL, models.SROS}
@@ -177,7 +514,7 @@ func runSetup() error {
 		}
 
 		fmt.Printf("Downloading embeddings for %s...\n", name)
-		path, err := downloader.EnsureEmbeddings(platform)
+		path, err := downloader.EnsureEmbeddingsContext(ctx, platform, "")
 		if err != nil {
 			return err
 		}
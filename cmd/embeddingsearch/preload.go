@@ -0,0 +1,40 @@
+// Package main implements the preload command, which downloads, indexes and
+// caches embeddings ahead of time so the first real query is fast.
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/preload"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// runPreload prepares the embeddings of each platform, printing how long
+// each step took
+func runPreload(ctx context.Context, embedDir, version string, platforms []models.EmbeddingType) error {
+	preloader := preload.New(embedDir)
+
+	start := time.Now()
+	for _, platform := range platforms {
+		timing, err := preloader.Platform(ctx, platform, version)
+		if err != nil {
+			return fmt.Errorf("preload %s: %w", platform, err)
+		}
+		printPreloadTiming(timing)
+	}
+	fmt.Printf("Preload completed in %s\n", time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func printPreloadTiming(timing preload.Timing) {
+	step := "indexed and cached"
+	if timing.Cached {
+		step = "loaded from cache"
+	}
+	fmt.Printf("%s: %s\n", timing.Platform, timing.Path)
+	fmt.Printf("  download: %s\n", timing.Download.Round(time.Millisecond))
+	fmt.Printf("  load:     %s (%d entries, %s)\n", timing.Load.Round(time.Millisecond), timing.Entries, step)
+	fmt.Printf("  total:    %s\n", (timing.Download + timing.Load).Round(time.Millisecond))
+}
@@ -0,0 +1,46 @@
+// Package main implements the -timeout flag, which bounds the whole command
+// so automation never waits on a stalled download or a huge database load.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// timeoutExitCode is the exit status after -timeout expires, the same as
+// timeout(1) uses
+const timeoutExitCode = 124
+
+// withDeadline returns a context that expires after timeout, or never when
+// timeout is not positive. Downloads stop as soon as it expires; loading and
+// searching cannot be interrupted, so a watchdog ends the process instead.
+func withDeadline(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	go func() {
+		<-ctx.Done()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			exitTimedOut(timeout)
+		}
+	}()
+	return ctx, cancel
+}
+
+// exitIfTimedOut ends the command with the timeout message once the
+// deadline of ctx has passed, so a failure it caused is reported as such
+func exitIfTimedOut(ctx context.Context, timeout time.Duration) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		exitTimedOut(timeout)
+	}
+}
+
+func exitTimedOut(timeout time.Duration) {
+	fmt.Fprintf(os.Stderr, "timed out after %s\n", timeout)
+	os.Exit(timeoutExitCode)
+}
@@ -16,6 +16,7 @@ import (
 type CacheManager interface {
 	GetFromMemory(path string) (*models.EmbeddingDB, bool)
 	StoreInMemory(path string, db *models.EmbeddingDB)
+	ClearMemory()
 	GetBinaryCachePath(jsonPath string) string
 	SaveBinaryCache(db *models.EmbeddingDB, cachePath string) error
 	LoadBinaryCache(cachePath string) (*models.EmbeddingDB, error)
@@ -50,6 +51,13 @@ func (m *DefaultCacheManager) StoreInMemory(path string, db *models.EmbeddingDB)
 	m.dbCache[path] = db
 }
 
+// ClearMemory drops every database from the memory cache
+func (m *DefaultCacheManager) ClearMemory() {
+	m.cacheMutex.Lock()
+	defer m.cacheMutex.Unlock()
+	clear(m.dbCache)
+}
+
 // GetBinaryCachePath returns the path for the binary cache file
 func (m *DefaultCacheManager) GetBinaryCachePath(jsonPath string) string {
 	dir := filepath.Dir(jsonPath)
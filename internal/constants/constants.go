@@ -19,15 +19,30 @@ const (
 	MaxSearchResults = 10
 	MaxCandidates    = 20
 
+	// Goroutines used to build the inverted index
+	MaxWorkers = 8
+
 	// EQL constants
+	MaxExtractedFields         = 5
+	BroadFieldThreshold        = 12
 	DefaultHighMemoryThreshold = 80
 	MaxLimitValue              = 1000
-	DefaultTopLimit            = 10
+	DefaultTopLimit            = 10 // "top" and "highest" without a count
+	DefaultRecentLimit         = 20 // "recent" or "latest" alarms
 	RealTimeIntervalSeconds    = 1
+	MinDeltaMicroseconds       = 100
+
+	// Private 16-bit AS number range (RFC 6996)
+	PrivateASNMin = 64512
+	PrivateASNMax = 65534
 
 	// Tokenizer constants
 	MinTokenLength = 2
 
 	// File permissions
 	DirPermissions = 0o755
+
+	// Query log rotation
+	DefaultQueryLogSizeMB = 10 // rotate the query log after this many MiB
+	QueryLogBackups       = 3  // rotated query logs kept
 )
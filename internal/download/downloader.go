@@ -5,12 +5,14 @@ package download
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
 	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
@@ -26,45 +28,140 @@ const (
 
 // Downloader handles downloading and managing embeddings
 type Downloader struct {
-	embedDir     string
-	srlURL       string
-	srosURL      string
-	srlFileName  string
-	srosFileName string
+	embedDir string
 }
 
-// NewDownloader creates a new embeddings downloader
+// EmbeddingsDirEnv names the environment variable that relocates the
+// embeddings directory, e.g. for containers with a read-only home
+const EmbeddingsDirEnv = "EDA_EMBEDDINGS_DIR"
+
+// NewDownloader creates a new embeddings downloader using DefaultEmbeddingsDir
 func NewDownloader() *Downloader {
-	homeDir, _ := os.UserHomeDir()
-	embedDir := filepath.Join(homeDir, ".eda", "vscode", "embeddings")
+	return NewDownloaderWithDir("")
+}
 
-	return &Downloader{
-		embedDir:     embedDir,
-		srlURL:       srlEmbeddingURL,
-		srosURL:      srosEmbeddingURL,
-		srlFileName:  srlEmbeddingFile,
-		srosFileName: srosEmbeddingFile,
+// NewDownloaderWithDir creates a downloader storing embeddings in embedDir.
+// An empty embedDir selects DefaultEmbeddingsDir. A leading ~ is expanded to
+// the home directory.
+func NewDownloaderWithDir(embedDir string) *Downloader {
+	if embedDir == "" {
+		embedDir = DefaultEmbeddingsDir()
 	}
+	return &Downloader{embedDir: expandHome(embedDir)}
 }
 
-// GetEmbeddingPath returns the path for the specified platform
-func (d *Downloader) GetEmbeddingPath(platform models.EmbeddingType) string {
-	switch platform {
-	case models.SROS:
-		return filepath.Join(d.embedDir, d.srosFileName)
-	default:
-		return filepath.Join(d.embedDir, d.srlFileName)
+// DefaultEmbeddingsDir returns $EDA_EMBEDDINGS_DIR when set and
+// ~/.eda/vscode/embeddings otherwise
+func DefaultEmbeddingsDir() string {
+	if dir := os.Getenv(EmbeddingsDirEnv); dir != "" {
+		return expandHome(dir)
+	}
+	return filepath.Join(homeDir(), ".eda", "vscode", "embeddings")
+}
+
+// homeDir returns the user's home directory, or the system temp directory
+// when it cannot be determined. An empty home would otherwise turn the
+// embeddings directory into a path relative to the working directory.
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		return home
+	}
+	return os.TempDir()
+}
+
+// expandHome replaces a leading ~ with the home directory, since neither
+// the file APIs nor a quoted flag value expand it
+func expandHome(path string) string {
+	if path == "~" {
+		return homeDir()
+	}
+	if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return filepath.Join(homeDir(), path[2:])
+	}
+	return path
+}
+
+// Source describes where the embeddings for a platform come from and where
+// they are stored locally
+type Source struct {
+	Platform models.EmbeddingType
+	Version  string
+	URL      string
+	FileName string
+	Dir      string
+	Path     string
+	Present  bool      // the embedding file already exists locally
+	Size     int64     // size of the local file, when present
+	ModTime  time.Time // modification time of the local file, when present
+}
+
+// Describe reports the source and destination EnsureEmbeddings would use for
+// a platform and version, without touching the network or creating
+// directories
+func (d *Downloader) Describe(platform models.EmbeddingType, version string) (Source, error) {
+	release, err := FindRelease(platform, version)
+	if err != nil {
+		return Source{}, err
+	}
+	return d.describeRelease(release), nil
+}
+
+// DescribeAll reports the source and local state of every known release
+func (d *Downloader) DescribeAll() []Source {
+	sources := make([]Source, 0, len(releases))
+	for _, release := range releases {
+		sources = append(sources, d.describeRelease(release))
+	}
+	return sources
+}
+
+func (d *Downloader) describeRelease(release Release) Source {
+	source := Source{
+		Platform: release.Platform,
+		Version:  release.Version,
+		URL:      release.URL,
+		FileName: release.FileName,
+		Dir:      d.embedDir,
+		Path:     d.releasePath(release),
 	}
+	if info, err := os.Stat(source.Path); err == nil {
+		source.Present = true
+		source.Size = info.Size()
+		source.ModTime = info.ModTime()
+	}
+	return source
+}
+
+// GetEmbeddingPath returns the path of the newest release for the platform
+func (d *Downloader) GetEmbeddingPath(platform models.EmbeddingType) string {
+	release, _ := FindRelease(platform, "")
+	return d.releasePath(release)
+}
+
+func (d *Downloader) releasePath(release Release) string {
+	return filepath.Join(d.embedDir, release.FileName)
 }
 
-// EnsureEmbeddings ensures embeddings are downloaded for the specified platform
-func (d *Downloader) EnsureEmbeddings(platform models.EmbeddingType) (string, error) {
+// EnsureEmbeddings ensures embeddings are downloaded for the specified
+// platform and version. An empty version selects the newest release.
+func (d *Downloader) EnsureEmbeddings(platform models.EmbeddingType, version string) (string, error) {
+	return d.EnsureEmbeddingsContext(context.Background(), platform, version)
+}
+
+// EnsureEmbeddingsContext is EnsureEmbeddings with a context that aborts
+// the download when it is canceled or its deadline passes
+func (d *Downloader) EnsureEmbeddingsContext(ctx context.Context, platform models.EmbeddingType, version string) (string, error) {
+	release, err := FindRelease(platform, version)
+	if err != nil {
+		return "", err
+	}
+
 	// Create embeddings directory
 	if err := os.MkdirAll(d.embedDir, constants.DirPermissions); err != nil {
 		return "", fmt.Errorf("failed to create embeddings directory: %v", err)
 	}
 
-	path := d.GetEmbeddingPath(platform)
+	path := d.releasePath(release)
 
 	// Check if embeddings already exist
 	if _, err := os.Stat(path); err == nil {
@@ -72,7 +169,7 @@ func (d *Downloader) EnsureEmbeddings(platform models.EmbeddingType) (string, er
 	}
 
 	// Download embeddings
-	if err := d.downloadEmbeddings(platform); err != nil {
+	if err := d.downloadEmbeddings(ctx, release); err != nil {
 		return "", err
 	}
 
@@ -96,11 +193,13 @@ func DetectPlatformFromQuery(query string) models.EmbeddingType {
 	return models.SRL
 }
 
-func (d *Downloader) downloadEmbeddings(platform models.EmbeddingType) error {
-	url, expectedFile := d.getURLAndFile(platform)
-
+func (d *Downloader) downloadEmbeddings(ctx context.Context, release Release) error {
 	// Download the tar.gz file
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, release.URL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to download embeddings: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to download embeddings: %v", err)
 	}
@@ -118,7 +217,7 @@ func (d *Downloader) downloadEmbeddings(platform models.EmbeddingType) error {
 	}
 	// Embeddings extracted successfully
 	// Verify the expected file exists
-	expectedPath := filepath.Join(d.embedDir, expectedFile)
+	expectedPath := d.releasePath(release)
 	if _, err := os.Stat(expectedPath); err != nil {
 		return fmt.Errorf("expected embedding file not found after extraction: %s", expectedPath)
 	}
@@ -126,13 +225,27 @@ func (d *Downloader) downloadEmbeddings(platform models.EmbeddingType) error {
 	return nil
 }
 
-func (d *Downloader) getURLAndFile(platform models.EmbeddingType) (url, file string) {
-	switch platform {
-	case models.SROS:
-		return d.srosURL, d.srosFileName
-	default:
-		return d.srlURL, d.srlFileName
+// extractFile writes r to target through a temporary file, so an aborted
+// download never leaves a truncated database that looks complete
+func extractFile(target string, r io.Reader) error {
+	partial := target + ".part"
+	outFile, err := os.Create(partial)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
 	}
+	if _, err := io.Copy(outFile, r); err != nil {
+		_ = outFile.Close()
+		_ = os.Remove(partial)
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	if err := outFile.Close(); err != nil {
+		_ = os.Remove(partial)
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	if err := os.Rename(partial, target); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	return nil
 }
 
 func (d *Downloader) extractTarGz(r io.Reader) error {
@@ -163,15 +276,9 @@ func (d *Downloader) extractTarGz(r io.Reader) error {
 				return fmt.Errorf("failed to create directory: %v", err)
 			}
 		case tar.TypeReg:
-			outFile, err := os.Create(target)
-			if err != nil {
-				return fmt.Errorf("failed to create file: %v", err)
-			}
-			if _, err := io.Copy(outFile, tr); err != nil {
-				_ = outFile.Close()
-				return fmt.Errorf("failed to write file: %v", err)
+			if err := extractFile(target, tr); err != nil {
+				return err
 			}
-			_ = outFile.Close()
 		}
 	}
 
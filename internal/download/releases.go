@@ -0,0 +1,59 @@
+// Package download lists the embedding releases that can be downloaded for
+// each platform.
+package download
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Release is a published embeddings archive for one platform version
+type Release struct {
+	Platform models.EmbeddingType
+	Version  string
+	URL      string
+	FileName string // embedding file inside the archive
+}
+
+// releases lists the known releases, newest first within each platform.
+// File names carry the version, so several releases can be stored side by
+// side in the embeddings directory. Only the newest release of each
+// platform is listed so far; older ones are added here, with their own
+// URL and file name, once their archives are confirmed.
+var releases = []Release{
+	{
+		Platform: models.SRL,
+		Version:  "25.3.3",
+		URL:      srlEmbeddingURL,
+		FileName: srlEmbeddingFile,
+	},
+	{
+		Platform: models.SROS,
+		Version:  "25.3.r2",
+		URL:      srosEmbeddingURL,
+		FileName: srosEmbeddingFile,
+	},
+}
+
+// Releases returns the known releases for all platforms
+func Releases() []Release {
+	return append([]Release(nil), releases...)
+}
+
+// FindRelease returns the release of platform with the given version. An
+// empty version selects the newest release.
+func FindRelease(platform models.EmbeddingType, version string) (Release, error) {
+	var known []string
+	for _, release := range releases {
+		if release.Platform != platform {
+			continue
+		}
+		if version == "" || strings.EqualFold(release.Version, version) {
+			return release, nil
+		}
+		known = append(known, release.Version)
+	}
+	return Release{}, fmt.Errorf("unknown %s version %q (known: %s)", platform, version, strings.Join(known, ", "))
+}
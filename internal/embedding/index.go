@@ -3,55 +3,31 @@
 package embedding
 
 import (
+	"runtime"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
 	"github.com/eda-labs/eda-embeddingsearch/internal/search"
 	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
 )
 
-// BuildInvertedIndex creates an inverted index for fast word-based lookups
-func BuildInvertedIndex(db *models.EmbeddingDB) {
-	if len(db.InvertedIndex) > 0 {
-		// Already built
-		return
-	}
-
-	db.InvertedIndex = make(map[string][]string)
-
-	for key, entry := range db.Table {
-		// Index key tokens
-		keyTokens := search.Tokenize(key)
-		for _, token := range keyTokens {
-			db.InvertedIndex[token] = append(db.InvertedIndex[token], key)
-		}
-
-		// Index reference text tokens (limited to avoid memory bloat)
-		refTokens := search.Tokenize(entry.ReferenceText)
-		for i, token := range refTokens {
-			if i > 50 { // Limit to first 50 tokens
-				break
-			}
-			db.InvertedIndex[token] = append(db.InvertedIndex[token], key)
-		}
+// FromTable builds an indexed database from an in-memory table, ready to
+// pass to search.NewEngine without reading any file
+func FromTable(table map[string]models.EmbeddingEntry) *models.EmbeddingDB {
+	db := models.NewEmbeddingDB(table)
+	BuildInvertedIndex(db)
+	return db
+}
 
-		// Also index Text field for better matching
-		textTokens := search.Tokenize(entry.Text)
-		for i, token := range textTokens {
-			if i > 30 { // Limit tokens from Text field
-				break
-			}
-			db.InvertedIndex[token] = append(db.InvertedIndex[token], key)
-		}
-	}
+// BuildInvertedIndex creates an inverted index for fast word-based lookups,
+// and a description index over the full table descriptions, using up to
+// constants.MaxWorkers goroutines
+func BuildInvertedIndex(db *models.EmbeddingDB) {
+	BuildInvertedIndexWithWorkers(db, min(constants.MaxWorkers, runtime.NumCPU()))
+}
 
-	// Deduplicate entries
-	for word, keys := range db.InvertedIndex {
-		seen := make(map[string]bool)
-		unique := make([]string, 0, len(keys))
-		for _, key := range keys {
-			if !seen[key] {
-				seen[key] = true
-				unique = append(unique, key)
-			}
-		}
-		db.InvertedIndex[word] = unique
-	}
+// BuildInvertedIndexWithWorkers builds the indexes on the given number of
+// goroutines; see search.BuildIndexes. An index that is already built is
+// kept.
+func BuildInvertedIndexWithWorkers(db *models.EmbeddingDB, workers int) {
+	search.BuildIndexes(db, workers)
 }
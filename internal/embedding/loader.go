@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/eda-labs/eda-embeddingsearch/internal/cache"
 	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
@@ -14,17 +15,47 @@ import (
 // Loader handles loading of embedding databases
 type Loader struct {
 	cacheManager cache.CacheManager
+	noCache      bool
+}
+
+// LoaderOption configures a Loader
+type LoaderOption func(*Loader)
+
+// WithoutCache makes the loader ignore the memory and binary caches: every
+// Load parses the JSON and rebuilds the index, and no cache is written.
+// Use it while editing a database whose modification time does not reveal
+// the change.
+func WithoutCache() LoaderOption {
+	return func(l *Loader) {
+		l.noCache = true
+	}
 }
 
 // NewLoader creates a new loader with the specified cache manager
-func NewLoader(cacheManager cache.CacheManager) *Loader {
-	return &Loader{
+func NewLoader(cacheManager cache.CacheManager, opts ...LoaderOption) *Loader {
+	l := &Loader{
 		cacheManager: cacheManager,
 	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Close drops the databases the loader kept in the memory cache, so a
+// long-running host releases them once its engines are closed. Binary
+// caches on disk are kept. Later loads read them again.
+func (l *Loader) Close() error {
+	l.cacheManager.ClearMemory()
+	return nil
 }
 
 // Load loads an embedding database from disk with caching
 func (l *Loader) Load(path string) (*models.EmbeddingDB, error) {
+	if l.noCache {
+		return l.loadUncached(path)
+	}
+
 	// Check memory cache first
 	if db := l.loadFromMemoryCache(path); db != nil {
 		return db, nil
@@ -33,11 +64,36 @@ func (l *Loader) Load(path string) (*models.EmbeddingDB, error) {
 	// Check binary cache
 	cachePath := l.cacheManager.GetBinaryCachePath(path)
 	if db := l.loadFromBinaryCache(path, cachePath); db != nil {
+		nameDatabase(db, path)
 		return db, nil
 	}
 
 	// Load from JSON file
-	return l.loadFromJSON(path, cachePath)
+	db, err := l.loadFromJSON(path, cachePath)
+	if err != nil {
+		return nil, err
+	}
+	nameDatabase(db, path)
+	return db, nil
+}
+
+// loadUncached parses the JSON and builds the index without touching any
+// cache
+func (l *Loader) loadUncached(path string) (*models.EmbeddingDB, error) {
+	db, err := l.loadJSONFile(path)
+	if err != nil {
+		return nil, err
+	}
+	BuildInvertedIndex(db)
+	nameDatabase(db, path)
+	return db, nil
+}
+
+// nameDatabase labels a database with its file name unless it already has one
+func nameDatabase(db *models.EmbeddingDB, path string) {
+	if db.Name == "" {
+		db.Name = filepath.Base(path)
+	}
 }
 
 func (l *Loader) loadFromMemoryCache(path string) *models.EmbeddingDB {
@@ -54,6 +110,10 @@ func (l *Loader) loadFromBinaryCache(path, cachePath string) *models.EmbeddingDB
 
 	db, err := l.cacheManager.LoadBinaryCache(cachePath)
 	if err == nil {
+		// Caches written before the description index existed lack it
+		if len(db.DescriptionIndex) == 0 {
+			l.postProcessDatabase(db, cachePath)
+		}
 		l.cacheManager.StoreInMemory(path, db)
 		return db
 	}
@@ -0,0 +1,58 @@
+// Package embedding computes summary statistics that give a quick health read
+// on a loaded embedding database.
+package embedding
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Stats summarizes a loaded database
+type Stats struct {
+	Entries       int
+	IndexedTerms  int
+	MinFields     int
+	MaxFields     int
+	MedianFields  float64
+	ParseFailures int // entries whose Text is not valid JSON
+}
+
+// ComputeStats parses each entry's Text once and reports entry, term and
+// per-entry field counts. Entries that fail to parse are counted but left
+// out of the field statistics.
+func ComputeStats(db *models.EmbeddingDB) Stats {
+	stats := Stats{
+		Entries:      len(db.Table),
+		IndexedTerms: len(db.InvertedIndex),
+	}
+
+	counts := make([]int, 0, len(db.Table))
+	for _, entry := range db.Table {
+		var text struct {
+			Fields []string `json:"Fields"`
+		}
+		if err := json.Unmarshal([]byte(entry.Text), &text); err != nil {
+			stats.ParseFailures++
+			continue
+		}
+		counts = append(counts, len(text.Fields))
+	}
+
+	if len(counts) == 0 {
+		return stats
+	}
+
+	sort.Ints(counts)
+	stats.MinFields = counts[0]
+	stats.MaxFields = counts[len(counts)-1]
+
+	mid := len(counts) / 2
+	if len(counts)%2 == 1 {
+		stats.MedianFields = float64(counts[mid])
+	} else {
+		stats.MedianFields = float64(counts[mid-1]+counts[mid]) / 2
+	}
+	return stats
+}
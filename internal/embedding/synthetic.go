@@ -0,0 +1,61 @@
+// Package embedding provides a synthetic embedding database so tests and
+// benchmarks can exercise the search engine without downloading embeddings.
+package embedding
+
+import (
+	"fmt"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// syntheticTable describes a representative slice of real SRL and SROS tables
+var syntheticTable = []struct {
+	key         string
+	description string
+	fields      []string
+}{
+	{".namespace.node.srl.interface", "The list of named interfaces on the device", []string{"admin-state", "description", "mtu", "name", "oper-state", "last-change"}},
+	{".namespace.node.srl.interface.statistics", "Interface statistics counters", []string{"in-octets", "out-octets", "in-packets", "out-packets", "in-error-packets", "out-error-packets", "in-discards", "out-discards", "last-clear"}},
+	{".namespace.node.srl.interface.ethernet", "Ethernet configuration and state of the interface", []string{"port-speed", "physical-medium", "aggregate-id", "hw-mac-address"}},
+	{".namespace.node.srl.interface.transceiver", "Transceiver information for the interface", []string{"form-factor", "vendor", "vendor-part-number", "vendor-serial-number", "connector-type", "ethernet-pmd"}},
+	{".namespace.node.srl.interface.subinterface", "The list of subinterfaces (logical interfaces) associated with a physical interface", []string{"index", "admin-state", "oper-state", "description", "type"}},
+	{".namespace.node.srl.interface.subinterface.ipv4.arp.neighbor", "List of static and dynamic ARP cache entries", []string{"ipv4-address", "link-layer-address", "origin", "expiration-time"}},
+	{".namespace.node.srl.network-instance", "Network instances configured on the device", []string{"name", "type", "admin-state", "oper-state", "description"}},
+	{".namespace.node.srl.network-instance.protocols.bgp", "Top-level BGP configuration and state", []string{"admin-state", "autonomous-system", "router-id"}},
+	{".namespace.node.srl.network-instance.protocols.bgp.neighbor", "List of BGP peers", []string{"peer-address", "peer-as", "peer-type", "admin-state", "session-state", "last-state"}},
+	{".namespace.node.srl.network-instance.route-table.ipv4-unicast.route", "IPv4 routes in the route table", []string{"ipv4-prefix", "route-type", "route-owner", "metric", "preference", "active"}},
+	{".namespace.node.srl.platform.control.memory", "Memory utilization of the control module", []string{"physical", "free", "utilization"}},
+	{".namespace.node.srl.platform.control.cpu", "CPU utilization of the control module", []string{"index", "total", "user", "system"}},
+	{".namespace.node.srl.system.app-management.application.statistics", "Process statistics of applications", []string{"memory-usage", "memory-utilization", "cpu-utilization", "restart-count"}},
+	{".namespace.node.srl.system.lldp.interface", "LLDP interface configuration and state", []string{"name", "admin-state"}},
+	{".namespace.node.srl.system.information", "System information such as version and uptime", []string{"version", "description", "current-datetime", "last-booted"}},
+	{".namespace.node.srl.acl.interface", "ACL interface bindings", []string{"interface-id"}},
+	{".namespace.node.sros.state.port", "Port state information", []string{"port-id", "oper-state", "admin-state", "description"}},
+	{".namespace.node.sros.state.port.ethernet", "Ethernet port state", []string{"oper-speed", "mtu", "hw-mac-address"}},
+	{".namespace.node.sros.configure.port", "Port configuration", []string{"port-id", "admin-state", "description"}},
+	{".namespace.node.sros.configure.port.ethernet", "Ethernet port configuration", []string{"speed", "mtu", "mode"}},
+	{".namespace.node.sros.state.router.interface", "Router interface state", []string{"interface-name", "oper-state", "if-index"}},
+	{".namespace.node.sros.state.router.bgp.neighbor", "BGP neighbor state", []string{"ip-address", "session-state", "peer-as"}},
+	{".namespace.alarms.v1.current-alarm", "Currently active alarms", []string{"severity", "time-created", "resource", "type"}},
+}
+
+// NewSyntheticDB builds an indexed in-memory database with the requested
+// number of entries. The first entries mirror real SRL and SROS tables; any
+// additional entries are derived from them so larger databases keep a
+// realistic shape.
+func NewSyntheticDB(size int) *models.EmbeddingDB {
+	db := models.NewEmbeddingDB(make(map[string]models.EmbeddingEntry, size))
+	db.Name = "synthetic"
+
+	for i := 0; i < size; i++ {
+		base := syntheticTable[i%len(syntheticTable)]
+		key := base.key
+		if round := i / len(syntheticTable); round > 0 {
+			key = fmt.Sprintf("%s.extension-%d", base.key, round)
+		}
+		db.Table[key] = models.NewEmbeddingEntry(key+" "+base.description, base.description, base.fields)
+	}
+
+	BuildInvertedIndex(db)
+	return db
+}
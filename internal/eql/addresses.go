@@ -0,0 +1,83 @@
+// Package eql extracts IPv4 and IPv6 addresses and prefixes from queries.
+package eql
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// addressTokens returns the whitespace separated words of query with
+// surrounding punctuation trimmed, which is where addresses are looked for
+func addressTokens(query string) []string {
+	words := strings.Fields(query)
+	for i, word := range words {
+		words[i] = strings.Trim(word, `"'(),;?!`)
+	}
+	return words
+}
+
+// ExtractPrefixes returns the IPv4 and IPv6 prefixes written in CIDR
+// notation, such as 10.0.0.0/8 or 2001:db8::/32, in query order
+func ExtractPrefixes(query string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, word := range addressTokens(query) {
+		if !strings.Contains(word, "/") {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(word); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// ExtractAddresses returns the IPv4 and IPv6 addresses written without a
+// prefix length, in query order
+func ExtractAddresses(query string) []netip.Addr {
+	var addrs []netip.Addr
+	for _, word := range addressTokens(query) {
+		// A single dot or colon is a version number or an AS:value pair
+		if strings.Count(word, ".") != 3 && strings.Count(word, ":") < 2 {
+			continue
+		}
+		if addr, err := netip.ParseAddr(word); err == nil {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// MaskAddresses blanks out IP addresses, prefixes and MAC addresses so the
+// tokenizer does not split them into stray numbers and hex pairs
+func MaskAddresses(query string) string {
+	for _, word := range strings.Fields(query) {
+		token := strings.Trim(word, `"'(),;?!`)
+		if isAddress(token) {
+			query = strings.Replace(query, token, " ", 1)
+		}
+	}
+	return query
+}
+
+// AddressFamilies returns "ipv4" and/or "ipv6" for the families of the
+// addresses and prefixes in query
+func AddressFamilies(query string) []string {
+	var v4, v6 bool
+	for _, prefix := range ExtractPrefixes(query) {
+		v4 = v4 || prefix.Addr().Is4()
+		v6 = v6 || prefix.Addr().Is6()
+	}
+	for _, addr := range ExtractAddresses(query) {
+		v4 = v4 || addr.Is4()
+		v6 = v6 || addr.Is6()
+	}
+
+	var families []string
+	if v4 {
+		families = append(families, "ipv4")
+	}
+	if v6 {
+		families = append(families, "ipv6")
+	}
+	return families
+}
@@ -0,0 +1,98 @@
+// Package eql detects the address families a routing-protocol query refers
+// to, such as "ipv6 bgp neighbors", and filters AFI-SAFI tables on them.
+package eql
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// afiSafiPattern matches the address family words of a query
+var afiSafiPattern = regexp.MustCompile(`\b(?:ipv4|ipv6|v4|v6|dual[- ]stack|evpn)\b`)
+
+// multicastPattern marks the multicast SAFI; unicast is the default
+var multicastPattern = regexp.MustCompile(`\bmulticast\b`)
+
+// ExtractAFISAFI returns the AFI-SAFIs a query names, in query order and in
+// SR Linux naming: "ipv4-unicast", "ipv6-unicast", their multicast variants
+// and "evpn". "dual-stack" names both IPv4 and IPv6.
+func ExtractAFISAFI(query string) []string {
+	lower := strings.ToLower(query)
+	safi := "unicast"
+	if multicastPattern.MatchString(lower) {
+		safi = "multicast"
+	}
+
+	var families []string
+	add := func(family string) {
+		if !slices.Contains(families, family) {
+			families = append(families, family)
+		}
+	}
+	for _, word := range afiSafiPattern.FindAllString(lower, -1) {
+		switch word {
+		case "ipv4", "v4":
+			add("ipv4-" + safi)
+		case "ipv6", "v6":
+			add("ipv6-" + safi)
+		case "evpn":
+			add("evpn")
+		default:
+			add("ipv4-" + safi)
+			add("ipv6-" + safi)
+		}
+	}
+	return families
+}
+
+// IsRoutingProtocolTable reports whether a table belongs to a routing
+// protocol that negotiates address families
+func IsRoutingProtocolTable(tablePath string) bool {
+	return strings.Contains(tablePath, ".bgp") || strings.Contains(tablePath, ".isis") || strings.Contains(tablePath, ".ospf")
+}
+
+// afiSafiField returns the field naming the address family on a routing
+// protocol table: afi-safi-name on SR Linux AFI-SAFI lists, family on SR
+// OS BGP tables, or "" when the table does not select by family
+func afiSafiField(tablePath string) string {
+	platform, ok := models.PlatformFromTable(tablePath)
+	switch {
+	case !ok || !IsRoutingProtocolTable(tablePath):
+		return ""
+	case platform == models.SRL && strings.HasSuffix(tablePath, ".afi-safi"):
+		return "afi-safi-name"
+	case platform == models.SROS && strings.Contains(tablePath, ".bgp"):
+		return "family"
+	default:
+		return ""
+	}
+}
+
+// srosFamilies maps SR Linux AFI-SAFI names to SR OS BGP family names
+var srosFamilies = map[string]string{
+	"ipv4-unicast":   "ipv4",
+	"ipv6-unicast":   "ipv6",
+	"ipv4-multicast": "mcast-ipv4",
+	"ipv6-multicast": "mcast-ipv6",
+	"evpn":           "evpn",
+}
+
+// applyAFISAFI filters routing protocol tables on the address families the
+// query names
+func applyAFISAFI(lower, tablePath string, conditions map[string]string) {
+	field := afiSafiField(tablePath)
+	families := ExtractAFISAFI(lower)
+	if field == "" || len(families) == 0 {
+		return
+	}
+
+	if field == "family" {
+		for i, family := range families {
+			families[i] = srosFamilies[family]
+		}
+	}
+	conditions[field] = matchAny(families)
+}
@@ -0,0 +1,20 @@
+// Package eql detects requests to compare the same data across nodes.
+package eql
+
+import "regexp"
+
+// comparePattern matches wording that asks for a side-by-side comparison
+var comparePattern = regexp.MustCompile(`(?i)\b(?:compare|comparing|comparison|versus|vs)\b`)
+
+// ComparedNodes returns the nodes a query compares, such as leaf1 and leaf2
+// in "compare interface errors between leaf1 and leaf2". It returns nil
+// unless the query asks for a comparison and names at least two nodes.
+func ComparedNodes(query string) []string {
+	if !comparePattern.MatchString(query) {
+		return nil
+	}
+	if nodes := ExtractNodeNames(query); len(nodes) >= 2 {
+		return nodes
+	}
+	return nil
+}
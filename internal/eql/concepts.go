@@ -0,0 +1,62 @@
+// Package eql segments queries that ask about several distinct concepts,
+// such as "bgp neighbors and their interfaces".
+package eql
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var (
+	// conceptConjunction matches the words joining two concepts
+	conceptConjunction = regexp.MustCompile(`(?i)\s+(?:and|plus|along\s+with|as\s+well\s+as|together\s+with)\s+`)
+
+	// betweenPattern matches a "between X" range opener left of a conjunction
+	betweenPattern = regexp.MustCompile(`(?i)\bbetween\s+\S+$`)
+)
+
+// SplitConcepts splits a query on conjunctions such as "and", "plus" and
+// "along with". Conjunctions joining node names, numbers, addresses or the
+// bounds of a "between" range are kept, so "interfaces on leaf1 and leaf2"
+// stays whole. Whether the segments really name distinct tables is left to
+// the caller.
+func SplitConcepts(query string) []string {
+	nodes := ExtractNodeNames(query)
+
+	var segments []string
+	start := 0
+	for _, loc := range conceptConjunction.FindAllStringIndex(query, -1) {
+		if joinsValues(query[start:loc[0]], query[loc[1]:], nodes) {
+			continue
+		}
+		segments = appendSegment(segments, query[start:loc[0]])
+		start = loc[1]
+	}
+	return appendSegment(segments, query[start:])
+}
+
+// joinsValues reports whether a conjunction between before and after joins
+// two values of one condition rather than two concepts
+func joinsValues(before, after string, nodes []string) bool {
+	if betweenPattern.MatchString(before) {
+		return true
+	}
+	fields := strings.Fields(after)
+	if len(fields) == 0 {
+		return true
+	}
+	next := strings.ToLower(cleanPunctuation(fields[0]))
+	if next == "" {
+		return true
+	}
+	return slices.Contains(nodes, next) || looksLikeNodeName(next) || isAddress(next) || unicode.IsDigit(rune(next[0]))
+}
+
+func appendSegment(segments []string, segment string) []string {
+	if segment = strings.TrimSpace(segment); segment != "" {
+		segments = append(segments, segment)
+	}
+	return segments
+}
@@ -0,0 +1,46 @@
+// Package eql keeps extracted WHERE conditions as an ordered list, so one
+// field can carry several conditions such as two excluded interface names.
+package eql
+
+import "sort"
+
+// Condition is a single WHERE condition on a field
+type Condition struct {
+	Field string
+	Value string
+}
+
+// String renders the condition as EQL
+func (c Condition) String() string {
+	return FormatCondition(c.Field, c.Value)
+}
+
+// ExtractConditionList extracts the WHERE conditions for a table, sorted by
+// field. Unlike ExtractConditions it keeps every exclusion, so "not
+// management and not system" yields two conditions on the interface name,
+// in query order.
+func ExtractConditionList(query, tablePath string) []Condition {
+	conditions, exclusions := extractConditions(query, tablePath)
+
+	list := make([]Condition, 0, len(conditions)+len(exclusions))
+	for field, value := range conditions {
+		if !hasConditionOn(exclusions, field) {
+			list = append(list, Condition{Field: field, Value: value})
+		}
+	}
+	list = append(list, exclusions...)
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Field < list[j].Field
+	})
+	return list
+}
+
+func hasConditionOn(conditions []Condition, field string) bool {
+	for _, c := range conditions {
+		if c.Field == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+// Package eql recognizes questions about counters since their last clear and
+// projects the fields that answer them.
+package eql
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// sinceClearPattern matches "since last clear", "since reset" and "since
+// counters were cleared"
+var sinceClearPattern = regexp.MustCompile(`(?i)\bsince\s+(?:the\s+)?(?:last\s+)?(?:counters?\s+(?:were\s+|was\s+)?)?(?:clear(?:ed)?|reset)\b`)
+
+// sinceClearSuffixes name the per-clear variants some tables expose next to
+// their cumulative counters
+var sinceClearSuffixes = []string{"-since-clear", "-since-last-clear"}
+
+// HasSinceClear reports whether the query asks for counters relative to the
+// last counter reset
+func HasSinceClear(query string) bool {
+	return sinceClearPattern.MatchString(query)
+}
+
+// isStatisticsTable reports whether a table holds resettable counters
+func isStatisticsTable(tablePath string) bool {
+	return strings.Contains(tablePath, "statistics") || strings.Contains(tablePath, "counters")
+}
+
+// applySinceClear swaps cumulative counters for their since-clear variants
+// where the table has them and adds last-clear, so counts can be read
+// against the time of the reset. An empty projection is left alone since it
+// already selects every column.
+func applySinceClear(lower, tablePath string, availableFields, fields []string) []string {
+	if len(fields) == 0 || !isStatisticsTable(tablePath) || !HasSinceClear(lower) {
+		return fields
+	}
+
+	result := make([]string, 0, len(fields)+1)
+	for _, field := range fields {
+		if variant := sinceClearVariant(field, availableFields); !slices.Contains(result, variant) {
+			result = append(result, variant)
+		}
+	}
+	if slices.Contains(availableFields, "last-clear") && !slices.Contains(result, "last-clear") {
+		result = append(result, "last-clear")
+	}
+	return result
+}
+
+func sinceClearVariant(field string, availableFields []string) string {
+	for _, suffix := range sinceClearSuffixes {
+		if slices.Contains(availableFields, field+suffix) {
+			return field + suffix
+		}
+	}
+	return field
+}
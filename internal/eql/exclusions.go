@@ -0,0 +1,99 @@
+// Package eql turns exclusion phrasing such as "without errors" or
+// "excluding management interface" into negated conditions.
+package eql
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// exclusionPattern matches an exclusion keyword and the term it excludes
+var exclusionPattern = regexp.MustCompile(`\b(?:without|excluding|except(?:\s+for)?|other\s+than|not)\s+(?:the\s+|any\s+|a\s+)?([a-z0-9-]+)`)
+
+// exclusionMapping maps an excluded term to the condition that filters it
+// out. An empty FieldName targets the table's interface key field.
+type exclusionMapping struct {
+	Terms                 []string
+	FieldName             string
+	Value                 string
+	RequiredTableKeywords []string
+}
+
+var exclusionMappings = []exclusionMapping{
+	{Terms: []string{"errors", "error"}, FieldName: "in-error-packets", Value: "= 0", RequiredTableKeywords: []string{"interface"}},
+	{Terms: []string{"errors", "error"}, FieldName: "out-error-packets", Value: "= 0", RequiredTableKeywords: []string{"interface"}},
+	{Terms: []string{"discards", "drops"}, FieldName: "in-discards", Value: "= 0", RequiredTableKeywords: []string{"interface"}},
+	{Terms: []string{"discards", "drops"}, FieldName: "out-discards", Value: "= 0", RequiredTableKeywords: []string{"interface"}},
+	{Terms: []string{"management", "mgmt", "mgmt0"}, Value: `!= "mgmt0"`, RequiredTableKeywords: []string{".srl.interface"}},
+	{Terms: []string{"system", "system0"}, Value: `!= "system0"`, RequiredTableKeywords: []string{".srl.interface"}},
+}
+
+// extractExclusions returns the negated conditions for every exclusion
+// phrase in lower, in query order, together with lower with those phrases
+// blanked so the excluded terms do not also trigger positive mappings
+func extractExclusions(lower, tablePath string) ([]Condition, string) {
+	var conditions []Condition
+	masked := []byte(lower)
+
+	for _, loc := range exclusionPattern.FindAllStringSubmatchIndex(lower, -1) {
+		term := lower[loc[2]:loc[3]]
+		excluded := exclusionConditions(term, tablePath)
+		if len(excluded) == 0 {
+			continue
+		}
+		for _, c := range excluded {
+			if !slices.Contains(conditions, c) {
+				conditions = append(conditions, c)
+			}
+		}
+		for i := loc[0]; i < loc[1]; i++ {
+			masked[i] = ' '
+		}
+	}
+
+	return conditions, string(masked)
+}
+
+// exclusionConditions returns the conditions excluding term, or none when
+// the term is not understood. Terms without a dedicated exclusion fall back
+// to negating a matching field mapping, so "except down" yields
+// oper-state != "down".
+func exclusionConditions(term, tablePath string) []Condition {
+	var conditions []Condition
+	for _, mapping := range exclusionMappings {
+		if !slices.Contains(mapping.Terms, term) || !hasTableKeywords(tablePath, mapping.RequiredTableKeywords) {
+			continue
+		}
+		field := mapping.FieldName
+		if field == "" {
+			field = interfaceNameField(tablePath, models.SRL)
+		}
+		if field != "" {
+			conditions = append(conditions, Condition{Field: field, Value: mapping.Value})
+		}
+	}
+	if len(conditions) > 0 {
+		return conditions
+	}
+
+	mappings := GetFieldMappings()
+	for i := range mappings {
+		if slices.Contains(mappings[i].Patterns, term) && isValidForTable(&mappings[i], tablePath) {
+			return []Condition{{Field: mappings[i].FieldName, Value: "!= " + QuoteString(mappings[i].Value)}}
+		}
+	}
+	return nil
+}
+
+func hasTableKeywords(tablePath string, keywords []string) bool {
+	lower := strings.ToLower(tablePath)
+	for _, keyword := range keywords {
+		if !strings.Contains(lower, keyword) {
+			return false
+		}
+	}
+	return true
+}
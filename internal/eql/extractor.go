@@ -4,11 +4,12 @@ package eql
 
 import (
 	"encoding/json"
-	"fmt"
 	"regexp"
 	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
 	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
@@ -25,44 +26,54 @@ func ParseEmbeddingText(text string) []string {
 	return []string{}
 }
 
-// ExtractFields extracts fields from natural language
+// ExtractFields extracts fields from natural language, keeping at most
+// MaxExtractedFields of the best-ranked guesses
 func ExtractFields(query, tablePath string, embeddingEntry *models.EmbeddingEntry) []string {
-	fields := []string{}
+	return ExtractFieldsWithLimit(query, tablePath, embeddingEntry, constants.MaxExtractedFields)
+}
+
+// ExtractFieldsWithLimit extracts fields from natural language. Asking for
+// all fields yields none, fields the user listed explicitly or spelled out
+// verbatim are returned as-is, and keyword guesses are ranked and truncated
+// to maxFields (no cap when maxFields is not positive). More than
+// BroadFieldThreshold guesses yield no projection at all.
+func ExtractFieldsWithLimit(query, tablePath string, embeddingEntry *models.EmbeddingEntry, maxFields int) []string {
+	// An empty projection selects every column
+	if WantsAllFields(query) {
+		return []string{}
+	}
+
 	lower := strings.ToLower(query)
 
 	// Get available fields from embedding
 	availableFields := ParseEmbeddingText(embeddingEntry.Text)
 
-	// Use field keywords mapping from configuration
-	fieldKeywords := FieldKeywordMappings()
+	// Fields the user listed by name take precedence over keyword guessing
+	if explicit := ExtractExplicitFields(query, availableFields); len(explicit) > 0 {
+		return explicit
+	}
 
-	// Function to find matching available fields
-	findMatchingFields := func(keywords []string) []string {
-		var matches []string
-		for _, keyword := range keywords {
-			for _, available := range availableFields {
-				if strings.Contains(strings.ToLower(available), keyword) {
-					if !slices.Contains(matches, available) {
-						matches = append(matches, available)
-					}
-				}
-			}
-		}
-		return matches
+	// Several metrics named verbatim are projected exactly as named
+	if named := NamedFields(query, availableFields); len(named) >= 2 {
+		return applySinceClear(lower, tablePath, availableFields, named)
 	}
 
-	// Check for specific field requests based on query keywords
-	for keyword, possibleFields := range fieldKeywords {
-		if strings.Contains(lower, keyword) {
-			matches := findMatchingFields(possibleFields)
-			for _, match := range matches {
-				if !slices.Contains(fields, match) {
-					fields = append(fields, match)
-				}
-			}
+	// Top talkers are judged by their octet counters
+	if IsTopTalkers(lower) {
+		if fields := topTalkerFields(availableFields); len(fields) > 0 {
+			return fields
 		}
 	}
 
+	guesses := rankKeywordFields(lower, availableFields)
+
+	// A query broad enough to match this many fields is better served by
+	// every column than by an arbitrary subset
+	if len(guesses) > constants.BroadFieldThreshold {
+		return []string{}
+	}
+	fields := applySinceClear(lower, tablePath, availableFields, LimitFields(guesses, maxFields))
+
 	// Special handling for interface errors when no statistics table
 	if strings.Contains(lower, "error") && strings.Contains(tablePath, "interface") && !strings.Contains(tablePath, "statistics") {
 		// Suggest looking at statistics if no direct error fields found
@@ -74,8 +85,109 @@ func ExtractFields(query, tablePath string, embeddingEntry *models.EmbeddingEntr
 	return fields
 }
 
-// ExtractNodeName extracts node name from query
+// LimitFields truncates a ranked field list to maxFields entries. A
+// non-positive maxFields leaves the list untouched.
+func LimitFields(fields []string, maxFields int) []string {
+	if maxFields > 0 && len(fields) > maxFields {
+		return fields[:maxFields]
+	}
+	return fields
+}
+
+// fieldMatch is an available field matched by a query keyword, with the
+// attributes used to rank it
+type fieldMatch struct {
+	field        string
+	exact        bool // field name equals the mapped name rather than containing it
+	queryPos     int  // position of the keyword in the query
+	mappingIndex int  // position of the mapped name in the keyword's list
+}
+
+// diffPattern matches the words asking what changed. They must be whole
+// words: "different", "difference" in "different mtu" and "unchanged" do not
+// ask for a diff.
+var diffPattern = regexp.MustCompile(`\b(?:changed|diff|difference|modified)\b`)
+
+// diffKeywords are the FieldKeywordMappings keys only matched as whole words
+var diffKeywords = map[string]bool{"changed": true, "diff": true, "modified": true}
+
+// HasDiffIntent reports whether the query asks what changed, which is
+// answered from configuration rather than operational state
+func HasDiffIntent(lower string) bool {
+	return diffPattern.MatchString(lower)
+}
+
+// keywordIndex returns where keyword occurs in lower, or -1. Diff keywords
+// only count as whole words; others match anywhere, so "expir" finds
+// "expiring".
+func keywordIndex(lower, keyword string) int {
+	if !diffKeywords[keyword] {
+		return strings.Index(lower, keyword)
+	}
+	for _, loc := range diffPattern.FindAllStringIndex(lower, -1) {
+		if lower[loc[0]:loc[1]] == keyword {
+			return loc[0]
+		}
+	}
+	return -1
+}
+
+// rankKeywordFields finds available fields for the keywords in the query and
+// orders them: exact name matches first, then by where the keyword appears
+// in the query, then by the mapping's own preference order
+func rankKeywordFields(lower string, availableFields []string) []string {
+	var matches []fieldMatch
+	for keyword, possibleFields := range FieldKeywordMappings() {
+		pos := keywordIndex(lower, keyword)
+		if pos < 0 {
+			continue
+		}
+		for i, possible := range possibleFields {
+			for _, available := range availableFields {
+				if strings.Contains(strings.ToLower(available), possible) {
+					matches = append(matches, fieldMatch{
+						field:        available,
+						exact:        strings.EqualFold(available, possible),
+						queryPos:     pos,
+						mappingIndex: i,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return fieldMatchLess(matches[i], matches[j])
+	})
+
+	fields := []string{}
+	for _, match := range matches {
+		if !slices.Contains(fields, match.field) {
+			fields = append(fields, match.field)
+		}
+	}
+	return fields
+}
+
+func fieldMatchLess(a, b fieldMatch) bool {
+	if a.exact != b.exact {
+		return a.exact
+	}
+	if a.queryPos != b.queryPos {
+		return a.queryPos < b.queryPos
+	}
+	if a.mappingIndex != b.mappingIndex {
+		return a.mappingIndex < b.mappingIndex
+	}
+	return a.field < b.field
+}
+
+// ExtractNodeName extracts node name from query. Fleet-wide queries name no
+// node.
 func ExtractNodeName(query string) string {
+	if IsFleetWide(query) {
+		return ""
+	}
 	words := strings.Fields(strings.ToLower(query))
 	for i, w := range words {
 		w = cleanPunctuation(w)
@@ -98,8 +210,13 @@ func ExtractNodeName(query string) string {
 	return ""
 }
 
-// ExtractNodeNames extracts all node names from query for multi-node support
+// ExtractNodeNames extracts all node names from query for multi-node support.
+// Fleet-wide queries such as "for all nodes" name no node.
 func ExtractNodeNames(query string) []string {
+	if IsFleetWide(query) {
+		return nil
+	}
+
 	var nodeNames []string
 	words := strings.Fields(strings.ToLower(query))
 
@@ -159,31 +276,65 @@ func checkNodePattern(word string) string {
 func checkPrepositionPattern(word string, index int, words []string) string {
 	if (word == "on" || word == "for" || word == "from") && index+1 < len(words) {
 		next := cleanPunctuation(words[index+1])
-		if !isSkipWord(next) && len(next) > 1 {
+		if !isSkipWord(next) && len(next) > 1 && !isInterfaceIdentifier(next) && hasLetter(next) && !isAddress(next) {
 			return next
 		}
 	}
 	return ""
 }
 
+// hasLetter reports whether a word contains a letter; bare numbers such as
+// "from 100" are values, not node names
+func hasLetter(word string) bool {
+	return strings.IndexFunc(word, unicode.IsLetter) >= 0
+}
+
 func isSkipWord(word string) bool {
 	skipWords := map[string]bool{
 		"nodes": true, "node": true, "my": true, "the": true,
 		"bgp": true, "ospf": true, "isis": true, "mpls": true,
 		"interface": true, "interfaces": true, "router": true,
-		"system": true, "all": true, "any": true,
+		"system": true, "all": true, "any": true, "every": true, "each": true,
 		"errors": true, "error": true, "drops": true, "drop": true,
 		"statistics": true, "stats": true, "status": true,
 		"configuration": true, "config": true, "state": true,
 		"up": true, "down": true, "active": true, "inactive": true,
+		"mac": true, "ip": true, "ipv4": true, "ipv6": true, "address": true,
+		"arp": true, "route": true, "routes": true, "prefix": true,
+		"vendor": true, "serial": true, "transceiver": true, "transceivers": true,
 	}
 	return skipWords[word]
 }
 
-// ExtractConditions extracts conditions for WHERE clause using dictionary-based approach
+// ExtractConditions extracts conditions for WHERE clause using dictionary-based approach.
+// Each field holds one value; when a field is excluded more than once the
+// last exclusion wins, see ExtractConditionList for all of them.
 func ExtractConditions(query, tablePath string) map[string]string {
+	conditions, exclusions := extractConditions(query, tablePath)
+	for _, c := range exclusions {
+		conditions[c.Field] = c.Value
+	}
+	return conditions
+}
+
+// extractConditions returns the positive conditions by field and the
+// exclusions in query order
+func extractConditions(query, tablePath string) (map[string]string, []Condition) {
 	conditions := make(map[string]string)
-	lower := strings.ToLower(query)
+
+	// Serial numbers and vendor names keep their case and quoting
+	valueQuery := applyTransceiverValues(query, tablePath, conditions)
+	lower := strings.ToLower(maskQuotedPhrases(valueQuery))
+
+	// Interface identifiers are extracted first, then masked so their parts
+	// do not trigger keyword mappings
+	applyInterfaceNames(lower, tablePath, conditions)
+	applySubinterfaceIndex(lower, tablePath, conditions)
+	lower = MaskInterfaceNames(lower)
+
+	// Exclusions are masked so the excluded terms do not also match
+	// positively, and win over other mappings on the same field
+	exclusions, lower := extractExclusions(lower, tablePath)
 
 	// Apply standard field mappings
 	applyFieldMappings(lower, tablePath, conditions)
@@ -191,13 +342,40 @@ func ExtractConditions(query, tablePath string) map[string]string {
 	// Apply regex-based mappings for value extraction
 	applyRegexMappings(lower, tablePath, conditions)
 
+	// AS number ranges override a single extracted AS number
+	applyASRanges(lower, tablePath, conditions)
+
+	// Speed ranges override a single extracted port speed
+	lower = applySpeedRanges(lower, tablePath, conditions)
+
+	// Prefixes and route distinguishers on route tables
+	applyRouteConditions(lower, tablePath, conditions)
+
+	// MAC and IP addresses on ARP, ND and MAC tables
+	applyNeighborConditions(lower, tablePath, conditions)
+
+	// Address families on routing protocol tables
+	applyAFISAFI(lower, tablePath, conditions)
+
 	// Apply conditional mappings based on context
 	applyConditionalMappings(lower, tablePath, conditions)
 
+	// "healthy" requires every health signal to be good
+	applyHealthy(lower, tablePath, conditions)
+
+	// Apply percentage, unit-aware and optical power thresholds before the
+	// plain numeric fallback
+	applyPercentThresholds(lower, tablePath, conditions)
+	applyUnitThresholds(lower, tablePath, conditions)
+	lower = applyPowerThresholds(lower, tablePath, conditions)
+
 	// Fallback to legacy extraction for uncovered cases
 	extractNumericConditions(lower, conditions)
 
-	return conditions
+	// Quoted description filters keep the phrase's original case
+	applyDescriptionPhrase(query, conditions)
+
+	return conditions, exclusions
 }
 
 // applyFieldMappings applies standard field mappings from configuration
@@ -259,6 +437,14 @@ func applyConditionalMappings(lower, tablePath string, conditions map[string]str
 
 // isValidForTable checks if a field mapping is valid for the given table
 func isValidForTable(mapping *FieldMapping, tablePath string) bool {
+	// Platform restrictions apply on top of any table restrictions
+	if len(mapping.ValidPlatforms) > 0 {
+		platform, ok := models.PlatformFromTable(tablePath)
+		if !ok || !slices.Contains(mapping.ValidPlatforms, platform) {
+			return false
+		}
+	}
+
 	// If no table restrictions, it's valid for all tables
 	if len(mapping.ValidTables) == 0 && len(mapping.RequiredTableKeywords) == 0 {
 		return true
@@ -289,105 +475,85 @@ func isValidForTable(mapping *FieldMapping, tablePath string) bool {
 	return false
 }
 
+// numericConditionPattern matches "<field> <operator> <number>", where the
+// number may be negative or decimal, e.g. "temperature below -5"
+var numericConditionPattern = regexp.MustCompile(`(\w+)(\s+(?:greater than|less than|more than|equal to|at least|at most|over|above|exceeding|under|below)|\s*(?:!=|>=|<=|>|<|=))\s*(-?\d+(?:\.\d+)?)\b`)
+
+// numericFieldNoise lists words before an operator that never name a field
+var numericFieldNoise = map[string]bool{
+	"with": true, "for": true, "is": true, "are": true, "and": true,
+	"of": true, "than": true, "has": true, "have": true, "at": true,
+}
+
 func extractNumericConditions(lower string, conditions map[string]string) {
-	numericPattern := regexp.MustCompile(`(\w+)\s*(greater than|less than|equal to|!=|>=|<=|>|<|=)\s*(\d+)`)
-	matches := numericPattern.FindAllStringSubmatch(lower, -1)
+	matches := numericConditionPattern.FindAllStringSubmatchIndex(lower, -1)
 
-	for _, match := range matches {
-		field := match[1]
-		op := normalizeOperator(match[2])
-		value := match[3]
-		conditions[field] = op + " " + value
+	for _, loc := range matches {
+		// Numbers carrying a unit are handled by applyUnitThresholds,
+		// applyPercentThresholds or applyPowerThresholds
+		rest := lower[loc[1]:]
+		if hasUnitSuffix(rest) || hasPercentSuffix(rest) || hasPowerSuffix(rest) {
+			continue
+		}
+
+		field := lower[loc[2]:loc[3]]
+		if numericFieldNoise[field] {
+			continue
+		}
+		op := normalizeOperator(strings.TrimSpace(lower[loc[4]:loc[5]]))
+		conditions[field] = op + " " + lower[loc[6]:loc[7]]
 	}
 }
 
 func normalizeOperator(op string) string {
-	switch op {
-	case "greater than":
-		return ">"
-	case "less than":
-		return "<"
-	case "equal to":
+	if op == "equal to" {
 		return "="
-	default:
-		return op
 	}
+	return comparisonOperator(op)
 }
 
-// GenerateWhereClause generates WHERE clause with field validation
+// GenerateWhereClause generates WHERE clause without field validation
 func GenerateWhereClause(tablePath, query string) string {
-	var whereParts []string
+	return generateWhereClause(tablePath, query, ExtractNodeNames(query), func(string) bool { return true })
+}
 
-	// Extract node names (support multiple nodes)
-	nodeNames := ExtractNodeNames(query)
-	if len(nodeNames) > 0 && strings.Contains(tablePath, ".namespace.node.") {
-		if len(nodeNames) == 1 {
-			whereParts = append(whereParts, fmt.Sprintf(".namespace.node.name = %q", nodeNames[0]))
-		} else {
-			// Multiple nodes: use IN clause
-			nodeList := make([]string, len(nodeNames))
-			for i, name := range nodeNames {
-				nodeList[i] = fmt.Sprintf("%q", name)
-			}
-			whereParts = append(whereParts, fmt.Sprintf(".namespace.node.name in [%s]", strings.Join(nodeList, ", ")))
-		}
-	}
+// GenerateWhereClauseWithValidation generates WHERE clause with field validation
+func GenerateWhereClauseWithValidation(tablePath, query string, availableFields []string) string {
+	return generateWhereClause(tablePath, query, ExtractNodeNames(query), fieldValidator(availableFields))
+}
 
-	// Extract other conditions
-	conditions := ExtractConditions(query, tablePath)
-	for field, value := range conditions {
-		if strings.HasPrefix(value, ">") || strings.HasPrefix(value, "<") || strings.HasPrefix(value, "=") || strings.HasPrefix(value, "!") {
-			whereParts = append(whereParts, fmt.Sprintf("%s %s", field, value))
-		} else {
-			whereParts = append(whereParts, fmt.Sprintf("%s = %q", field, value))
-		}
-	}
+// GenerateNodeWhereClause generates the validated WHERE clause for query
+// with the node filter narrowed to a single node
+func GenerateNodeWhereClause(tablePath, query, node string, availableFields []string) string {
+	return generateWhereClause(tablePath, query, []string{node}, fieldValidator(availableFields))
+}
 
-	if len(whereParts) == 0 {
-		return ""
+func fieldValidator(availableFields []string) func(string) bool {
+	return func(field string) bool {
+		return slices.Contains(availableFields, field)
 	}
-
-	return strings.Join(whereParts, " and ")
 }
 
-// GenerateWhereClauseWithValidation generates WHERE clause with field validation
-func GenerateWhereClauseWithValidation(tablePath, query string, availableFields []string) string {
+// generateWhereClause builds the WHERE clause filtering on nodeNames,
+// keeping only the extracted conditions whose field is accepted by keepField
+func generateWhereClause(tablePath, query string, nodeNames []string, keepField func(string) bool) string {
 	var whereParts []string
 
-	// Extract node names (support multiple nodes)
-	nodeNames := ExtractNodeNames(query)
 	if len(nodeNames) > 0 && strings.Contains(tablePath, ".namespace.node.") {
-		if len(nodeNames) == 1 {
-			whereParts = append(whereParts, fmt.Sprintf(".namespace.node.name = %q", nodeNames[0]))
-		} else {
-			// Multiple nodes: use IN clause
-			nodeList := make([]string, len(nodeNames))
-			for i, name := range nodeNames {
-				nodeList[i] = fmt.Sprintf("%q", name)
-			}
-			whereParts = append(whereParts, fmt.Sprintf(".namespace.node.name in [%s]", strings.Join(nodeList, ", ")))
-		}
+		whereParts = append(whereParts, formatNodeCondition(nodeNames))
 	}
 
-	// Extract other conditions and validate against available fields
-	conditions := ExtractConditions(query, tablePath)
-	for field, value := range conditions {
-		// Check if field exists in available fields
-		fieldExists := false
-		for _, availableField := range availableFields {
-			if availableField == field {
-				fieldExists = true
-				break
-			}
+	// Composite conditions come first, then the others sorted by field so
+	// the clause is stable. Conditions that do not fit the field's inferred
+	// type are dropped.
+	for _, composite := range ExtractCompositeConditions(query, tablePath) {
+		if part, ok := formatKeptComposite(composite, keepField); ok {
+			whereParts = append(whereParts, part)
 		}
-
-		// Only add condition if field exists in the table
-		if fieldExists {
-			if strings.HasPrefix(value, ">") || strings.HasPrefix(value, "<") || strings.HasPrefix(value, "=") || strings.HasPrefix(value, "!") {
-				whereParts = append(whereParts, fmt.Sprintf("%s %s", field, value))
-			} else {
-				whereParts = append(whereParts, fmt.Sprintf("%s = %q", field, value))
-			}
+	}
+	for _, c := range ExtractConditionList(query, tablePath) {
+		if part, ok := formatKeptCondition(c, keepField); ok {
+			whereParts = append(whereParts, part)
 		}
 	}
 
@@ -398,6 +564,29 @@ func GenerateWhereClauseWithValidation(tablePath, query string, availableFields
 	return strings.Join(whereParts, " and ")
 }
 
+// formatKeptComposite keeps the alternatives of c that formatKeptCondition
+// accepts, dropping c when none does
+func formatKeptComposite(c CompositeCondition, keepField func(string) bool) (string, bool) {
+	var parts []string
+	for _, alternative := range c.Any {
+		if part, ok := formatKeptCondition(alternative, keepField); ok {
+			parts = append(parts, part)
+		}
+	}
+	return joinAlternatives(parts), len(parts) > 0
+}
+
+// formatKeptCondition renders c when its field is kept and fits the field's
+// type
+func formatKeptCondition(c Condition, keepField func(string) bool) (string, bool) {
+	// Fully qualified paths reference a parent table and are not among the
+	// table's own fields
+	if !keepField(c.Field) && !strings.HasPrefix(c.Field, ".") {
+		return "", false
+	}
+	return FormatTypedCondition(c.Field, c.Value)
+}
+
 // ExtractOrderBy extracts ORDER BY clauses
 func ExtractOrderBy(query, tablePath string, embeddingEntry *models.EmbeddingEntry) []models.OrderByClause {
 	lower := strings.ToLower(query)
@@ -407,6 +596,23 @@ func ExtractOrderBy(query, tablePath string, embeddingEntry *models.EmbeddingEnt
 
 	var orderBy []models.OrderByClause
 
+	// Several sort keys keep the direction written next to each of them
+	if chain := extractSortChain(lower, fieldFinder); chain != nil {
+		return applySortAlgorithms(lower, chain)
+	}
+
+	// An explicit "sort by <field>" replaces any inferred sort
+	if explicit := extractExplicitSort(lower, fieldFinder); explicit != nil {
+		return applySortModifiers(lower, append(orderBy, *explicit))
+	}
+
+	// Top talkers sort by traffic even without a superlative naming it
+	if IsTopTalkers(lower) {
+		if talkers := topTalkersOrderBy(availableFields); len(talkers) > 0 {
+			return applySortModifiers(lower, talkers)
+		}
+	}
+
 	// Check for descending sort patterns
 	orderBy = extractDescendingSort(lower, fieldFinder, orderBy)
 
@@ -419,7 +625,8 @@ func ExtractOrderBy(query, tablePath string, embeddingEntry *models.EmbeddingEnt
 	// Default natural sorting
 	orderBy = extractDefaultSort(lower, fieldFinder, orderBy)
 
-	return orderBy
+	// Explicit direction words win over the inferred direction
+	return applySortModifiers(lower, orderBy)
 }
 
 func createFieldFinder(availableFields []string) func([]string) string {
@@ -440,14 +647,17 @@ func extractDescendingSort(lower string, findSortField func([]string) string, or
 		return orderBy
 	}
 
-	sortConfig := getDescendingSortConfig(lower)
-	if sortConfig.keywords != nil {
-		if sortField := findSortField(sortConfig.keywords); sortField != "" {
-			orderBy = append(orderBy, models.OrderByClause{
-				Field:     sortField,
-				Direction: "descending",
-			})
-		}
+	// "by <field>" names the metric directly; otherwise fall back to the
+	// well-known metrics
+	sortField := findSortField(byFieldKeywords(lower))
+	if sortField == "" {
+		sortField = findSortField(getMetricSortConfig(lower).keywords)
+	}
+	if sortField != "" {
+		orderBy = append(orderBy, models.OrderByClause{
+			Field:     sortField,
+			Direction: "descending",
+		})
 	}
 
 	return orderBy
@@ -458,24 +668,26 @@ func extractAscendingSort(lower string, findSortField func([]string) string, ord
 		return orderBy
 	}
 
-	if strings.Contains(lower, "memory") {
-		if sortField := findSortField([]string{"memory-usage", "memory-utilization", "utilization", "used"}); sortField != "" {
-			orderBy = append(orderBy, models.OrderByClause{
-				Field:     sortField,
-				Direction: "ascending",
-			})
-		}
+	sortField := findSortField(byFieldKeywords(lower))
+	if sortField == "" {
+		sortField = findSortField(getMetricSortConfig(lower).keywords)
+	}
+	if sortField != "" {
+		orderBy = append(orderBy, models.OrderByClause{
+			Field:     sortField,
+			Direction: "ascending",
+		})
 	}
 
 	return orderBy
 }
 
 func extractTimeSort(lower, tablePath string, findSortField func([]string) string, orderBy []models.OrderByClause) []models.OrderByClause {
-	if !strings.Contains(tablePath, "alarm") {
+	if !isTimeOrderedTable(tablePath) {
 		return orderBy
 	}
 
-	if strings.Contains(lower, "recent") || strings.Contains(lower, "latest") {
+	if hasRecentIntent(lower) {
 		if sortField := findSortField([]string{"time-created", "last-change", "timestamp"}); sortField != "" {
 			orderBy = append(orderBy, models.OrderByClause{
 				Field:     sortField,
@@ -504,7 +716,8 @@ func extractDefaultSort(lower string, findSortField func([]string) string, order
 func hasDescendingKeywords(lower string) bool {
 	return strings.Contains(lower, "top") ||
 		strings.Contains(lower, "highest") ||
-		strings.Contains(lower, "most")
+		strings.Contains(lower, "most") ||
+		strings.Contains(lower, "flappiest")
 }
 
 func hasAscendingKeywords(lower string) bool {
@@ -512,70 +725,89 @@ func hasAscendingKeywords(lower string) bool {
 		strings.Contains(lower, "least")
 }
 
+var byFieldPattern = regexp.MustCompile(`\bby\s+(?:the\s+)?([a-z0-9-]+)(?:\s+([a-z0-9-]+))?`)
+
+// byFieldNoise lists words after "by" that never name a field on their own
+var byFieldNoise = map[string]bool{
+	"in": true, "out": true, "on": true, "for": true, "of": true,
+	"from": true, "with": true, "and": true, "number": true, "total": true,
+}
+
+// FindByField returns the available field named by a "by <field>" phrase in
+// the query, or "" when the query has no such phrase or no field matches
+func FindByField(query string, availableFields []string) string {
+	return createFieldFinder(availableFields)(byFieldKeywords(strings.ToLower(query)))
+}
+
+// SortPhraseWords returns the words following "by" in the query, which name
+// a sort field rather than what the query is about
+func SortPhraseWords(query string) []string {
+	match := byFieldPattern.FindStringSubmatch(strings.ToLower(query))
+	if match == nil {
+		return nil
+	}
+	return strings.FieldsFunc(match[1]+" "+match[2], func(r rune) bool {
+		return r == ' ' || r == '-'
+	})
+}
+
+// byFieldKeywords returns field keywords for a "by <field>" phrase, most
+// specific first: the two words joined as a field name ("out discards" ->
+// "out-discards"), then the first word alone
+func byFieldKeywords(lower string) []string {
+	match := byFieldPattern.FindStringSubmatch(lower)
+	if match == nil {
+		return nil
+	}
+
+	var keywords []string
+	if match[2] != "" {
+		keywords = append(keywords, match[1]+"-"+match[2])
+	}
+	if !byFieldNoise[match[1]] && len(match[1]) >= constants.MinTokenLength {
+		keywords = append(keywords, match[1])
+	} else if match[2] != "" && !byFieldNoise[match[2]] {
+		keywords = append(keywords, match[2])
+	}
+	return keywords
+}
+
 type sortConfig struct {
 	keywords []string
 }
 
-func getDescendingSortConfig(lower string) sortConfig {
+// getMetricSortConfig returns the fields to sort by for the well-known
+// metrics a superlative refers to, e.g. "most memory" or "lowest traffic"
+func getMetricSortConfig(lower string) sortConfig {
 	switch {
+	case mentionsFlaps(lower):
+		return sortConfig{keywords: flapFieldKeywords}
 	case strings.Contains(lower, "memory"):
 		return sortConfig{keywords: []string{"memory-usage", "memory-utilization", "utilization", "used"}}
 	case strings.Contains(lower, "cpu"):
 		return sortConfig{keywords: []string{"cpu-utilization", "cpu-usage", "cpu"}}
 	case strings.Contains(lower, "traffic"):
 		return sortConfig{keywords: []string{"in-octets", "out-octets", "octets"}}
+	case strings.Contains(lower, "error"):
+		return sortConfig{keywords: []string{"in-error-packets", "out-error-packets", "error"}}
 	default:
 		return sortConfig{}
 	}
 }
 
-// ExtractLimit extracts LIMIT value
-func ExtractLimit(query string) int {
-	lower := strings.ToLower(query)
-
-	// Look for "top N" or "first N" patterns
-	patterns := []string{
-		`top (\d+)`,
-		`first (\d+)`,
-		`limit (\d+)`,
-		`(\d+) results`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		if matches := re.FindStringSubmatch(lower); len(matches) > 1 {
-			if limit, err := strconv.Atoi(matches[1]); err == nil && limit > 0 && limit <= constants.MaxLimitValue {
-				return limit
-			}
-		}
-	}
-
-	// Default limits for certain queries
-	if strings.Contains(lower, "top") || strings.Contains(lower, "highest") {
-		return constants.DefaultTopLimit
-	}
-
-	return 0
-}
-
 // ExtractDelta extracts DELTA clause
 func ExtractDelta(query string) *models.DeltaClause {
 	lower := strings.ToLower(query)
 
-	// Look for update frequency patterns
-	patterns := map[string]*regexp.Regexp{
-		"second":      regexp.MustCompile(`every (\d+) seconds?`),
-		"millisecond": regexp.MustCompile(`every (\d+) milliseconds?`),
-		"realtime":    regexp.MustCompile(`real[\s-]?time|streaming`),
-	}
-
-	for unit, pattern := range patterns {
-		if matches := pattern.FindStringSubmatch(lower); len(matches) > 1 {
+	// Look for update frequency patterns, most specific unit first so "ms"
+	// is not read as seconds
+	for _, pattern := range deltaPatterns {
+		if matches := pattern.regex.FindStringSubmatch(lower); len(matches) > 1 {
 			if value, err := strconv.Atoi(matches[1]); err == nil && value > 0 {
-				return &models.DeltaClause{
-					Unit:  unit + "s",
+				return clampDelta(&models.DeltaClause{
+					Unit:  pattern.unit,
 					Value: value,
-				}
+				})
 			}
 		}
 	}
@@ -590,3 +822,32 @@ func ExtractDelta(query string) *models.DeltaClause {
 
 	return nil
 }
+
+// deltaPatterns maps update frequency phrasings to DELTA units
+var deltaPatterns = []struct {
+	unit  string
+	regex *regexp.Regexp
+}{
+	{"microseconds", regexp.MustCompile(`every (\d+)\s*(?:microseconds?|us|µs)\b`)},
+	{"milliseconds", regexp.MustCompile(`every (\d+)\s*(?:milliseconds?|ms)\b`)},
+	{"seconds", regexp.MustCompile(`every (\d+)\s*(?:seconds?|secs?|s)\b`)},
+}
+
+// deltaUnitMicroseconds is the length of one DELTA unit in microseconds
+var deltaUnitMicroseconds = map[string]int{
+	"microseconds": 1,
+	"milliseconds": 1000,
+	"seconds":      1000000,
+}
+
+// clampDelta raises intervals shorter than MinDeltaMicroseconds to that
+// floor so generated queries cannot poll a device faster than it can serve
+func clampDelta(delta *models.DeltaClause) *models.DeltaClause {
+	if delta.Value*deltaUnitMicroseconds[delta.Unit] < constants.MinDeltaMicroseconds {
+		return &models.DeltaClause{
+			Unit:  "microseconds",
+			Value: constants.MinDeltaMicroseconds,
+		}
+	}
+	return delta
+}
@@ -3,7 +3,10 @@ package eql
 
 import (
 	"regexp"
+	"slices"
 	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
 )
 
 // FieldMapping represents a mapping from natural language patterns to field conditions
@@ -20,6 +23,8 @@ type FieldMapping struct {
 	ValidTables []string
 	// Whether this mapping requires the table path to contain certain keywords
 	RequiredTableKeywords []string
+	// Platforms the table path must belong to (empty means all platforms)
+	ValidPlatforms []models.EmbeddingType
 }
 
 // ConditionalMapping represents conditional field mappings
@@ -51,12 +56,74 @@ func GetFieldMappings() []FieldMapping {
 			FieldName:             "admin-state",
 			Value:                 "enable",
 			RequiredTableKeywords: []string{"interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
 		},
 		{
 			Patterns:              []string{"disabled", "disable"},
 			FieldName:             "admin-state",
 			Value:                 "disable",
 			RequiredTableKeywords: []string{"interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
+		},
+
+		// === SROS ADMIN STATE MAPPINGS ===
+		// SROS configures admin-state as enable/disable but reports it as
+		// up/down under state, on ports as well as router interfaces.
+		{
+			Patterns:              []string{"enabled", "enable"},
+			FieldName:             "admin-state",
+			Value:                 "enable",
+			RequiredTableKeywords: []string{".configure.", "port"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"enabled", "enable"},
+			FieldName:             "admin-state",
+			Value:                 "enable",
+			RequiredTableKeywords: []string{".configure.", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"disabled", "disable"},
+			FieldName:             "admin-state",
+			Value:                 "disable",
+			RequiredTableKeywords: []string{".configure.", "port"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"disabled", "disable"},
+			FieldName:             "admin-state",
+			Value:                 "disable",
+			RequiredTableKeywords: []string{".configure.", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"enabled", "enable"},
+			FieldName:             "admin-state",
+			Value:                 "up",
+			RequiredTableKeywords: []string{".state.", "port"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"enabled", "enable"},
+			FieldName:             "admin-state",
+			Value:                 "up",
+			RequiredTableKeywords: []string{".state.", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"disabled", "disable"},
+			FieldName:             "admin-state",
+			Value:                 "down",
+			RequiredTableKeywords: []string{".state.", "port"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"disabled", "disable"},
+			FieldName:             "admin-state",
+			Value:                 "down",
+			RequiredTableKeywords: []string{".state.", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
 		},
 
 		// === PORT SPEED MAPPINGS ===
@@ -65,42 +132,152 @@ func GetFieldMappings() []FieldMapping {
 			FieldName:             "port-speed",
 			Value:                 "400G",
 			RequiredTableKeywords: []string{"ethernet", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
 		},
 		{
 			Patterns:              []string{"100g", "100gbps", "100 gbps"},
 			FieldName:             "port-speed",
 			Value:                 "100G",
 			RequiredTableKeywords: []string{"ethernet", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
 		},
 		{
 			Patterns:              []string{"50g", "50gbps", "50 gbps"},
 			FieldName:             "port-speed",
 			Value:                 "50G",
 			RequiredTableKeywords: []string{"ethernet", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
 		},
 		{
 			Patterns:              []string{"40g", "40gbps", "40 gbps"},
 			FieldName:             "port-speed",
 			Value:                 "40G",
 			RequiredTableKeywords: []string{"ethernet", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
 		},
 		{
 			Patterns:              []string{"25g", "25gbps", "25 gbps"},
 			FieldName:             "port-speed",
 			Value:                 "25G",
 			RequiredTableKeywords: []string{"ethernet", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
 		},
 		{
 			Patterns:              []string{"10g", "10gbps", "10 gbps"},
 			FieldName:             "port-speed",
 			Value:                 "10G",
 			RequiredTableKeywords: []string{"ethernet", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
 		},
 		{
 			Patterns:              []string{"1g", "1gbps", "1 gbps", "gigabit"},
 			FieldName:             "port-speed",
 			Value:                 "1G",
 			RequiredTableKeywords: []string{"ethernet", "interface"},
+			ValidPlatforms:        []models.EmbeddingType{models.SRL},
+		},
+
+		// === SROS PORT SPEED MAPPINGS ===
+		// SROS reports port speed as an integer number of Mbps, in
+		// oper-speed under state and speed under configure; each table
+		// only gets the field it has.
+		{
+			Patterns:              []string{"400g", "400gbps", "400 gbps"},
+			FieldName:             "oper-speed",
+			Value:                 "= 400000",
+			RequiredTableKeywords: []string{".state.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"400g", "400gbps", "400 gbps"},
+			FieldName:             "speed",
+			Value:                 "= 400000",
+			RequiredTableKeywords: []string{".configure.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"100g", "100gbps", "100 gbps"},
+			FieldName:             "oper-speed",
+			Value:                 "= 100000",
+			RequiredTableKeywords: []string{".state.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"100g", "100gbps", "100 gbps"},
+			FieldName:             "speed",
+			Value:                 "= 100000",
+			RequiredTableKeywords: []string{".configure.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"50g", "50gbps", "50 gbps"},
+			FieldName:             "oper-speed",
+			Value:                 "= 50000",
+			RequiredTableKeywords: []string{".state.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"50g", "50gbps", "50 gbps"},
+			FieldName:             "speed",
+			Value:                 "= 50000",
+			RequiredTableKeywords: []string{".configure.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"40g", "40gbps", "40 gbps"},
+			FieldName:             "oper-speed",
+			Value:                 "= 40000",
+			RequiredTableKeywords: []string{".state.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"40g", "40gbps", "40 gbps"},
+			FieldName:             "speed",
+			Value:                 "= 40000",
+			RequiredTableKeywords: []string{".configure.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"25g", "25gbps", "25 gbps"},
+			FieldName:             "oper-speed",
+			Value:                 "= 25000",
+			RequiredTableKeywords: []string{".state.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"25g", "25gbps", "25 gbps"},
+			FieldName:             "speed",
+			Value:                 "= 25000",
+			RequiredTableKeywords: []string{".configure.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"10g", "10gbps", "10 gbps"},
+			FieldName:             "oper-speed",
+			Value:                 "= 10000",
+			RequiredTableKeywords: []string{".state.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"10g", "10gbps", "10 gbps"},
+			FieldName:             "speed",
+			Value:                 "= 10000",
+			RequiredTableKeywords: []string{".configure.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"1g", "1gbps", "1 gbps", "gigabit"},
+			FieldName:             "oper-speed",
+			Value:                 "= 1000",
+			RequiredTableKeywords: []string{".state.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
+		},
+		{
+			Patterns:              []string{"1g", "1gbps", "1 gbps", "gigabit"},
+			FieldName:             "speed",
+			Value:                 "= 1000",
+			RequiredTableKeywords: []string{".configure.", "port", "ethernet"},
+			ValidPlatforms:        []models.EmbeddingType{models.SROS},
 		},
 
 		// === PHYSICAL MEDIUM MAPPINGS ===
@@ -122,13 +299,13 @@ func GetFieldMappings() []FieldMapping {
 			Patterns:              []string{"copper", "electrical"},
 			FieldName:             "ethernet-pmd",
 			Value:                 "~ \"BASE-T\"",
-			RequiredTableKeywords: []string{"transceiver", "ethernet"},
+			RequiredTableKeywords: []string{"transceiver"},
 		},
 		{
 			Patterns:              []string{"fiber", "optical"},
 			FieldName:             "ethernet-pmd",
 			Value:                 "!~ \"BASE-T\"",
-			RequiredTableKeywords: []string{"transceiver", "ethernet"},
+			RequiredTableKeywords: []string{"transceiver"},
 		},
 
 		// === VLAN MAPPINGS ===
@@ -267,6 +444,76 @@ func GetFieldMappings() []FieldMapping {
 			RequiredTableKeywords: []string{"bgp", "neighbor"},
 		},
 
+		// === ARP / NEIGHBOR DISCOVERY MAPPINGS ===
+		{
+			Patterns:    []string{"static"},
+			FieldName:   "origin",
+			Value:       "static",
+			ValidTables: neighborTableMarkers,
+		},
+		{
+			Patterns:    []string{"dynamic", "learned"},
+			FieldName:   "origin",
+			Value:       "dynamic",
+			ValidTables: neighborTableMarkers,
+		},
+		{
+			Patterns:    []string{"evpn"},
+			FieldName:   "origin",
+			Value:       "evpn",
+			ValidTables: neighborTableMarkers,
+		},
+		{
+			Patterns:              []string{"reachable"},
+			FieldName:             "current-state",
+			Value:                 "reachable",
+			RequiredTableKeywords: []string{"neighbor-discovery"},
+		},
+		{
+			Patterns:              []string{"stale"},
+			FieldName:             "current-state",
+			Value:                 "stale",
+			RequiredTableKeywords: []string{"neighbor-discovery"},
+		},
+		{
+			Patterns:              []string{"incomplete"},
+			FieldName:             "current-state",
+			Value:                 "incomplete",
+			RequiredTableKeywords: []string{"neighbor-discovery"},
+		},
+
+		// === ROUTE TYPE MAPPINGS ===
+		{
+			Patterns:    []string{"bgp route", "bgp-learned", "learned via bgp", "learned from bgp"},
+			FieldName:   "route-type",
+			Value:       "bgp",
+			ValidTables: routeTableMarkers,
+		},
+		{
+			Patterns:    []string{"static route"},
+			FieldName:   "route-type",
+			Value:       "static",
+			ValidTables: routeTableMarkers,
+		},
+		{
+			Patterns:    []string{"connected route", "direct route", "local route"},
+			FieldName:   "route-type",
+			Value:       "local",
+			ValidTables: routeTableMarkers,
+		},
+		{
+			Patterns:    []string{"ospf route", "ospf-learned"},
+			FieldName:   "route-type",
+			Value:       "ospfv2",
+			ValidTables: routeTableMarkers,
+		},
+		{
+			Patterns:    []string{"isis route", "is-is route"},
+			FieldName:   "route-type",
+			Value:       "isis",
+			ValidTables: routeTableMarkers,
+		},
+
 		// === CONNECTOR TYPE MAPPINGS ===
 		{
 			Patterns:              []string{"lc connector", "lc"},
@@ -320,6 +567,13 @@ func GetRegexMappings() []FieldMapping {
 // GetConditionalMappings returns mappings that depend on context
 func GetConditionalMappings() []ConditionalMapping {
 	return []ConditionalMapping{
+		// Address family on route tables whose path does not already fix it
+		addressFamilyMapping("ipv4", "ipv4-unicast"),
+		addressFamilyMapping("ipv6", "ipv6-unicast"),
+		addressFamilyMapping("evpn", "evpn"),
+		// High memory without a number, as a percentage of the memory
+		highMemoryMapping("app-management", "memory-utilization"),
+		highMemoryMapping(".memory", "utilization"),
 		// Special handling for "down" in BGP context
 		{
 			Condition: func(query, tablePath string) bool {
@@ -366,7 +620,8 @@ func FieldKeywordMappings() map[string][]string {
 		"fiber":       {"physical-medium", "connector-type", "wavelength"},
 		"copper":      {"physical-medium", "ethernet-pmd"},
 		"sfp":         {"form-factor", "vendor-part-number"},
-		"mac":         {"hw-mac-address", "system-id-mac"},
+		"mac":         {"hw-mac-address", "link-layer-address", "system-id-mac"},
+		"expir":       {"expiration-time"},
 		"power":       {"input-power", "output-power", "laser-bias-current"},
 		"vendor":      {"vendor", "vendor-part-number", "vendor-serial-number"},
 		"aggregate":   {"aggregate-id", "lag-type", "min-links"},
@@ -374,5 +629,25 @@ func FieldKeywordMappings() map[string][]string {
 		"tagged":      {"vlan-tagging", "vlan-id"},
 		"physical":    {"physical-medium", "linecard", "forwarding-complex"},
 		"hardware":    {"hw-mac-address", "form-factor", "vendor"},
+
+		// Configuration comparison fields, matched as whole words (see
+		// diffKeywords)
+		"changed":  {"last-change", "admin-state", "description"},
+		"diff":     {"last-change", "admin-state", "description"},
+		"modified": {"last-change", "admin-state", "description"},
+	}
+}
+
+// addressFamilyMapping filters route tables that hold several address
+// families on the family named by keyword
+func addressFamilyMapping(keyword, family string) ConditionalMapping {
+	return ConditionalMapping{
+		Condition: func(query, tablePath string) bool {
+			if !isRouteTable(tablePath) || strings.Contains(tablePath, "ipv4") || strings.Contains(tablePath, "ipv6") || strings.Contains(tablePath, "evpn") {
+				return false
+			}
+			return slices.Contains(strings.Fields(query), keyword)
+		},
+		Mappings: []FieldMapping{{FieldName: "address-family", Value: family}},
 	}
 }
@@ -0,0 +1,110 @@
+// Package eql parses explicit field lists such as "show name, mtu for
+// interfaces" so users who know the schema get exactly the fields they named.
+package eql
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+var (
+	// listVerbPattern finds verbs that may be followed by a field list
+	listVerbPattern = regexp.MustCompile(`\b(show|get|display|select|fields?)\b\s*:?\s*`)
+
+	// listEndPattern marks where a field list ends and the table description begins
+	listEndPattern = regexp.MustCompile(`\s(?:for|on|of|from|in|where|with)\s`)
+
+	listSeparatorPattern = regexp.MustCompile(`\s*,\s*(?:and\s+)?|\s+and\s+`)
+	fieldNamePattern     = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+	allFieldsPattern = regexp.MustCompile(`\b(?:all|every)\s+(?:the\s+)?(?:fields?|columns?)\b|\beverything\b`)
+)
+
+// WantsAllFields reports whether the user asked for every column, e.g. "all
+// fields", "every field" or "everything", in which case no field projection
+// should be emitted
+func WantsAllFields(query string) bool {
+	return allFieldsPattern.MatchString(strings.ToLower(query))
+}
+
+// ExtractExplicitFields returns the fields a user listed by name after verbs
+// like "show", "get" or "fields", in the order given, keeping only those the
+// table actually has. Verbs other than "fields" need at least two
+// comma-separated names so ordinary queries are not mistaken for lists.
+func ExtractExplicitFields(query string, availableFields []string) []string {
+	lower := strings.ToLower(query)
+
+	for _, loc := range listVerbPattern.FindAllStringSubmatchIndex(lower, -1) {
+		verb, rest := lower[loc[2]:loc[3]], lower[loc[1]:]
+		if loc := listEndPattern.FindStringIndex(" " + rest + " "); loc != nil {
+			rest = rest[:max(loc[0]-1, 0)]
+		}
+
+		names := listSeparatorPattern.Split(strings.TrimSpace(rest), -1)
+		if !isFieldList(names, verb) {
+			continue
+		}
+
+		if fields := intersectFields(names, availableFields); len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+// NamedFields returns the hyphenated fields of availableFields that the
+// query spells out verbatim, such as "in-octets and out-octets", in the
+// order they appear. Single-word names are skipped as they read too much
+// like ordinary English.
+func NamedFields(query string, availableFields []string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return r != '-' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var fields []string
+	for _, word := range words {
+		if !strings.Contains(word, "-") {
+			continue
+		}
+		for _, available := range availableFields {
+			if strings.EqualFold(word, available) && !slices.Contains(fields, available) {
+				fields = append(fields, available)
+			}
+		}
+	}
+	return fields
+}
+
+// isFieldList reports whether the split names look like a field list
+func isFieldList(names []string, verb string) bool {
+	minNames := 2
+	if strings.HasPrefix(verb, "field") {
+		minNames = 1
+	}
+	if len(names) < minNames {
+		return false
+	}
+
+	for _, name := range names {
+		if !fieldNamePattern.MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// intersectFields keeps the requested names that exist in availableFields,
+// preserving the requested order
+func intersectFields(names, availableFields []string) []string {
+	var fields []string
+	for _, name := range names {
+		for _, available := range availableFields {
+			if strings.EqualFold(name, available) && !slices.Contains(fields, available) {
+				fields = append(fields, available)
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,121 @@
+// Package eql infers the type of a field from its name, so WHERE conditions
+// use operators and literals that suit the field.
+package eql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldType is the kind of value a field holds
+type FieldType int
+
+// Field types, FieldString when the name gives no hint
+const (
+	FieldString FieldType = iota
+	FieldNumeric
+	FieldEnum
+	FieldBool
+)
+
+func (t FieldType) String() string {
+	switch t {
+	case FieldNumeric:
+		return "numeric"
+	case FieldEnum:
+		return "enum"
+	case FieldBool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// Name suffixes and whole names hinting at each type, checked in order
+var (
+	numericFieldSuffixes = []string{"-packets", "-octets", "-count", "-errors", "-discards", "-bps", "-pps", "-utilization", "-usage", "-power", "-temperature", "-percent"}
+	numericFieldNames    = map[string]bool{"count": true, "mtu": true, "ip-mtu": true, "utilization": true, "index": true, "vlan-id": true, "peer-as": true, "local-as": true, "metric": true, "preference": true}
+	enumFieldSuffixes    = []string{"-state", "-status"}
+	enumFieldNames       = map[string]bool{"state": true, "status": true, "severity": true}
+	boolFieldSuffixes    = []string{"-enabled", "-tagging"}
+	boolFieldNames       = map[string]bool{"enabled": true, "tagging": true, "acknowledged": true}
+)
+
+// InferFieldType guesses the type of a field from its name: counters and
+// sizes are numeric, "*-state" is an enum and "*-enabled" or "*-tagging" is
+// a boolean. Fully qualified paths are judged by their last segment.
+func InferFieldType(field string) FieldType {
+	name := strings.ToLower(field[strings.LastIndex(field, ".")+1:])
+	switch {
+	case numericFieldNames[name] || hasAnySuffix(name, numericFieldSuffixes):
+		return FieldNumeric
+	case enumFieldNames[name] || hasAnySuffix(name, enumFieldSuffixes):
+		return FieldEnum
+	case boolFieldNames[name] || hasAnySuffix(name, boolFieldSuffixes):
+		return FieldBool
+	default:
+		return FieldString
+	}
+}
+
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// boolLiterals maps the words a query uses for a boolean to EQL literals
+var boolLiterals = map[string]string{
+	"true": "true", "yes": "true", "enable": "true", "enabled": "true", "on": "true",
+	"false": "false", "no": "false", "disable": "false", "disabled": "false", "off": "false",
+}
+
+// orderingOperators compare magnitudes and only make sense on numbers
+var orderingOperators = []string{">=", "<=", ">", "<"}
+
+// FormatTypedCondition renders a condition like FormatCondition, using the
+// inferred field type to pick the literal: numbers and booleans are left
+// unquoted. It reports false for a condition that cannot hold for the type,
+// such as an ordering comparison on an enum or a word compared to a counter.
+func FormatTypedCondition(field, value string) (string, bool) {
+	fieldType := InferFieldType(field)
+	if _, ok := formatRange(field, value); ok {
+		return FormatCondition(field, value), fieldType == FieldNumeric || fieldType == FieldString
+	}
+
+	if hasOperatorPrefix(value) {
+		if fieldType != FieldNumeric && fieldType != FieldString && hasOrderingOperator(value) {
+			return "", false
+		}
+		return FormatCondition(field, value), true
+	}
+
+	switch fieldType {
+	case FieldNumeric:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", false
+		}
+		return fmt.Sprintf("%s = %s", field, value), true
+	case FieldBool:
+		literal, ok := boolLiterals[strings.ToLower(value)]
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%s = %s", field, literal), true
+	default:
+		return FormatCondition(field, value), true
+	}
+}
+
+func hasOrderingOperator(value string) bool {
+	for _, op := range orderingOperators {
+		if strings.HasPrefix(value, op) {
+			return true
+		}
+	}
+	return false
+}
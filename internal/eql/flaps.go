@@ -0,0 +1,33 @@
+// Package eql ranks interfaces by how often they flapped, such as "interfaces
+// with the most flaps", using the table's transition counter.
+package eql
+
+import (
+	"regexp"
+	"strings"
+)
+
+// flapRankingPattern matches queries ranking by flaps or link transitions
+var flapRankingPattern = regexp.MustCompile(`\b(?:flappiest|(?:most|top(?:\s+\d+)?)\s+(?:flaps?|flapping|(?:link\s+|carrier\s+)?transitions?))\b`)
+
+// flapFieldKeywords name the transition counters, most specific first
+var flapFieldKeywords = []string{"carrier-transitions", "oper-change-count", "flap-count", "transition", "flap"}
+
+// IsFlapRanking reports whether the query asks for the interfaces that
+// flapped the most
+func IsFlapRanking(lower string) bool {
+	return flapRankingPattern.MatchString(lower)
+}
+
+func mentionsFlaps(lower string) bool {
+	return strings.Contains(lower, "flap") || strings.Contains(lower, "transition")
+}
+
+// flapWordPattern matches the words for an interface going down and up
+var flapWordPattern = regexp.MustCompile(`(?i)\bflap(?:s|ped|ping|piest)?\b`)
+
+// FlapsToTransitions replaces flap wording with the words of the counter it
+// means, so scoring finds interface statistics
+func FlapsToTransitions(query string) string {
+	return flapWordPattern.ReplaceAllString(query, "statistics carrier transitions")
+}
@@ -0,0 +1,25 @@
+// Package eql recognizes queries that ask for data from every node, which
+// must not be narrowed by a node filter.
+package eql
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fleetWidePattern matches "all nodes", "every device", "each of the
+// switches", "fleet-wide" and "across the fleet", along with a leading
+// preposition so stripping the phrase leaves no dangling "on" or "for"
+var fleetWidePattern = regexp.MustCompile(`(?i)(?:\b(?:on|for|from|across|in|of)\s+)?(?:\b(?:all|every|each)\s+(?:of\s+)?(?:the\s+)?(?:nodes?|devices?|switches|routers)\b|\bfleet[- ]?wide\b|\b(?:the\s+)?(?:whole|entire)\s+fleet\b|\bthe\s+fleet\b)`)
+
+// IsFleetWide reports whether the query explicitly asks for every node, in
+// which case no node condition is generated
+func IsFleetWide(query string) bool {
+	return fleetWidePattern.MatchString(query)
+}
+
+// StripFleetWide removes fleet-wide wording so words like "all" and "nodes"
+// do not influence table ranking
+func StripFleetWide(query string) string {
+	return strings.Join(strings.Fields(fleetWidePattern.ReplaceAllString(query, " ")), " ")
+}
@@ -0,0 +1,55 @@
+// Package eql renders condition values following EQL string rules.
+package eql
+
+import (
+	"fmt"
+	"strings"
+)
+
+var eqlStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// QuoteString renders a value as an EQL string literal, escaping backslashes
+// and double quotes
+func QuoteString(value string) string {
+	return `"` + eqlStringEscaper.Replace(value) + `"`
+}
+
+// FormatCondition renders a single WHERE condition. Values that already start
+// with an operator (e.g. "> 100", "!= null", `~ "BASE-T"`) are emitted
+// verbatim, "between lo and hi" becomes a pair of inclusive bounds, and
+// anything else is compared for equality as a quoted string.
+func FormatCondition(field, value string) string {
+	if bounds, ok := formatRange(field, value); ok {
+		return bounds
+	}
+	if hasOperatorPrefix(value) {
+		return fmt.Sprintf("%s %s", field, value)
+	}
+	return fmt.Sprintf("%s = %s", field, QuoteString(value))
+}
+
+// operatorPrefixes lists the EQL operators a mapped value may start with.
+// Anything else, including text that merely starts with "!", is a literal.
+var operatorPrefixes = []string{"!=", "!~", ">=", "<=", ">", "<", "=", "~", "in [", "not in ["}
+
+func hasOperatorPrefix(value string) bool {
+	for _, op := range operatorPrefixes {
+		if strings.HasPrefix(value, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatNodeCondition restricts a query to one or more node names
+func formatNodeCondition(nodeNames []string) string {
+	if len(nodeNames) == 1 {
+		return ".namespace.node.name = " + QuoteString(nodeNames[0])
+	}
+
+	nodeList := make([]string, len(nodeNames))
+	for i, name := range nodeNames {
+		nodeList[i] = QuoteString(name)
+	}
+	return fmt.Sprintf(".namespace.node.name in [%s]", strings.Join(nodeList, ", "))
+}
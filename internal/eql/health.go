@@ -0,0 +1,91 @@
+// Package eql expands "healthy" and "unhealthy" on interface and port tables
+// into the state and error conditions they stand for.
+package eql
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CompositeCondition holds when any of its alternatives does
+type CompositeCondition struct {
+	Any []Condition
+}
+
+// String renders the alternatives joined with or, parenthesized so they
+// bind before the and joining the rest of the clause
+func (c CompositeCondition) String() string {
+	parts := make([]string, len(c.Any))
+	for i, alternative := range c.Any {
+		parts[i] = alternative.String()
+	}
+	return joinAlternatives(parts)
+}
+
+func joinAlternatives(parts []string) string {
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, " or ") + ")"
+}
+
+// ExtractCompositeConditions returns the conditions of a query that combine
+// several fields with or, such as "unhealthy interfaces" or "traffic over
+// 10 gbps" in either direction
+func ExtractCompositeConditions(query, tablePath string) []CompositeCondition {
+	lower := strings.ToLower(maskQuotedPhrases(query))
+	var composites []CompositeCondition
+	if c, ok := unhealthyCondition(lower, tablePath); ok {
+		composites = append(composites, c)
+	}
+	return append(composites, unitThresholdComposites(query, tablePath)...)
+}
+
+var (
+	unhealthyPattern = regexp.MustCompile(`\b(?:unhealthy|degraded|problem(?:s|atic)?|failing|broken)\b`)
+	healthyPattern   = regexp.MustCompile(`\bhealthy\b`)
+)
+
+// healthSignals are the fields that tell whether an interface is healthy,
+// with the value for each side. An interface is unhealthy when any signal
+// says so and healthy when all of them agree.
+var healthSignals = []struct {
+	field     string
+	unhealthy string
+	healthy   string
+}{
+	{"oper-state", "down", "up"},
+	{"in-error-packets", "> 0", "= 0"},
+	{"out-error-packets", "> 0", "= 0"},
+}
+
+func isHealthTable(tablePath string) bool {
+	return strings.Contains(tablePath, "interface") || strings.Contains(tablePath, ".port")
+}
+
+// unhealthyCondition returns the alternatives that make an interface
+// unhealthy, for "unhealthy", "degraded" or "problem" queries on interface
+// and port tables
+func unhealthyCondition(lower, tablePath string) (CompositeCondition, bool) {
+	if !isHealthTable(tablePath) || !unhealthyPattern.MatchString(lower) {
+		return CompositeCondition{}, false
+	}
+	var c CompositeCondition
+	for _, signal := range healthSignals {
+		c.Any = append(c.Any, Condition{Field: signal.field, Value: signal.unhealthy})
+	}
+	return c, true
+}
+
+// applyHealthy requires every health signal to be good for "healthy"
+// queries, without overriding a condition the query sets explicitly
+func applyHealthy(lower, tablePath string, conditions map[string]string) {
+	if !isHealthTable(tablePath) || !healthyPattern.MatchString(lower) {
+		return
+	}
+	for _, signal := range healthSignals {
+		if _, ok := conditions[signal.field]; !ok {
+			conditions[signal.field] = signal.healthy
+		}
+	}
+}
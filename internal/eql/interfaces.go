@@ -0,0 +1,125 @@
+// Package eql recognizes interface identifiers in queries and normalizes
+// them to the naming convention of each platform.
+package eql
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+var (
+	// srlInterfacePattern matches SR Linux style names and common shorthands:
+	// ethernet-1/1, ethernet1/1, eth-1/1, eth1/1, e1/1, e1-1 and breakouts
+	// such as ethernet-1/3/1, optionally with a subinterface index suffix
+	// as in ethernet-1/1.100
+	srlInterfacePattern = regexp.MustCompile(`\b(?:ethernet|eth|e)[-_]?(\d+)[/-](\d+)(?:/(\d+))?(?:\.(\d+))?\b`)
+
+	// srosPortPattern matches SR OS port ids such as 1/1/1 and 1/1/c1/1
+	srosPortPattern = regexp.MustCompile(`(?:^|[\s(,])(\d+/\d+/(?:c\d+/)?\d+)\b`)
+
+	// slotPortPattern matches a bare slot/port pair such as 1/1
+	slotPortPattern = regexp.MustCompile(`(?:^|[\s(,])(\d+)/(\d+)(?:$|[\s),?])`)
+)
+
+// ExtractInterfaceNames returns the interface identifiers in a query,
+// normalized for the platform: ethernet-1/1 on SR Linux and 1/1/1 on SR OS.
+// Identifiers written in another platform's convention are ignored.
+func ExtractInterfaceNames(query string, platform models.EmbeddingType) []string {
+	lower := strings.ToLower(query)
+	var names []string
+
+	add := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	switch platform {
+	case models.SROS:
+		for _, match := range srosPortPattern.FindAllStringSubmatch(lower, -1) {
+			add(match[1])
+		}
+	case models.SRL:
+		for _, match := range srlInterfacePattern.FindAllStringSubmatch(lower, -1) {
+			add(srlInterfaceName(match[1], match[2], match[3]))
+		}
+		for _, match := range slotPortPattern.FindAllStringSubmatch(lower, -1) {
+			add(srlInterfaceName(match[1], match[2], ""))
+		}
+	}
+
+	return names
+}
+
+func srlInterfaceName(slot, port, breakout string) string {
+	name := fmt.Sprintf("ethernet-%s/%s", slot, port)
+	if breakout != "" {
+		name += "/" + breakout
+	}
+	return name
+}
+
+// MaskInterfaceNames blanks out interface identifiers so the tokenizer does
+// not split them into misleading words like "ethernet"
+func MaskInterfaceNames(query string) string {
+	masked := srlInterfacePattern.ReplaceAllString(query, " ")
+	masked = srosPortPattern.ReplaceAllString(masked, " ")
+	return slotPortPattern.ReplaceAllString(masked, " ")
+}
+
+// isInterfaceIdentifier reports whether a single word is an interface name
+func isInterfaceIdentifier(word string) bool {
+	return srlInterfacePattern.MatchString(word) || srosPortPattern.MatchString(word) || slotPortPattern.MatchString(word)
+}
+
+// interfaceNameField returns the field holding the interface name for a
+// table. Tables below the interface (or port) list reference the parent's
+// key by its full path, which is not among the table's own fields.
+func interfaceNameField(tablePath string, platform models.EmbeddingType) string {
+	listName, keyField := "interface", "name"
+	if platform == models.SROS {
+		listName, keyField = "port", "port-id"
+	}
+
+	return listKeyField(tablePath, listName, keyField)
+}
+
+// listKeyField returns how a table refers to the key of the list named
+// listName: the bare key on the list itself, or the key's full path on
+// tables below it. It is empty when the table is not within the list.
+func listKeyField(tablePath, listName, keyField string) string {
+	segments := strings.Split(tablePath, ".")
+	i := slices.Index(segments, listName)
+	if i < 0 {
+		return ""
+	}
+	if i == len(segments)-1 {
+		return keyField
+	}
+	return strings.Join(segments[:i+1], ".") + "." + keyField
+}
+
+// applyInterfaceNames adds a condition on the interface name when the query
+// mentions specific interfaces
+func applyInterfaceNames(lower, tablePath string, conditions map[string]string) {
+	platform, ok := models.PlatformFromTable(tablePath)
+	if !ok {
+		return
+	}
+
+	names := ExtractInterfaceNames(lower, platform)
+	if len(names) == 0 {
+		return
+	}
+
+	field := interfaceNameField(tablePath, platform)
+	if field == "" {
+		return
+	}
+
+	conditions[field] = matchAny(names)
+}
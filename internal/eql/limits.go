@@ -0,0 +1,65 @@
+// Package eql chooses the LIMIT of a query: a number the user gave, or a
+// default that depends on what is asked and of which table.
+package eql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+)
+
+var (
+	// explicitLimitPatterns capture a row count the user asked for
+	explicitLimitPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\btop (\d+)`),
+		regexp.MustCompile(`\bfirst (\d+)`),
+		regexp.MustCompile(`\blimit (\d+)`),
+		regexp.MustCompile(`\b(\d+) results`),
+	}
+	superlativeLimitPattern = regexp.MustCompile(`\b(?:top|highest)\b`)
+	enumerationPattern      = regexp.MustCompile(`^(?:list|show|get|display|give)?\s*(?:me\s+)?(?:all|every)\b`)
+)
+
+// ExtractLimit extracts the LIMIT for a query against tablePath. Without an
+// explicit count or ordinal it defaults by intent: DefaultTopLimit for "top",
+// "highest" and "most flaps", DefaultRecentLimit for recent entries of a time-ordered
+// table, and no limit for enumerations such as "list all interfaces".
+func ExtractLimit(query, tablePath string) int {
+	lower := strings.ToLower(strings.TrimSpace(query))
+
+	for _, re := range explicitLimitPatterns {
+		if matches := re.FindStringSubmatch(lower); len(matches) > 1 {
+			if limit, err := strconv.Atoi(matches[1]); err == nil && limit > 0 && limit <= constants.MaxLimitValue {
+				return limit
+			}
+		}
+	}
+
+	// "second highest" needs the first two rows
+	if n := ExtractOrdinal(lower); n > 0 {
+		return n
+	}
+
+	switch {
+	case enumerationPattern.MatchString(lower):
+		return 0
+	case superlativeLimitPattern.MatchString(lower) || IsTopTalkers(lower) || IsFlapRanking(lower):
+		return constants.DefaultTopLimit
+	case isTimeOrderedTable(tablePath) && hasRecentIntent(lower):
+		return constants.DefaultRecentLimit
+	default:
+		return 0
+	}
+}
+
+// isTimeOrderedTable reports whether rows of tablePath are naturally read
+// newest first
+func isTimeOrderedTable(tablePath string) bool {
+	return strings.Contains(tablePath, "alarm")
+}
+
+func hasRecentIntent(lower string) bool {
+	return strings.Contains(lower, "recent") || strings.Contains(lower, "latest")
+}
@@ -0,0 +1,93 @@
+// Package eql filters ARP and IPv6 neighbor discovery tables on MAC and IP
+// addresses.
+package eql
+
+import (
+	"regexp"
+	"strings"
+)
+
+// neighborTableMarkers identify ARP and neighbor discovery tables
+var neighborTableMarkers = []string{".arp.", "neighbor-discovery"}
+
+// macPattern matches MAC addresses written as aa:bb:cc:dd:ee:ff,
+// aa-bb-cc-dd-ee-ff or aabb.ccdd.eeff
+var macPattern = regexp.MustCompile(`(?i)\b([0-9a-f]{2}(?:[:-][0-9a-f]{2}){5}|[0-9a-f]{4}\.[0-9a-f]{4}\.[0-9a-f]{4})\b`)
+
+// ExtractMACAddresses returns the MAC addresses in query as colon separated
+// upper-case hex, the form SR Linux reports them in
+func ExtractMACAddresses(query string) []string {
+	var macs []string
+	for _, match := range macPattern.FindAllStringSubmatch(strings.ToLower(query), -1) {
+		macs = append(macs, normalizeMAC(match[1]))
+	}
+	return macs
+}
+
+func normalizeMAC(mac string) string {
+	hex := strings.NewReplacer(":", "", "-", "", ".", "").Replace(mac)
+	pairs := make([]string, 0, len(hex)/2)
+	for i := 0; i+1 < len(hex); i += 2 {
+		pairs = append(pairs, hex[i:i+2])
+	}
+	return strings.ToUpper(strings.Join(pairs, ":"))
+}
+
+// isAddress reports whether word is an IP address, prefix or MAC address
+func isAddress(word string) bool {
+	return macPattern.MatchString(word) || len(ExtractAddresses(word)) > 0 || len(ExtractPrefixes(word)) > 0
+}
+
+func isNeighborTable(tablePath string) bool {
+	for _, marker := range neighborTableMarkers {
+		if strings.Contains(tablePath, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// macField returns the field holding a MAC address on the table, if any
+func macField(tablePath string) string {
+	switch {
+	case isNeighborTable(tablePath):
+		return "link-layer-address"
+	case strings.HasSuffix(tablePath, ".mac-table.mac"):
+		return "address"
+	case strings.HasSuffix(tablePath, ".ethernet"):
+		return "hw-mac-address"
+	default:
+		return ""
+	}
+}
+
+// applyNeighborConditions filters on MAC addresses wherever the table has a
+// MAC field, and on IP addresses of the table's family on ARP (IPv4) and
+// neighbor discovery (IPv6) tables
+func applyNeighborConditions(lower, tablePath string, conditions map[string]string) {
+	if field := macField(tablePath); field != "" {
+		if macs := ExtractMACAddresses(lower); len(macs) > 0 {
+			conditions[field] = matchAny(macs)
+		}
+	}
+
+	if !isNeighborTable(tablePath) {
+		return
+	}
+
+	ipv4 := strings.Contains(tablePath, ".arp.")
+	var addrs []string
+	for _, addr := range ExtractAddresses(lower) {
+		if addr.Is4() == ipv4 {
+			addrs = append(addrs, addr.String())
+		}
+	}
+	if len(addrs) == 0 {
+		return
+	}
+	if ipv4 {
+		conditions["ipv4-address"] = matchAny(addrs)
+	} else {
+		conditions["ipv6-address"] = matchAny(addrs)
+	}
+}
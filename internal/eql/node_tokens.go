@@ -0,0 +1,43 @@
+// Package eql flags tokens that look like node names but are not picked up
+// by node extraction, so callers can warn that a filter was dropped.
+package eql
+
+import (
+	"slices"
+	"strings"
+)
+
+// nodeTokenPrefixes are the role prefixes node names conventionally start with
+var nodeTokenPrefixes = []string{"leaf", "spine", "superspine", "borderleaf", "border-leaf"}
+
+// UnmatchedNodeTokens returns the query tokens that resemble node names,
+// such as "leaf01a" or "spine-core", but that ExtractNodeNames does not
+// recognize. Such tokens add no node condition to the WHERE clause.
+func UnmatchedNodeTokens(query string) []string {
+	matched := ExtractNodeNames(query)
+
+	var unmatched []string
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		word = cleanPunctuation(word)
+		if !looksLikeNodeName(word) || slices.Contains(matched, word) || slices.Contains(unmatched, word) {
+			continue
+		}
+		unmatched = append(unmatched, word)
+	}
+	return unmatched
+}
+
+// looksLikeNodeName reports whether word carries a node role prefix followed
+// by a qualifier; bare or plural roles ("leaf", "spines") are generic
+func looksLikeNodeName(word string) bool {
+	if word == "leaf-list" {
+		return false
+	}
+	for _, prefix := range nodeTokenPrefixes {
+		rest, ok := strings.CutPrefix(word, prefix)
+		if ok && strings.ContainsAny(rest, "0123456789-_") {
+			return true
+		}
+	}
+	return false
+}
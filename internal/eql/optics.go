@@ -0,0 +1,70 @@
+// Package eql contains threshold extraction for optical power, such as
+// "rx power below -10 dBm", which filters transceiver power readings.
+package eql
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	powerThresholdPattern = regexp.MustCompile(`(over|above|exceeding|more than|greater than|at least|under|below|less than|at most|>=|<=|>|<)\s*(-?\d+(?:\.\d+)?)\s*(dbm\b)?`)
+	powerSuffixPattern    = regexp.MustCompile(`^\s*dbm\b`)
+	powerContextPattern   = regexp.MustCompile(`\b(?:power|optical|light|dbm)\b`)
+	powerDirectionPattern = regexp.MustCompile(`\b(?:rx|input|receive[ds]?|tx|output|transmit(?:ted|s)?)\b`)
+)
+
+// applyPowerThresholds extracts signed decimal thresholds on transceiver
+// tables and applies them to input-power or output-power. A number counts
+// when it carries dBm or the query is about optical power; the direction
+// word before it ("rx", "tx", "output", ...) picks the field, defaulting to
+// the received power. The thresholds used are blanked in the returned query
+// so the plain numeric fallback does not read them again.
+func applyPowerThresholds(lower, tablePath string, conditions map[string]string) string {
+	if !isTransceiverTable(tablePath) {
+		return lower
+	}
+	powerQuery := MentionsOpticalPower(lower)
+
+	field := "input-power"
+	previousEnd := 0
+	masked := []byte(lower)
+	for _, loc := range powerThresholdPattern.FindAllStringSubmatchIndex(lower, -1) {
+		hasUnit := loc[6] >= 0
+		if !hasUnit && (!powerQuery || hasUnitSuffix(lower[loc[1]:]) || hasPercentSuffix(lower[loc[1]:])) {
+			continue
+		}
+
+		// The nearest direction word since the previous threshold applies
+		if words := powerDirectionPattern.FindAllString(lower[previousEnd:loc[0]], -1); len(words) > 0 {
+			field = powerField(words[len(words)-1])
+		}
+		previousEnd = loc[1]
+
+		op := comparisonOperator(lower[loc[2]:loc[3]])
+		conditions[field] = op + " " + lower[loc[4]:loc[5]]
+		for i := loc[0]; i < loc[1]; i++ {
+			masked[i] = ' '
+		}
+	}
+	return string(masked)
+}
+
+// MentionsOpticalPower reports whether the query is about optical power
+func MentionsOpticalPower(lower string) bool {
+	return powerContextPattern.MatchString(lower)
+}
+
+// powerField maps a direction word to the transceiver power field
+func powerField(direction string) string {
+	if direction == "rx" || direction == "input" || strings.HasPrefix(direction, "receive") {
+		return "input-power"
+	}
+	return "output-power"
+}
+
+// hasPowerSuffix reports whether text starts with dBm, meaning the number
+// before it was already handled by applyPowerThresholds
+func hasPowerSuffix(text string) bool {
+	return powerSuffixPattern.MatchString(text)
+}
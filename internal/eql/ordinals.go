@@ -0,0 +1,38 @@
+// Package eql recognizes relative-rank phrasing such as "second highest" or
+// "3rd most", which asks for the Nth entry of a sorted table.
+package eql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+)
+
+// ordinalPattern matches an ordinal directly followed by a superlative
+var ordinalPattern = regexp.MustCompile(`\b(second|third|fourth|fifth|sixth|seventh|eighth|ninth|tenth|\d+(?:st|nd|rd|th))\s+(?:highest|most|lowest|least)\b`)
+
+var ordinalWords = map[string]int{
+	"second": 2, "third": 3, "fourth": 4, "fifth": 5,
+	"sixth": 6, "seventh": 7, "eighth": 8, "ninth": 9, "tenth": 10,
+}
+
+// ExtractOrdinal returns N for "Nth highest/most/lowest/least" phrasing, or
+// 0 when the query has none. EQL has no offset, so callers limit the sorted
+// result to N rows and the requested entry is the last one.
+func ExtractOrdinal(query string) int {
+	match := ordinalPattern.FindStringSubmatch(strings.ToLower(query))
+	if match == nil {
+		return 0
+	}
+	if n, ok := ordinalWords[match[1]]; ok {
+		return n
+	}
+
+	n, err := strconv.Atoi(strings.TrimRight(match[1], "stndrh"))
+	if err != nil || n < 1 || n > constants.MaxLimitValue {
+		return 0
+	}
+	return n
+}
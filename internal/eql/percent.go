@@ -0,0 +1,85 @@
+// Package eql contains threshold extraction for percentages, such as "cpu
+// over 90 percent" or "high utilization", which filter utilization fields.
+package eql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+)
+
+var (
+	percentThresholdPattern = regexp.MustCompile(`(over|above|exceeding|more than|greater than|at least|under|below|less than|at most|>=|<=|>|<)\s*(\d+(?:\.\d+)?)\s*(?:%|percent\b)`)
+	percentSuffixPattern    = regexp.MustCompile(`^\s*(?:%|percent\b)`)
+	highUtilizationPattern  = regexp.MustCompile(`\bhigh(?:ly)?\s+(?:(?:cpu|memory)\s+)?utili(?:[sz]ation|[sz]ed)\b`)
+	highMemoryPattern       = regexp.MustCompile(`\b(?:high|heavy)\s+mem(?:ory)?\b|\bmemory\s+pressure\b`)
+)
+
+// utilizationTableSuffixes end the paths of tables that report a single
+// utilization percentage
+var utilizationTableSuffixes = []string{".cpu", ".memory", ".traffic-rate"}
+
+// applyPercentThresholds extracts percentage thresholds and applies them to
+// the table's utilization field. "high utilization" without a number means
+// above constants.DefaultHighMemoryThreshold percent. Tables without a
+// utilization field get no condition.
+func applyPercentThresholds(lower, tablePath string, conditions map[string]string) {
+	field, ok := utilizationField(lower, tablePath)
+	if !ok {
+		return
+	}
+
+	matches := percentThresholdPattern.FindAllStringSubmatch(lower, -1)
+	if len(matches) == 0 {
+		if highUtilizationPattern.MatchString(lower) {
+			conditions[field] = "> " + strconv.Itoa(constants.DefaultHighMemoryThreshold)
+		}
+		return
+	}
+
+	for _, match := range matches {
+		conditions[field] = comparisonOperator(match[1]) + " " + match[2]
+	}
+}
+
+// utilizationField picks the percentage field of a table: application
+// statistics keep separate CPU and memory utilization, CPU, memory and
+// traffic-rate tables report a single utilization, and other tables have
+// none
+func utilizationField(lower, tablePath string) (string, bool) {
+	if strings.Contains(tablePath, "app-management") {
+		if containsWord(lower, "memory") {
+			return "memory-utilization", true
+		}
+		return "cpu-utilization", true
+	}
+	for _, suffix := range utilizationTableSuffixes {
+		if strings.HasSuffix(tablePath, suffix) {
+			return "utilization", true
+		}
+	}
+	return "", false
+}
+
+// hasPercentSuffix reports whether text starts with a percent sign or word,
+// meaning the number before it was already handled by applyPercentThresholds
+func hasPercentSuffix(text string) bool {
+	return percentSuffixPattern.MatchString(text)
+}
+
+// highMemoryMapping sets field above constants.DefaultHighMemoryThreshold
+// percent on tables whose path contains tableKeyword when the query asks
+// for high memory or memory pressure without giving a threshold
+func highMemoryMapping(tableKeyword, field string) ConditionalMapping {
+	return ConditionalMapping{
+		Condition: func(query, tablePath string) bool {
+			return strings.Contains(tablePath, tableKeyword) &&
+				highMemoryPattern.MatchString(query) &&
+				!percentThresholdPattern.MatchString(query) &&
+				!unitThresholdPattern.MatchString(query)
+		},
+		Mappings: []FieldMapping{{FieldName: field, Value: "> " + strconv.Itoa(constants.DefaultHighMemoryThreshold)}},
+	}
+}
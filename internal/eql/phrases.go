@@ -0,0 +1,53 @@
+// Package eql extracts quoted phrases so they can be matched and filtered on
+// as a whole instead of word by word.
+package eql
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// The quoted text may contain backslash-escaped characters, including
+	// double quotes, as in models.quotedValuePattern
+	quotedPhrasePattern      = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+	descriptionPhrasePattern = regexp.MustCompile(`(?i)\bdescriptions?\s+(?:contains?|containing|like|matching|matches|with|of)?\s*"((?:[^"\\]|\\.)*)"`)
+	phraseEscapePattern      = regexp.MustCompile(`\\(.)`)
+)
+
+// ExtractQuotedPhrases returns the double-quoted phrases in a query, trimmed,
+// unescaped and with their original case
+func ExtractQuotedPhrases(query string) []string {
+	var phrases []string
+	for _, match := range quotedPhrasePattern.FindAllStringSubmatch(query, -1) {
+		if phrase := unescapePhrase(match[1]); phrase != "" {
+			phrases = append(phrases, phrase)
+		}
+	}
+	return phrases
+}
+
+// maskQuotedPhrases blanks out quoted phrases so keyword mappings do not fire
+// on words the user meant literally
+func maskQuotedPhrases(query string) string {
+	return quotedPhrasePattern.ReplaceAllString(query, " ")
+}
+
+// applyDescriptionPhrase adds a regex condition on the description field when
+// the query explicitly asks for descriptions containing a quoted phrase. The
+// phrase is matched literally, so "uplink (spine1)" is not read as a group.
+func applyDescriptionPhrase(query string, conditions map[string]string) {
+	match := descriptionPhrasePattern.FindStringSubmatch(query)
+	if match == nil {
+		return
+	}
+	if phrase := unescapePhrase(match[1]); phrase != "" {
+		conditions["description"] = "~ " + QuoteString(regexp.QuoteMeta(phrase))
+	}
+}
+
+// unescapePhrase trims a captured phrase and drops the backslash from each
+// escaped character, so \" becomes "
+func unescapePhrase(phrase string) string {
+	return strings.TrimSpace(phraseEscapePattern.ReplaceAllString(phrase, "$1"))
+}
@@ -0,0 +1,62 @@
+// Package eql extracts numeric ranges, such as AS number ranges, and renders
+// them as bounded conditions.
+package eql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+)
+
+var (
+	// asRangePattern matches "as between 65000 and 65100", "asn 65000-65100",
+	// "as numbers from 65000 to 65100"
+	asRangePattern = regexp.MustCompile(`\b(?:as|asn|asns)\s+(?:numbers?\s+)?(?:between\s+|from\s+)?(\d+)\s*(?:and|to|-)\s*(\d+)`)
+
+	privateASPattern = regexp.MustCompile(`\bprivate\s+(?:as|asn|asns|as numbers?)\b`)
+
+	betweenValuePattern = regexp.MustCompile(`^between (\d+) and (\d+)$`)
+)
+
+// rangeValue encodes an inclusive range as a condition value understood by
+// FormatCondition
+func rangeValue(low, high int) string {
+	if low > high {
+		low, high = high, low
+	}
+	return fmt.Sprintf("between %d and %d", low, high)
+}
+
+// formatRange renders a "between lo and hi" value as a pair of bounds. The
+// boolean is false when value is not a range.
+func formatRange(field, value string) (string, bool) {
+	match := betweenValuePattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s >= %s and %s <= %s", field, match[1], field, match[2]), true
+}
+
+// applyASRanges adds a peer-as range for AS number ranges and private AS
+// mentions on BGP tables
+func applyASRanges(lower, tablePath string, conditions map[string]string) {
+	if !strings.Contains(tablePath, "bgp") {
+		return
+	}
+
+	if match := asRangePattern.FindStringSubmatch(lower); match != nil {
+		low, errLow := strconv.Atoi(match[1])
+		high, errHigh := strconv.Atoi(match[2])
+		if errLow == nil && errHigh == nil {
+			conditions["peer-as"] = rangeValue(low, high)
+			return
+		}
+	}
+
+	if privateASPattern.MatchString(lower) {
+		conditions["peer-as"] = rangeValue(constants.PrivateASNMin, constants.PrivateASNMax)
+	}
+}
@@ -0,0 +1,82 @@
+// Package eql filters route tables on prefixes and route distinguishers.
+package eql
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+)
+
+// routeTableMarkers identify route, RIB and FIB tables in a table path
+var routeTableMarkers = []string{"route-table", ".rib", "-rib", ".fib", "-fib"}
+
+// routeDistinguisherPattern matches "rd 65000:100" and
+// "route distinguisher 10.0.0.1:5"
+var routeDistinguisherPattern = regexp.MustCompile(`\b(?:rd|route[- ]distinguisher)\s+(\d+(?:\.\d+){0,3}:\d+)\b`)
+
+func isRouteTable(tablePath string) bool {
+	lower := strings.ToLower(tablePath)
+	for _, marker := range routeTableMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixField picks the prefix field of a route table from its address
+// family, e.g. ipv4-prefix under ipv4-unicast
+func prefixField(tablePath string, prefix netip.Prefix) string {
+	switch {
+	case strings.Contains(tablePath, "ipv4"):
+		return "ipv4-prefix"
+	case strings.Contains(tablePath, "ipv6"):
+		return "ipv6-prefix"
+	case prefix.Addr().Is4():
+		return "ipv4-prefix"
+	default:
+		return "ipv6-prefix"
+	}
+}
+
+// applyRouteConditions adds prefix and route distinguisher filters on route
+// tables. Prefixes of the other address family than the table's are
+// ignored.
+func applyRouteConditions(lower, tablePath string, conditions map[string]string) {
+	if !isRouteTable(tablePath) {
+		return
+	}
+
+	byField := make(map[string][]string)
+	var order []string
+	for _, prefix := range ExtractPrefixes(lower) {
+		field := prefixField(tablePath, prefix)
+		if (field == "ipv4-prefix") != prefix.Addr().Is4() {
+			continue
+		}
+		if _, ok := byField[field]; !ok {
+			order = append(order, field)
+		}
+		byField[field] = append(byField[field], prefix.String())
+	}
+	for _, field := range order {
+		conditions[field] = matchAny(byField[field])
+	}
+
+	if match := routeDistinguisherPattern.FindStringSubmatch(lower); match != nil {
+		conditions["route-distinguisher"] = match[1]
+	}
+}
+
+// matchAny returns a single value as is, or an "in [...]" value for several
+func matchAny(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = QuoteString(value)
+	}
+	return fmt.Sprintf("in [%s]", strings.Join(quoted, ", "))
+}
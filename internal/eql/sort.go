@@ -0,0 +1,142 @@
+// Package eql parses explicit sort instructions such as "sort by name
+// descending" or "by errors descending then name ascending" into ORDER BY
+// clauses.
+package eql
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+var (
+	explicitSortPattern  = regexp.MustCompile(`\b(?:sort|sorted|order|ordered)(?:\s+[a-z0-9-]+){0,2}?\s+by\s+(?:the\s+)?([a-z0-9-]+)(?:\s+([a-z0-9-]+))?`)
+	sortDirectionPattern = regexp.MustCompile(`\b(ascending|descending|asc|desc)\b`)
+	naturalSortPattern   = regexp.MustCompile(`\bnatural(?:ly)?\b`)
+	sortChainStart       = regexp.MustCompile(`\bby\s+`)
+	sortChainSeparator   = regexp.MustCompile(`\s*(?:,\s*(?:then\s+)?|\s(?:and\s+)?then\s+)(?:by\s+)?`)
+)
+
+// extractSortChain reads several sort keys after "by", separated by "then"
+// or commas, each with its own direction: "by errors descending then name
+// ascending". A key without a direction word continues the previous key's
+// direction; the first defaults to descending after a superlative such as
+// "top". It returns nil unless at least two keys name available fields.
+func extractSortChain(lower string, findSortField func([]string) string) []models.OrderByClause {
+	loc := sortChainStart.FindStringIndex(lower)
+	if loc == nil {
+		return nil
+	}
+
+	direction := "ascending"
+	if hasDescendingKeywords(lower) {
+		direction = "descending"
+	}
+
+	var orderBy []models.OrderByClause
+	for _, segment := range sortChainSeparator.Split(lower[loc[1]:], -1) {
+		field := findSortField(sortKeyKeywords(segment))
+		if field == "" || slices.ContainsFunc(orderBy, func(c models.OrderByClause) bool { return c.Field == field }) {
+			break
+		}
+		if match := sortDirectionPattern.FindStringSubmatch(segment); match != nil {
+			direction = normalizeSortDirection(match[1])
+		}
+		orderBy = append(orderBy, models.OrderByClause{Field: field, Direction: direction})
+	}
+
+	if len(orderBy) < 2 {
+		return nil
+	}
+	return orderBy
+}
+
+// sortKeyKeywords returns field keywords for one key of a sort chain: its
+// first two words joined as a field name, then the first word, each also
+// without a plural "s" so "errors" finds in-error-packets
+func sortKeyKeywords(segment string) []string {
+	words := strings.Fields(strings.TrimPrefix(segment, "the "))
+	if len(words) == 0 {
+		return nil
+	}
+
+	var candidates []string
+	if len(words) > 1 && !sortDirectionPattern.MatchString(words[1]) {
+		candidates = append(candidates, words[0]+"-"+words[1])
+	}
+	candidates = append(candidates, words[0])
+
+	var keywords []string
+	for _, candidate := range candidates {
+		keywords = append(keywords, candidate)
+		if singular := strings.TrimSuffix(candidate, "s"); singular != candidate && len(singular) >= constants.MinTokenLength {
+			keywords = append(keywords, singular)
+		}
+	}
+	return keywords
+}
+
+// extractExplicitSort reads "sort by <field>" / "order by <field>". It
+// returns nil when the phrase is absent or names no available field.
+func extractExplicitSort(lower string, findSortField func([]string) string) *models.OrderByClause {
+	match := explicitSortPattern.FindStringSubmatch(lower)
+	if match == nil {
+		return nil
+	}
+
+	keywords := []string{match[1]}
+	if match[2] != "" {
+		keywords = []string{match[1] + "-" + match[2], match[1]}
+	}
+
+	sortField := findSortField(keywords)
+	if sortField == "" {
+		return nil
+	}
+	return &models.OrderByClause{
+		Field:     sortField,
+		Direction: "ascending",
+	}
+}
+
+// applySortModifiers lets explicit direction words override the inferred
+// direction of every clause, then applies applySortAlgorithms
+func applySortModifiers(lower string, orderBy []models.OrderByClause) []models.OrderByClause {
+	if match := sortDirectionPattern.FindStringSubmatch(lower); match != nil {
+		direction := normalizeSortDirection(match[1])
+		for i := range orderBy {
+			orderBy[i].Direction = direction
+		}
+	}
+	return applySortAlgorithms(lower, orderBy)
+}
+
+// applySortAlgorithms selects the natural algorithm when the query says
+// "natural", and always for name-like fields so ethernet-1/2 sorts before
+// ethernet-1/10
+func applySortAlgorithms(lower string, orderBy []models.OrderByClause) []models.OrderByClause {
+	natural := naturalSortPattern.MatchString(lower)
+	for i := range orderBy {
+		if natural || isNameLikeField(orderBy[i].Field) {
+			orderBy[i].Algorithm = "natural"
+		}
+	}
+	return orderBy
+}
+
+// isNameLikeField reports whether a field holds identifiers that embed
+// numbers, such as interface names or port ids
+func isNameLikeField(field string) bool {
+	return field == "name" || field == "id" ||
+		strings.HasSuffix(field, "-name") || strings.HasSuffix(field, "-id")
+}
+
+func normalizeSortDirection(word string) string {
+	if word == "desc" || word == "descending" {
+		return "descending"
+	}
+	return "ascending"
+}
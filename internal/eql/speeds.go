@@ -0,0 +1,178 @@
+// Package eql extracts interface speed ranges, such as "faster than 25g" or
+// "between 10g and 100g", which need the port speeds in order.
+package eql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// portSpeeds lists the SR Linux port-speed values in Gbps, slowest first
+var portSpeeds = []struct {
+	value string
+	gbps  float64
+}{
+	{"10M", 0.01},
+	{"100M", 0.1},
+	{"1G", 1},
+	{"10G", 10},
+	{"25G", 25},
+	{"40G", 40},
+	{"50G", 50},
+	{"100G", 100},
+	{"200G", 200},
+	{"400G", 400},
+	{"800G", 800},
+}
+
+// srosSpeedField returns the SR OS field holding a port's speed in Mbps:
+// speed under configure, oper-speed under state
+func srosSpeedField(tablePath string) string {
+	if strings.Contains(tablePath, ".configure.") {
+		return "speed"
+	}
+	return "oper-speed"
+}
+
+const speedUnit = `\s*(g|gig|gbps|gb/s|m|mbps|mb/s)\b`
+
+var (
+	speedBetweenPattern    = regexp.MustCompile(`\bbetween\s+(\d+)` + speedUnit + `\s+and\s+(\d+)` + speedUnit)
+	speedComparisonPattern = regexp.MustCompile(`\b(faster than|slower than|over|above|more than|greater than|at least|under|below|less than|at most)\s+(\d+)` + speedUnit)
+	speedContextPattern    = regexp.MustCompile(`\bspeeds?\b`)
+)
+
+// applySpeedRanges turns speed comparisons and ranges on ethernet tables
+// into conditions, replacing the single speed the field mappings read from
+// the same words. SR Linux speeds are enums, so the range becomes the set of
+// qualifying values, or no condition when none qualifies; SR OS speeds are
+// Mbps and get numeric bounds. Only "faster"/"slower" and units written "g"
+// imply a speed by themselves, so "traffic over 10 gbps" stays a rate
+// threshold. The phrase used is blanked in the returned query.
+func applySpeedRanges(lower, tablePath string, conditions map[string]string) string {
+	platform, ok := models.PlatformFromTable(tablePath)
+	if !ok || !isSpeedTable(platform, tablePath) {
+		return lower
+	}
+
+	low, high, loc := speedBounds(lower)
+	if loc == nil {
+		return lower
+	}
+
+	if platform == models.SROS {
+		conditions[srosSpeedField(tablePath)] = srosSpeedCondition(low, high)
+	} else if values := portSpeedsWithin(low, high); len(values) > 0 {
+		conditions["port-speed"] = "in [" + strings.Join(values, ", ") + "]"
+	} else {
+		delete(conditions, "port-speed")
+	}
+	return lower[:loc[0]] + strings.Repeat(" ", loc[1]-loc[0]) + lower[loc[1]:]
+}
+
+func isSpeedTable(platform models.EmbeddingType, tablePath string) bool {
+	if platform == models.SROS {
+		return strings.Contains(tablePath, "port") || strings.Contains(tablePath, "ethernet")
+	}
+	return strings.Contains(tablePath, "ethernet") || strings.Contains(tablePath, "interface")
+}
+
+// speedBound is one end of a speed range in Gbps
+type speedBound struct {
+	gbps      float64
+	inclusive bool
+}
+
+// speedBounds returns the range a query asks for, with nil bounds for an open
+// end, and the location of the phrase, nil when there is none
+func speedBounds(lower string) (low, high *speedBound, loc []int) {
+	if m := speedBetweenPattern.FindStringSubmatchIndex(lower); m != nil {
+		a := &speedBound{speedGbps(lower[m[2]:m[3]], lower[m[4]:m[5]]), true}
+		b := &speedBound{speedGbps(lower[m[6]:m[7]], lower[m[8]:m[9]]), true}
+		if a.gbps > b.gbps {
+			a, b = b, a
+		}
+		return a, b, m[:2]
+	}
+
+	for _, m := range speedComparisonPattern.FindAllStringSubmatchIndex(lower, -1) {
+		phrase, unit := lower[m[2]:m[3]], lower[m[6]:m[7]]
+		explicit := strings.HasPrefix(phrase, "faster") || strings.HasPrefix(phrase, "slower")
+		if !explicit && unit != "g" && unit != "gig" && !speedContextPattern.MatchString(lower) {
+			continue
+		}
+
+		bound := &speedBound{gbps: speedGbps(lower[m[4]:m[5]], unit)}
+		switch phrase {
+		case "faster than":
+			return bound, nil, m[:2]
+		case "slower than":
+			return nil, bound, m[:2]
+		}
+		switch op := comparisonOperator(phrase); op {
+		case ">", ">=":
+			bound.inclusive = op == ">="
+			return bound, nil, m[:2]
+		default:
+			bound.inclusive = op == "<="
+			return nil, bound, m[:2]
+		}
+	}
+	return nil, nil, nil
+}
+
+// speedGbps converts a number and its unit to Gbps
+func speedGbps(number, unit string) float64 {
+	value, _ := strconv.ParseFloat(number, 64)
+	if strings.HasPrefix(unit, "m") {
+		return value / 1000
+	}
+	return value
+}
+
+func (b *speedBound) admits(gbps float64, lowerEnd bool) bool {
+	switch {
+	case b == nil:
+		return true
+	case gbps == b.gbps:
+		return b.inclusive
+	case lowerEnd:
+		return gbps > b.gbps
+	default:
+		return gbps < b.gbps
+	}
+}
+
+// portSpeedsWithin returns the quoted port-speed values within the range
+func portSpeedsWithin(low, high *speedBound) []string {
+	var values []string
+	for _, speed := range portSpeeds {
+		if low.admits(speed.gbps, true) && high.admits(speed.gbps, false) {
+			values = append(values, QuoteString(speed.value))
+		}
+	}
+	return values
+}
+
+// srosSpeedCondition bounds a speed in Mbps
+func srosSpeedCondition(low, high *speedBound) string {
+	bound := func(b *speedBound, strict, inclusive string) string {
+		op := strict
+		if b.inclusive {
+			op = inclusive
+		}
+		return fmt.Sprintf("%s %d", op, int(b.gbps*1000))
+	}
+	switch {
+	case low != nil && high != nil:
+		return rangeValue(int(low.gbps*1000), int(high.gbps*1000))
+	case low != nil:
+		return bound(low, ">", ">=")
+	default:
+		return bound(high, "<", "<=")
+	}
+}
@@ -0,0 +1,65 @@
+// Package eql recognizes subinterface indices such as "subinterface 100"
+// or "ethernet-1/1.100" and filters subinterface tables on them.
+package eql
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// subinterfaceIndexPattern matches "subinterface 100", "sub-interface
+	// 200", "subif 3" and "unit 5"
+	subinterfaceIndexPattern = regexp.MustCompile(`\b(?:sub-?interfaces?|subifs?|unit)\s+(?:index\s+)?(\d+)\b`)
+
+	// dottedSubinterfacePattern matches the index in "ethernet-1/1.100"
+	dottedSubinterfacePattern = regexp.MustCompile(`\b(?:ethernet|eth|e)[-_]?\d+[/-]\d+(?:/\d+)?\.(\d+)\b`)
+
+	// subinterfaceSpellingPattern matches spellings of "subinterface" that
+	// the tokenizer would split or not recognize
+	subinterfaceSpellingPattern = regexp.MustCompile(`(?i)\b(?:sub-interface|subif)(s?)\b|\bunit(\s+\d+)`)
+)
+
+// ExtractSubinterfaceIndex returns the subinterface index a query mentions,
+// or an empty string when there is none
+func ExtractSubinterfaceIndex(query string) string {
+	lower := strings.ToLower(query)
+	if match := dottedSubinterfacePattern.FindStringSubmatch(lower); match != nil {
+		return match[1]
+	}
+	if match := subinterfaceIndexPattern.FindStringSubmatch(lower); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// NormalizeSubinterfaceTerms rewrites "sub-interface", "subif" and "unit N"
+// as "subinterface" so the term survives tokenization as one word. A dotted
+// name such as ethernet-1/1.100 adds the word when the query lacks it.
+func NormalizeSubinterfaceTerms(query string) string {
+	query = subinterfaceSpellingPattern.ReplaceAllStringFunc(query, func(match string) string {
+		sub := subinterfaceSpellingPattern.FindStringSubmatch(strings.ToLower(match))
+		if sub[2] != "" {
+			return "subinterface" + sub[2]
+		}
+		return "subinterface" + sub[1]
+	})
+
+	lower := strings.ToLower(query)
+	if dottedSubinterfacePattern.MatchString(lower) && !strings.Contains(lower, "subinterface") {
+		query += " subinterface"
+	}
+	return query
+}
+
+// applySubinterfaceIndex filters subinterface tables, and tables below
+// them, on the subinterface index
+func applySubinterfaceIndex(lower, tablePath string, conditions map[string]string) {
+	index := ExtractSubinterfaceIndex(lower)
+	if index == "" {
+		return
+	}
+	if field := listKeyField(tablePath, "subinterface", "index"); field != "" {
+		conditions[field] = "= " + index
+	}
+}
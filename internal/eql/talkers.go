@@ -0,0 +1,48 @@
+// Package eql recognizes the "top talkers" idiom: the interfaces moving the
+// most traffic, sorted by octet counters.
+package eql
+
+import (
+	"regexp"
+	"slices"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// topTalkersPattern matches "top talkers", "top 5 talkers" and "heavy hitters"
+var topTalkersPattern = regexp.MustCompile(`(?i)\b(?:top(?:\s+\d+)?\s+talkers?|heavy[\s-]+hitters?)\b`)
+
+// talkerFields are the counters top talkers are ranked by, most significant first
+var talkerFields = []string{"in-octets", "out-octets"}
+
+// IsTopTalkers reports whether the query asks for the top talkers
+func IsTopTalkers(query string) bool {
+	return topTalkersPattern.MatchString(query)
+}
+
+// TopTalkersToTraffic replaces the idiom with the words of the tables it
+// means, so scoring finds interface statistics
+func TopTalkersToTraffic(query string) string {
+	return topTalkersPattern.ReplaceAllString(query, "interface statistics traffic")
+}
+
+// topTalkerFields returns the talker counters present in availableFields
+func topTalkerFields(availableFields []string) []string {
+	var fields []string
+	for _, field := range talkerFields {
+		if slices.Contains(availableFields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// topTalkersOrderBy sorts by every available talker counter, descending.
+// EQL cannot order by a sum, so out-octets breaks ties on in-octets.
+func topTalkersOrderBy(availableFields []string) []models.OrderByClause {
+	var orderBy []models.OrderByClause
+	for _, field := range topTalkerFields(availableFields) {
+		orderBy = append(orderBy, models.OrderByClause{Field: field, Direction: "descending"})
+	}
+	return orderBy
+}
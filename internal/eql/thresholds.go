@@ -0,0 +1,191 @@
+// Package eql contains threshold extraction for numeric conditions that carry
+// units, such as traffic rates and memory sizes.
+package eql
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// unitKind groups units that normalize to the same base unit
+type unitKind int
+
+const (
+	unitRate unitKind = iota // bits per second
+	unitSize                 // bytes
+)
+
+// unitSpec describes how a unit suffix converts to its base unit
+type unitSpec struct {
+	kind       unitKind
+	multiplier float64
+}
+
+var unitSpecs = map[string]unitSpec{
+	"tbps":  {unitRate, 1e12},
+	"gbps":  {unitRate, 1e9},
+	"mbps":  {unitRate, 1e6},
+	"kbps":  {unitRate, 1e3},
+	"bps":   {unitRate, 1},
+	"tb":    {unitSize, 1 << 40},
+	"gb":    {unitSize, 1 << 30},
+	"mb":    {unitSize, 1 << 20},
+	"kb":    {unitSize, 1 << 10},
+	"bytes": {unitSize, 1},
+}
+
+var (
+	unitThresholdPattern = regexp.MustCompile(`(over|above|exceeding|more than|greater than|at least|under|below|less than|at most|>=|<=|>|<)\s*(\d+(?:\.\d+)?)\s*(tbps|gbps|mbps|kbps|bps|tb|gb|mb|kb|bytes)\b`)
+	unitSuffixPattern    = regexp.MustCompile(`^\s*(?:tbps|gbps|mbps|kbps|bps|tb|gb|mb|kb|bytes)\b`)
+)
+
+// comparisonOperator maps comparison phrasing to an EQL operator
+func comparisonOperator(phrase string) string {
+	switch phrase {
+	case "over", "above", "exceeding", "more than", "greater than", ">":
+		return ">"
+	case "at least", ">=":
+		return ">="
+	case "under", "below", "less than", "<":
+		return "<"
+	case "at most", "<=":
+		return "<="
+	default:
+		return phrase
+	}
+}
+
+// rateDirections are the bps fields selected by words naming a traffic
+// direction
+var rateDirections = []struct {
+	field string
+	words []string
+}{
+	{"in-bps", []string{"ingress", "rx", "inbound", "incoming", "receive"}},
+	{"out-bps", []string{"out", "egress", "tx", "outbound", "outgoing", "transmit"}},
+}
+
+// unitThreshold is one threshold with a unit found in a query. Fields holds
+// the fields it applies to on the table, several when any of them may
+// match, and none when the table stores no value in that unit.
+type unitThreshold struct {
+	phrase string
+	fields []string
+	value  string
+}
+
+// extractUnitThresholds finds thresholds such as "over 10 gbps" or "more
+// than 2 gb" and normalizes them to bps or bytes
+func extractUnitThresholds(lower, tablePath string) []unitThreshold {
+	var thresholds []unitThreshold
+	for _, match := range unitThresholdPattern.FindAllStringSubmatch(lower, -1) {
+		value, err := strconv.ParseFloat(match[2], 64)
+		if err != nil {
+			continue
+		}
+		spec := unitSpecs[match[3]]
+		thresholds = append(thresholds, unitThreshold{
+			phrase: match[0],
+			fields: unitFields(lower, tablePath, spec.kind),
+			value:  comparisonOperator(match[1]) + " " + strconv.FormatFloat(value*spec.multiplier, 'f', 0, 64),
+		})
+	}
+	return thresholds
+}
+
+// applyUnitThresholds applies the thresholds that target a single field of
+// the table. Those matching either traffic direction become composite
+// conditions instead, see unitThresholdComposites.
+func applyUnitThresholds(lower, tablePath string, conditions map[string]string) {
+	for _, threshold := range extractUnitThresholds(lower, tablePath) {
+		if len(threshold.fields) == 1 {
+			conditions[threshold.fields[0]] = threshold.value
+		}
+	}
+}
+
+// unitThresholdComposites returns the thresholds that name no traffic
+// direction, which hold when either the inbound or the outbound rate
+// crosses them
+func unitThresholdComposites(query, tablePath string) []CompositeCondition {
+	var composites []CompositeCondition
+	for _, threshold := range extractUnitThresholds(unitThresholdText(query, tablePath), tablePath) {
+		if len(threshold.fields) < 2 {
+			continue
+		}
+		var c CompositeCondition
+		for _, field := range threshold.fields {
+			c.Any = append(c.Any, Condition{Field: field, Value: threshold.value})
+		}
+		composites = append(composites, c)
+	}
+	return composites
+}
+
+// UnappliedUnitThresholds returns the thresholds with a unit, such as "more
+// than 2 gb", that the table has no field for. They add no condition to the
+// WHERE clause.
+func UnappliedUnitThresholds(query, tablePath string) []string {
+	var phrases []string
+	for _, threshold := range extractUnitThresholds(unitThresholdText(query, tablePath), tablePath) {
+		if len(threshold.fields) == 0 {
+			phrases = append(phrases, threshold.phrase)
+		}
+	}
+	return phrases
+}
+
+// unitThresholdText is the query as applyUnitThresholds sees it: lowercase,
+// without quoted phrases and without the speed range that applySpeedRanges
+// turns into a port speed condition
+func unitThresholdText(query, tablePath string) string {
+	lower := strings.ToLower(maskQuotedPhrases(query))
+	return applySpeedRanges(lower, tablePath, make(map[string]string))
+}
+
+// unitFields picks the fields that store values of the given unit kind:
+// application statistics carry memory sizes and traffic-rate tables carry
+// rates, inbound and outbound unless the query names a direction
+func unitFields(lower, tablePath string, kind unitKind) []string {
+	if kind == unitSize {
+		if strings.Contains(tablePath, "app-management") {
+			return []string{"memory-usage"}
+		}
+		return nil
+	}
+	if !strings.HasSuffix(tablePath, ".traffic-rate") {
+		return nil
+	}
+	var fields []string
+	for _, direction := range rateDirections {
+		for _, word := range direction.words {
+			if containsWord(lower, word) {
+				fields = append(fields, direction.field)
+				break
+			}
+		}
+	}
+	if len(fields) == 0 {
+		for _, direction := range rateDirections {
+			fields = append(fields, direction.field)
+		}
+	}
+	return fields
+}
+
+// hasUnitSuffix reports whether text starts with a unit, meaning the number
+// before it was already handled by applyUnitThresholds
+func hasUnitSuffix(text string) bool {
+	return unitSuffixPattern.MatchString(text)
+}
+
+// containsWord reports whether word appears in text as a whole word
+func containsWord(text, word string) bool {
+	for _, field := range strings.Fields(text) {
+		if strings.Trim(field, ".,?!") == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Package eql extracts transceiver serial numbers and vendor names, keeping
+// the case the user wrote them in.
+package eql
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// serialPattern matches "serial XYZ123", "serial number: XYZ123" or
+	// "vendor-serial-number "XYZ123"". Unquoted serials must contain a digit
+	// so "serial numbers of transceivers" is not read as a value.
+	serialPattern = regexp.MustCompile(`(?i)\b(?:vendor[- ])?serial(?:[- ]numbers?|[- ]no\.?|\s*#)?\s*(?:=|:|is\s+|of\s+)?\s*(?:"([^"]+)"|([a-z0-9][a-z0-9._/-]*\d[a-z0-9._/-]*))`)
+
+	// vendorPattern matches `vendor "Finisar"` or "vendor FINISAR". Unquoted
+	// names must be upper case so "vendor part number" is not read as one.
+	vendorPattern = regexp.MustCompile(`(?i:\bvendor(?:[- ]name)?)\s*(?:=|:|is\s+)?\s*(?:"([^"]+)"|([A-Z0-9][A-Z0-9&-]*[A-Z0-9])\b)`)
+)
+
+func isTransceiverTable(tablePath string) bool {
+	return strings.Contains(tablePath, "transceiver")
+}
+
+// applyTransceiverValues adds serial number and vendor conditions on
+// transceiver tables from the original-case query. The matched phrases are
+// blanked in the returned query so the values do not feed other mappings.
+func applyTransceiverValues(query, tablePath string, conditions map[string]string) string {
+	if !isTransceiverTable(tablePath) {
+		return query
+	}
+
+	serialField := "vendor-serial-number"
+	if strings.Contains(tablePath, ".sros.") {
+		serialField = "serial-number"
+	}
+
+	query = applyValuePattern(query, serialPattern, serialField, conditions)
+	return applyValuePattern(query, vendorPattern, "vendor", conditions)
+}
+
+// applyValuePattern sets field to the quoted or bare value captured by
+// pattern and blanks the match
+func applyValuePattern(query string, pattern *regexp.Regexp, field string, conditions map[string]string) string {
+	loc := pattern.FindStringSubmatchIndex(query)
+	if loc == nil {
+		return query
+	}
+
+	value := ""
+	if loc[2] >= 0 {
+		value = strings.TrimSpace(query[loc[2]:loc[3]])
+	} else {
+		value = query[loc[4]:loc[5]]
+	}
+	if value == "" {
+		return query
+	}
+
+	conditions[field] = value
+	return query[:loc[0]] + strings.Repeat(" ", loc[1]-loc[0]) + query[loc[1]:]
+}
@@ -0,0 +1,77 @@
+// Package output colorizes text output with ANSI escapes.
+package output
+
+import (
+	"os"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// ANSI escape sequences used for text output
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiCyan    = "\033[36m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+	ansiMagenta = "\033[35m"
+)
+
+// palette wraps text in ANSI colors, or leaves it untouched when disabled
+type palette struct {
+	enabled bool
+}
+
+// UseColor reports whether text written to stdout should be colored: only
+// for a terminal, and not when disabled by noColor (the -no-color flag) or
+// the NO_COLOR environment variable
+func UseColor(noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// Heading renders a section heading, in bold when color is set
+func Heading(text string, color bool) string {
+	return palette{enabled: color}.heading(text)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (p palette) wrap(code, text string) string {
+	if !p.enabled || text == "" {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// score highlights a formatted score
+func (p palette) score(text string) string {
+	return p.wrap(ansiYellow, text)
+}
+
+// heading highlights a section heading
+func (p palette) heading(text string) string {
+	return p.wrap(ansiBold, text)
+}
+
+// query renders an EQL query with the table path, WHERE clause and other
+// clauses in distinct colors
+func (p palette) query(q *models.EQLQuery) string {
+	return q.Render(func(clause models.Clause, text string) string {
+		switch clause {
+		case models.ClauseTable:
+			return p.wrap(ansiBold+ansiCyan, text)
+		case models.ClauseWhere:
+			return p.wrap(ansiGreen, text)
+		case models.ClauseFields:
+			return text
+		default:
+			return p.wrap(ansiMagenta, text)
+		}
+	})
+}
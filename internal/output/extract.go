@@ -0,0 +1,24 @@
+// Package output writes the bare EQL of the top match, for scripts that
+// capture a single line.
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// ErrNoMatches is returned by Extract when there is no result to print
+var ErrNoMatches = errors.New("no matches found")
+
+// Extract writes the EQL query of the top match on a line of its own. When
+// nothing matched it writes nothing and returns ErrNoMatches.
+func Extract(w io.Writer, results []models.SearchResult) error {
+	if len(results) == 0 {
+		return ErrNoMatches
+	}
+	_, err := fmt.Fprintln(w, results[0].EQLQuery.String())
+	return err
+}
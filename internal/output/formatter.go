@@ -0,0 +1,53 @@
+// Package output renders search results as text, JSON, YAML or a
+// user-supplied template, so the CLI and other front ends share one
+// implementation of each format.
+package output
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Formatter writes search results to w. Results are ranked best first; an
+// empty slice means nothing matched.
+type Formatter interface {
+	Format(w io.Writer, results []models.SearchResult, opts Options) error
+}
+
+// Options carries what a formatter reports besides the results
+type Options struct {
+	UnmatchedTokens []string         // node-like tokens that did not become a node filter
+	AppliedSynonyms []search.Synonym // query words replaced before searching
+	Color           bool             // colorize text output with ANSI escapes
+	All             bool             // print every result, not only the first MaxSearchResults
+}
+
+// Names lists the formats ForName accepts
+var Names = []string{"text", "json", "yaml"}
+
+// ForName returns the formatter for a format name listed in Names
+func ForName(name string) (Formatter, error) {
+	switch name {
+	case "text":
+		return Text{}, nil
+	case "json":
+		return JSON{}, nil
+	case "yaml":
+		return YAML{}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (must be text, json or yaml)", name)
+	}
+}
+
+// shown returns the results a formatter prints: the top match and up to
+// nine others, or all of them with opts.All
+func shown(results []models.SearchResult, opts Options) []models.SearchResult {
+	if opts.All {
+		return results
+	}
+	return results[:min(len(results), constants.MaxSearchResults)]
+}
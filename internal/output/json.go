@@ -0,0 +1,71 @@
+// Package output renders results as JSON, in the document shape the schema
+// command describes.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Document is the JSON and YAML output for a search with results
+type Document struct {
+	TopMatch        *models.SearchResult  `json:"topMatch"`
+	Others          []models.SearchResult `json:"others,omitempty"`
+	UnmatchedTokens []string              `json:"unmatchedTokens,omitempty"`
+	AppliedSynonyms []search.Synonym      `json:"appliedSynonyms,omitempty"`
+}
+
+// noMatches is the document written when nothing matched; JSON output
+// keeps it on one line as noMatchesJSON
+type noMatches struct {
+	Error   string `json:"error"`
+	Results []any  `json:"results"`
+}
+
+const noMatchesJSON = `{"error": "No matches found", "results": []}`
+
+// JSON prints the results as an indented Document
+type JSON struct{}
+
+// Format implements Formatter
+func (JSON) Format(w io.Writer, results []models.SearchResult, opts Options) error {
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, noMatchesJSON)
+		return err
+	}
+	data, err := marshalDocument(results, opts)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// NewDocument builds the Document for results, which must not be empty
+func NewDocument(results []models.SearchResult, opts Options) Document {
+	results = shown(results, opts)
+	return Document{
+		TopMatch:        &results[0],
+		Others:          results[1:],
+		UnmatchedTokens: opts.UnmatchedTokens,
+		AppliedSynonyms: opts.AppliedSynonyms,
+	}
+}
+
+// marshalDocument encodes the Document for results, or the no-match
+// document when there are none
+func marshalDocument(results []models.SearchResult, opts Options) ([]byte, error) {
+	var doc any = noMatches{Error: "No matches found", Results: []any{}}
+	if len(results) > 0 {
+		doc = NewDocument(results, opts)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,94 @@
+// Package output renders multi-concept searches, one block per part of the
+// query, in the formats that support it.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// MultiFormatter writes the results of a search split into concepts.
+// Template does not implement it, since templates are written against
+// TemplateData.
+type MultiFormatter interface {
+	FormatMulti(w io.Writer, concepts []search.ConceptResults, opts Options) error
+}
+
+// MultiDocument is the JSON and YAML output for a multi-concept search
+type MultiDocument struct {
+	Concepts []ConceptMatch `json:"concepts"`
+}
+
+// ConceptMatch is one concept with its top match, omitted when nothing
+// matched
+type ConceptMatch struct {
+	Concept  string               `json:"concept"`
+	TopMatch *models.SearchResult `json:"topMatch,omitempty"`
+}
+
+// NewMultiDocument builds the MultiDocument for concepts
+func NewMultiDocument(concepts []search.ConceptResults) MultiDocument {
+	doc := MultiDocument{Concepts: make([]ConceptMatch, len(concepts))}
+	for i := range concepts {
+		doc.Concepts[i].Concept = concepts[i].Concept
+		if len(concepts[i].Results) > 0 {
+			doc.Concepts[i].TopMatch = &concepts[i].Results[0]
+		}
+	}
+	return doc
+}
+
+func marshalMultiDocument(concepts []search.ConceptResults) ([]byte, error) {
+	data, err := json.MarshalIndent(NewMultiDocument(concepts), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return data, nil
+}
+
+// FormatMulti implements MultiFormatter, printing each concept under a
+// heading as Format would
+func (t Text) FormatMulti(w io.Writer, concepts []search.ConceptResults, opts Options) error {
+	for i, concept := range concepts {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, Heading("Concept: "+concept.Concept, opts.Color)); err != nil {
+			return err
+		}
+		if err := t.Format(w, concept.Results, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FormatMulti implements MultiFormatter, printing an indented MultiDocument
+func (JSON) FormatMulti(w io.Writer, concepts []search.ConceptResults, _ Options) error {
+	data, err := marshalMultiDocument(concepts)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// FormatMulti implements MultiFormatter, printing the MultiDocument as YAML
+func (YAML) FormatMulti(w io.Writer, concepts []search.ConceptResults, _ Options) error {
+	data, err := marshalMultiDocument(concepts)
+	if err != nil {
+		return err
+	}
+	text, err := jsonToYAML(data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, text)
+	return err
+}
@@ -0,0 +1,27 @@
+// Package output describes the documents JSON output writes as a JSON
+// Schema, derived from the structs they are marshalled from.
+package output
+
+import (
+	"reflect"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Schema returns the JSON Schema of JSON output. A document is one of a
+// Document, the no-match document {"error": ..., "results": []}, or the
+// MultiDocument printed with -multi.
+func Schema() map[string]any {
+	noMatch := models.JSONSchema(reflect.TypeFor[noMatches]())
+	noMatch["properties"].(map[string]any)["results"].(map[string]any)["maxItems"] = 0
+
+	return map[string]any{
+		"$schema": models.JSONSchemaDraft,
+		"title":   "embeddingsearch -json output",
+		"oneOf": []any{
+			models.JSONSchema(reflect.TypeFor[Document]()),
+			noMatch,
+			models.JSONSchema(reflect.TypeFor[MultiDocument]()),
+		},
+	}
+}
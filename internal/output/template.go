@@ -0,0 +1,47 @@
+// Package output renders results through a user-supplied text/template, for
+// scripts that need their own format.
+package output
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// TemplateData is what a Template is executed with. Each result's query
+// renders with {{.EQLQuery.String}}.
+type TemplateData struct {
+	Results         []models.SearchResult
+	UnmatchedTokens []string
+	AppliedSynonyms []search.Synonym
+}
+
+// Template executes a text/template once for the whole result set
+type Template struct {
+	tmpl *template.Template
+}
+
+// NewTemplate parses text as a text/template
+func NewTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Format implements Formatter
+func (t *Template) Format(w io.Writer, results []models.SearchResult, opts Options) error {
+	data := TemplateData{
+		Results:         shown(results, opts),
+		UnmatchedTokens: opts.UnmatchedTokens,
+		AppliedSynonyms: opts.AppliedSynonyms,
+	}
+	if err := t.tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute output template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Package output renders results as human-readable text: the top match in
+// detail followed by the other candidates.
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Text prints the top match with its description and fields, then the
+// other possible matches
+type Text struct{}
+
+// Format implements Formatter
+func (Text) Format(w io.Writer, results []models.SearchResult, opts Options) error {
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(w, "No matches found")
+		return err
+	}
+
+	colors := palette{enabled: opts.Color}
+	var b strings.Builder
+	results = shown(results, opts)
+
+	// Display top match
+	top := results[0]
+	fmt.Fprintf(&b, "%s (score: %s):\n%s\n", colors.heading("Top match"), colors.score(fmt.Sprintf("%.2f", top.Score)), colors.query(&top.EQLQuery))
+
+	for _, nq := range top.Comparison {
+		fmt.Fprintf(&b, "  %s: %s\n", nq.Node, colors.query(&nq.EQLQuery))
+	}
+	if top.FleetWide {
+		b.WriteString("Scope: all nodes (no node filter)\n")
+	}
+
+	if top.Description != "" {
+		fmt.Fprintf(&b, "\nDescription: %s\n", top.Description)
+	}
+	if top.ReferenceText != "" {
+		fmt.Fprintf(&b, "Reference text: %s\n", top.ReferenceText)
+	}
+	if len(top.AvailableFields) > 0 {
+		fmt.Fprintf(&b, "Available fields: %s\n", strings.Join(top.AvailableFields, ", "))
+	}
+
+	// Show other matches
+	if len(results) > 1 {
+		fmt.Fprintf(&b, "\n%s\n", colors.heading("Other possible matches:"))
+		for i, other := range results[1:] {
+			fmt.Fprintf(&b, "%d. %s (score: %s)\n", i+1, colors.query(&other.EQLQuery), colors.score(fmt.Sprintf("%.2f", other.Score)))
+			if other.Description != "" {
+				fmt.Fprintf(&b, "   Description: %s\n", other.Description)
+			}
+			if len(other.AvailableFields) > 0 {
+				fmt.Fprintf(&b, "   Available fields: %s\n", strings.Join(other.AvailableFields, ", "))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,162 @@
+// Package output renders results as YAML by re-encoding the JSON document,
+// so both formats carry the same fields without a YAML dependency.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// YAML prints the same document as JSON in block-style YAML
+type YAML struct{}
+
+// Format implements Formatter
+func (YAML) Format(w io.Writer, results []models.SearchResult, opts Options) error {
+	data, err := marshalDocument(results, opts)
+	if err != nil {
+		return err
+	}
+	text, err := jsonToYAML(data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, text)
+	return err
+}
+
+// yamlNode is a decoded JSON value with object keys in document order
+type yamlNode struct {
+	scalar   string // YAML text of a scalar; unset for objects and arrays
+	isObject bool
+	isArray  bool
+	keys     []string
+	children []*yamlNode
+}
+
+// jsonToYAML converts a JSON document to YAML, keeping the key order.
+// Strings are double-quoted, so no value can be mistaken for another type.
+func jsonToYAML(data []byte) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root, err := readNode(dec)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert JSON to YAML: %w", err)
+	}
+
+	var b strings.Builder
+	switch {
+	case root.isObject && len(root.keys) > 0:
+		writeMapping(&b, root, 0, false)
+	case root.isArray && len(root.children) > 0:
+		writeSequence(&b, root, 0)
+	default:
+		b.WriteString(inline(root) + "\n")
+	}
+	return b.String(), nil
+}
+
+func readNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yamlNode{isObject: t == '{', isArray: t == '['}
+		for dec.More() {
+			if node.isObject {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			child, err := readNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, child)
+		}
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yamlNode{scalar: strconv.Quote(t)}, nil
+	case json.Number:
+		return &yamlNode{scalar: t.String()}, nil
+	case bool:
+		return &yamlNode{scalar: strconv.FormatBool(t)}, nil
+	default:
+		return &yamlNode{scalar: "null"}, nil
+	}
+}
+
+// inline renders scalars and empty collections, which fit on one line
+func inline(node *yamlNode) string {
+	switch {
+	case node.isObject:
+		return "{}"
+	case node.isArray:
+		return "[]"
+	default:
+		return node.scalar
+	}
+}
+
+func isBlock(node *yamlNode) bool {
+	return (node.isObject || node.isArray) && len(node.children) > 0
+}
+
+var plainKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// writeMapping writes an object's entries at indent. With inlineFirst the
+// first entry continues a "- " sequence marker already written.
+func writeMapping(b *strings.Builder, node *yamlNode, indent int, inlineFirst bool) {
+	for i, key := range node.keys {
+		if i > 0 || !inlineFirst {
+			b.WriteString(strings.Repeat(" ", indent))
+		}
+		if !plainKeyPattern.MatchString(key) {
+			key = strconv.Quote(key)
+		}
+
+		child := node.children[i]
+		switch {
+		case !isBlock(child):
+			fmt.Fprintf(b, "%s: %s\n", key, inline(child))
+		case child.isObject:
+			fmt.Fprintf(b, "%s:\n", key)
+			writeMapping(b, child, indent+2, false)
+		default:
+			fmt.Fprintf(b, "%s:\n", key)
+			writeSequence(b, child, indent+2)
+		}
+	}
+}
+
+// writeSequence writes an array's items at indent
+func writeSequence(b *strings.Builder, node *yamlNode, indent int) {
+	for _, child := range node.children {
+		b.WriteString(strings.Repeat(" ", indent) + "-")
+		switch {
+		case !isBlock(child):
+			b.WriteString(" " + inline(child) + "\n")
+		case child.isObject:
+			b.WriteString(" ")
+			writeMapping(b, child, indent+2, true)
+		default:
+			b.WriteString("\n")
+			writeSequence(b, child, indent+2)
+		}
+	}
+}
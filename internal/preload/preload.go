@@ -0,0 +1,66 @@
+// Package preload downloads, indexes and caches embeddings ahead of time so
+// the first real query is fast.
+package preload
+
+import (
+	"context"
+	"time"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/cache"
+	"github.com/eda-labs/eda-embeddingsearch/internal/download"
+	"github.com/eda-labs/eda-embeddingsearch/internal/embedding"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Timing records how long each preload step took for one platform
+type Timing struct {
+	Platform models.EmbeddingType
+	Path     string // the embeddings file
+	Entries  int
+	Cached   bool // a valid binary cache existed before loading
+	Download time.Duration
+	Load     time.Duration
+}
+
+// Preloader prepares the embeddings kept in one directory
+type Preloader struct {
+	downloader   *download.Downloader
+	cacheManager cache.CacheManager
+	loader       *embedding.Loader
+}
+
+// New returns a Preloader for embedDir; an empty embedDir selects
+// download.DefaultEmbeddingsDir
+func New(embedDir string) *Preloader {
+	cacheManager := cache.NewCacheManager()
+	return &Preloader{
+		downloader:   download.NewDownloaderWithDir(embedDir),
+		cacheManager: cacheManager,
+		loader:       embedding.NewLoader(cacheManager),
+	}
+}
+
+// Platform prepares the embeddings of one platform: it downloads the release
+// when missing, then loads it, which builds the inverted index and writes
+// the binary cache unless a valid one already exists
+func (p *Preloader) Platform(ctx context.Context, platform models.EmbeddingType, version string) (Timing, error) {
+	timing := Timing{Platform: platform}
+
+	start := time.Now()
+	path, err := p.downloader.EnsureEmbeddingsContext(ctx, platform, version)
+	if err != nil {
+		return timing, err
+	}
+	timing.Path = path
+	timing.Download = time.Since(start)
+
+	timing.Cached = p.cacheManager.IsBinaryCacheValid(path, p.cacheManager.GetBinaryCachePath(path))
+	start = time.Now()
+	db, err := p.loader.Load(path)
+	if err != nil {
+		return timing, err
+	}
+	timing.Load = time.Since(start)
+	timing.Entries = len(db.Table)
+	return timing, nil
+}
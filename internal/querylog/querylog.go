@@ -0,0 +1,67 @@
+// Package querylog records an audit trail of queries and their top matches as
+// JSON lines, for long-running front ends and the CLI. Logging is off unless
+// a Logger is created.
+package querylog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Logger writes one JSON record per query. A nil *Logger discards
+// everything, so callers need not check whether logging is enabled.
+type Logger struct {
+	logger *slog.Logger
+	closer io.Closer
+}
+
+// New logs to w
+func New(w io.Writer) *Logger {
+	return &Logger{logger: slog.New(slog.NewJSONHandler(w, nil))}
+}
+
+// Open logs to path, rotating it once it would exceed maxBytes and keeping
+// backups older files
+func Open(path string, maxBytes int64, backups int) (*Logger, error) {
+	file, err := OpenRotatingFile(path, maxBytes, backups)
+	if err != nil {
+		return nil, err
+	}
+	l := New(file)
+	l.closer = file
+	return l, nil
+}
+
+// Log records a query with the platform it was run against and its top
+// match: table, score and EQL. The record's time is the time of the call.
+func (l *Logger) Log(query string, platform models.EmbeddingType, results []models.SearchResult) {
+	if l == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("query", query),
+		slog.String("platform", platform.String()),
+		slog.Int("results", len(results)),
+	}
+	if len(results) > 0 {
+		top := results[0]
+		attrs = append(attrs,
+			slog.String("table", top.Key),
+			slog.Float64("score", top.Score),
+			slog.String("eql", top.EQLQuery.String()),
+		)
+	}
+	l.logger.LogAttrs(context.Background(), slog.LevelInfo, "query", attrs...)
+}
+
+// Close closes the log file opened by Open
+func (l *Logger) Close() error {
+	if l == nil || l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
@@ -0,0 +1,127 @@
+// Package querylog rotates the query log file by size, keeping a fixed number
+// of older files next to it.
+package querylog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an io.Writer appending to a file that is renamed to
+// path.1 once it would grow past maxBytes; path.1 becomes path.2 and so on,
+// and the oldest backup beyond the limit is removed
+type RotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	backups  int
+	file     *os.File
+	size     int64
+}
+
+// OpenRotatingFile opens path for appending, creating it if needed. A
+// maxBytes of zero or less never rotates.
+func OpenRotatingFile(path string, maxBytes int64, backups int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxBytes: maxBytes, backups: max(backups, 0)}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open query log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat query log: %w", err)
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when p would not fit. A single write is
+// never split, so a record larger than maxBytes gets a file of its own. A
+// failed rotation is reported on stderr and p is appended to the current
+// file instead, since the logger discards write errors.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			if r.file == nil {
+				return 0, err
+			}
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the backups up by one and starts an empty file. The file at
+// path is reopened even when shifting fails, so writing can go on.
+func (r *RotatingFile) rotate() error {
+	err := r.file.Close()
+	r.file = nil
+	if err != nil {
+		err = fmt.Errorf("failed to close query log: %w", err)
+	} else {
+		err = r.shiftBackups()
+	}
+	if openErr := r.open(); openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	return err
+}
+
+// shiftBackups renames path to path.1, path.1 to path.2 and so on, removing
+// the oldest backup; with no backups it removes path
+func (r *RotatingFile) shiftBackups() error {
+	oldest := r.path
+	if r.backups > 0 {
+		oldest = r.backupPath(r.backups)
+	}
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate query log: %w", err)
+	}
+	if r.backups == 0 {
+		return nil
+	}
+
+	for i := r.backups - 1; i >= 1; i-- {
+		if err := os.Rename(r.backupPath(i), r.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate query log: %w", err)
+		}
+	}
+	if err := os.Rename(r.path, r.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate query log: %w", err)
+	}
+	return nil
+}
+
+func (r *RotatingFile) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+// Close closes the current file
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
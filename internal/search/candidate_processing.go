@@ -3,43 +3,82 @@
 package search
 
 import (
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
 )
 
 type scoredCandidate struct {
 	key   string
 	score float64
+	db    *models.EmbeddingDB
 }
 
-func (e *Engine) scoreCandidates(candidateKeys map[string]int, query string, words []string) []scoredCandidate {
+func (e *Engine) scoreCandidates(db *models.EmbeddingDB, candidateKeys map[string]int, query string, words []string) []scoredCandidate {
 	candidates := make([]scoredCandidate, 0, len(candidateKeys))
 
 	for key, matchCount := range candidateKeys {
-		score := e.calculateCandidateScore(key, matchCount, query, words)
-		threshold := getScoreThreshold(key)
+		score := e.calculateCandidateScore(db.Table[key], key, matchCount, query, words)
+		threshold := getScoreThreshold(e.config, key)
 
 		if score > threshold {
 			candidates = append(candidates, scoredCandidate{
 				key:   key,
 				score: score,
+				db:    db,
 			})
 		}
 	}
 
-	// Sort candidates by score
+	return candidates
+}
+
+// sortCandidates orders candidates by descending score. Ties are broken by
+// preferring the shorter table path and then lexical order, so the ranking
+// does not depend on map iteration order.
+func sortCandidates(candidates []scoredCandidate) {
 	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].score > candidates[j].score
+		return candidateLess(&candidates[i], &candidates[j])
 	})
+}
 
-	return candidates
+func candidateLess(a, b *scoredCandidate) bool {
+	if a.score != b.score {
+		return a.score > b.score
+	}
+	if len(a.key) != len(b.key) {
+		return len(a.key) < len(b.key)
+	}
+	return a.key < b.key
 }
 
-func (e *Engine) calculateCandidateScore(key string, matchCount int, query string, words []string) float64 {
-	entry := e.db.Table[key]
+// dedupCandidates drops repeated table paths, keeping the best scoring
+// instance; on equal scores the database added to the engine first wins.
+// The order of first appearance is kept.
+func (e *Engine) dedupCandidates(candidates []scoredCandidate) []scoredCandidate {
+	if e.keepDuplicateKeys {
+		return candidates
+	}
 
+	best := make(map[string]int, len(candidates))
+	unique := candidates[:0]
+	for _, cand := range candidates {
+		i, seen := best[cand.key]
+		switch {
+		case !seen:
+			best[cand.key] = len(unique)
+			unique = append(unique, cand)
+		case cand.score > unique[i].score:
+			unique[i] = cand
+		}
+	}
+	return unique
+}
+
+func (e *Engine) calculateCandidateScore(entry models.EmbeddingEntry, key string, matchCount int, query string, words []string) float64 {
 	// Base score from inverted index matches
 	baseScore := float64(matchCount) * constants.BaseIndexMatchScore
 
@@ -64,9 +103,38 @@ func hasAllWords(key string, words []string) bool {
 	return true
 }
 
-func getScoreThreshold(key string) float64 {
+// getScoreThreshold returns the score a candidate must beat to be kept
+func getScoreThreshold(config *ScoringConfig, key string) float64 {
 	if strings.Contains(key, ".sros.") {
-		return constants.SROSScoreThreshold
+		return config.SROSCandidateThreshold
+	}
+	return config.CandidateThreshold
+}
+
+// ScoreEntry scores a single table path against a query the same way
+// IndexedSearch ranks candidates. The boolean is false when no database
+// holds the key. It is intended for benchmarks and ranking analysis.
+func (e *Engine) ScoreEntry(key, query string) (float64, bool) {
+	e.ensureIndexes()
+	query = e.scoringQuery(e.rewriteQuery(query))
+	words := queryWords(query)
+	for _, db := range e.dbs {
+		entry, ok := db.Table[key]
+		if !ok {
+			continue
+		}
+		return e.calculateCandidateScore(entry, key, indexMatchCount(db, key, words), query, words), true
+	}
+	return 0, false
+}
+
+// indexMatchCount counts how many query words list key in the inverted index
+func indexMatchCount(db *models.EmbeddingDB, key string, words []string) int {
+	count := 0
+	for _, word := range words {
+		if slices.Contains(db.InvertedIndex[word], key) {
+			count++
+		}
 	}
-	return constants.DefaultScoreThreshold
+	return count
 }
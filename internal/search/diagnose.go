@@ -0,0 +1,146 @@
+// Package search explains how a single table scores for a query, to answer
+// why an expected table did not show up.
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Diagnose reports how expectedKey fares for query: whether any query word
+// reaches it through the inverted index, whether the path filter drops it,
+// its score broken down by component, whether it clears the score
+// thresholds and where it ranks in the results.
+func (e *Engine) Diagnose(query, expectedKey string) string {
+	e.ensureIndexes()
+	var b strings.Builder
+	rewritten := e.rewriteQuery(query)
+	scoring := e.scoringQuery(rewritten)
+	words := queryWords(scoring)
+
+	fmt.Fprintf(&b, "Query words: %s\n", strings.Join(words, ", "))
+
+	db, entry, ok := e.findEntry(expectedKey)
+	if !ok {
+		fmt.Fprintf(&b, "%s is not in any database\n", expectedKey)
+		return b.String()
+	}
+	if db.Name != "" {
+		fmt.Fprintf(&b, "Database: %s\n", db.Name)
+	}
+
+	e.diagnoseCandidate(&b, db, expectedKey, scoring, words)
+	score := e.diagnoseScore(&b, db, entry, expectedKey, scoring, words)
+	e.diagnoseRank(&b, rewritten, expectedKey, score)
+	return b.String()
+}
+
+// findEntry returns the first database holding key
+func (e *Engine) findEntry(key string) (*models.EmbeddingDB, models.EmbeddingEntry, bool) {
+	for _, db := range e.dbs {
+		if entry, ok := db.Table[key]; ok {
+			return db, entry, true
+		}
+	}
+	return nil, models.EmbeddingEntry{}, false
+}
+
+// diagnoseCandidate reports whether the index makes key a candidate
+func (e *Engine) diagnoseCandidate(b *strings.Builder, db *models.EmbeddingDB, key, scoring string, words []string) {
+	var hits []string
+	for _, word := range words {
+		if slices.Contains(db.InvertedIndex[word], key) {
+			hits = append(hits, word)
+		}
+	}
+
+	candidates := getCandidateKeys(db, words, scoring, detectSROSDatabase(db), e.interfaceCandidateLimit)
+	_, candidate := candidates[key]
+	descriptionHits := descriptionCandidateWords(db, key, words)
+	switch {
+	case len(hits) > 0:
+		fmt.Fprintf(b, "Indexed candidate: yes, via %s\n", strings.Join(hits, ", "))
+	case candidate && len(descriptionHits) > 0:
+		fmt.Fprintf(b, "Indexed candidate: yes, via description match on %s\n", strings.Join(descriptionHits, ", "))
+	case candidate:
+		fmt.Fprintln(b, "Indexed candidate: yes, added as an interface table")
+	default:
+		fmt.Fprintln(b, "Indexed candidate: no, no query word lists this table in the inverted index")
+	}
+
+	if !e.pathFilter.IsEmpty() && !e.pathFilter.Match(key) {
+		fmt.Fprintln(b, "Path filter: rejected")
+	}
+}
+
+// descriptionCandidateWords returns the query words through which
+// addDescriptionCandidates adds key, or nil when the description index is
+// not consulted because a query word already names a path segment
+func descriptionCandidateWords(db *models.EmbeddingDB, key string, words []string) []string {
+	indexed := make(map[string]int)
+	addIndexedCandidates(db, words, indexed)
+	if matchesAnyPath(indexed, words) {
+		return nil
+	}
+	var hits []string
+	for _, word := range words {
+		if slices.Contains(db.DescriptionIndex[word], key) {
+			hits = append(hits, word)
+		}
+	}
+	return hits
+}
+
+// diagnoseScore prints the score breakdown and threshold checks and
+// returns the total
+func (e *Engine) diagnoseScore(b *strings.Builder, db *models.EmbeddingDB, entry models.EmbeddingEntry, key, scoring string, words []string) float64 {
+	matchCount := indexMatchCount(db, key, words)
+	components := []scoreComponent{
+		{"index matches", float64(matchCount) * constants.BaseIndexMatchScore},
+		{"all words in path", e.conditionalScore(hasAllWords(key, words), float64(len(words))*constants.AllWordsMatchBonus)},
+	}
+	components = append(components, e.scoreEntryComponents(key, entry, scoring, words)...)
+	score := e.calculateCandidateScore(entry, key, matchCount, scoring, words)
+
+	fmt.Fprintf(b, "Score: %.2f\n", score)
+	for _, c := range components {
+		if c.score != 0 {
+			fmt.Fprintf(b, "  %-18s %+.2f\n", c.name+":", c.score)
+		}
+	}
+
+	threshold := getScoreThreshold(e.config, key)
+	fmt.Fprintf(b, "Candidate threshold: %.2f (%s)\n", threshold, passFail(score > threshold))
+	if e.minScore != 0 {
+		fmt.Fprintf(b, "Minimum score: %.2f (%s)\n", e.minScore, passFail(score >= e.minScore))
+	}
+	return score
+}
+
+// diagnoseRank reports where key ends up in the search results
+func (e *Engine) diagnoseRank(b *strings.Builder, rewritten, key string, score float64) {
+	results := e.search(rewritten, &SearchStats{})
+	for i, result := range results {
+		if result.Key == key {
+			fmt.Fprintf(b, "Rank: %d of %d\n", i+1, len(results))
+			return
+		}
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(b, "Rank: not returned; the query has no results")
+		return
+	}
+	last := results[len(results)-1]
+	fmt.Fprintf(b, "Rank: not returned; the last of %d results scored %.2f against %.2f here\n", len(results), last.Score, score)
+}
+
+func passFail(passed bool) string {
+	if passed {
+		return "passed"
+	}
+	return "failed"
+}
@@ -3,19 +3,158 @@
 package search
 
 import (
+	"sync"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
 	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
 )
 
 // Engine represents the search engine
 type Engine struct {
-	db     *models.EmbeddingDB
-	config *ScoringConfig
+	dbs        []*models.EmbeddingDB
+	config     *ScoringConfig
+	pathFilter PathFilter
+	rewriter   QueryRewriter
+	maxFields  int
+	maxResults int // 0 returns every scored result
+	minScore   float64
+	cache      *queryCache // nil unless WithQueryCache is used
+
+	includeReferenceText bool
+	keepDuplicateKeys    bool // list a table path once per database holding it
+
+	interfaceCandidateLimit int // 0 adds every interface candidate
+
+	indexOnce sync.Once  // builds missing database indexes on first use
+	terms     [][]string // sorted InvertedIndex terms of each database, for prefix lookups
+}
+
+// Option configures optional Engine behavior
+type Option func(*Engine)
+
+// WithAdditionalDBs adds more databases to search alongside the primary one.
+// When the same table path exists in more than one database, only its best
+// scoring instance is returned, the earliest database winning ties; see
+// WithDuplicateKeys.
+func WithAdditionalDBs(dbs ...*models.EmbeddingDB) Option {
+	return func(e *Engine) {
+		for _, db := range dbs {
+			if db != nil {
+				e.dbs = append(e.dbs, db)
+			}
+		}
+	}
+}
+
+// WithScoringConfig replaces the default scoring weights and penalties
+func WithScoringConfig(config *ScoringConfig) Option {
+	return func(e *Engine) {
+		if config != nil {
+			e.config = config
+		}
+	}
+}
+
+// WithPathFilter limits candidates to table paths accepted by the filter
+func WithPathFilter(filter PathFilter) Option {
+	return func(e *Engine) {
+		e.pathFilter = filter
+	}
+}
+
+// WithMaxFields caps the fields projected in each result's EQL query. Zero
+// means no cap; negative values are ignored.
+func WithMaxFields(maxFields int) Option {
+	return func(e *Engine) {
+		if maxFields >= 0 {
+			e.maxFields = maxFields
+		}
+	}
+}
+
+// WithMaxResults caps how many results a search returns, MaxSearchResults
+// by default. Zero returns every scored result, for tooling that analyzes the
+// whole ranking.
+func WithMaxResults(maxResults int) Option {
+	return func(e *Engine) {
+		if maxResults >= 0 {
+			e.maxResults = maxResults
+		}
+	}
+}
+
+// WithMinScore drops results whose raw score is below minScore
+func WithMinScore(minScore float64) Option {
+	return func(e *Engine) {
+		e.minScore = minScore
+	}
+}
+
+// WithInterfaceCandidateLimit caps the candidate set when SR OS interface
+// queries pull in every interface-related table. Interface tables are only
+// added until the set holds limit keys, so the flood is skipped entirely
+// when the index already found that many. Zero, the default, removes the
+// cap.
+func WithInterfaceCandidateLimit(limit int) Option {
+	return func(e *Engine) {
+		if limit >= 0 {
+			e.interfaceCandidateLimit = limit
+		}
+	}
+}
+
+// WithReferenceText copies each matched entry's ReferenceText into its
+// result, showing which natural-language text the table was indexed from
+func WithReferenceText() Option {
+	return func(e *Engine) {
+		e.includeReferenceText = true
+	}
+}
+
+// WithDuplicateKeys returns a table path found in several databases once per
+// database instead of only its best scoring instance, e.g. to compare how
+// two releases rank the same table
+func WithDuplicateKeys() Option {
+	return func(e *Engine) {
+		e.keepDuplicateKeys = true
+	}
+}
+
+// QueryRewriter transforms a raw query before any tokenization or extraction,
+// e.g. to expand site-specific aliases or strip ticket numbers
+type QueryRewriter func(string) string
+
+// WithQueryRewriter applies rewriter to every query passed to IndexedSearch
+// and ScoreEntry
+func WithQueryRewriter(rewriter QueryRewriter) Option {
+	return func(e *Engine) {
+		e.rewriter = rewriter
+	}
 }
 
 // NewEngine creates a new search engine
-func NewEngine(db *models.EmbeddingDB) *Engine {
-	return &Engine{
-		db:     db,
-		config: DefaultScoringConfig(),
+func NewEngine(db *models.EmbeddingDB, opts ...Option) *Engine {
+	e := &Engine{
+		dbs:        []*models.EmbeddingDB{db},
+		config:     DefaultScoringConfig(),
+		maxFields:  constants.MaxExtractedFields,
+		maxResults: constants.MaxSearchResults,
+	}
+	for _, opt := range opts {
+		opt(e)
 	}
+	return e
+}
+
+// Close releases what the engine holds so a long-running host can drop it
+// without leaking memory: cached results are cleared and the databases are
+// released, so later searches find nothing. The engine starts no background
+// goroutines; index builds run inside the first search and are finished
+// once it returns. Call Close after in-flight searches have returned. It is
+// safe to call more than once and always returns nil.
+func (e *Engine) Close() error {
+	e.ClearQueryCache()
+	e.cache = nil
+	e.dbs = nil
+	return nil
 }
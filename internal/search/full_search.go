@@ -0,0 +1,93 @@
+// Package search implements the full scan, which scores table paths by
+// scanning their text instead of consulting the inverted index.
+package search
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/eql"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// FullSearch scores every table in every database that mentions a query
+// word, bypassing the inverted index. It is slower than IndexedSearch and meant for comparing recall
+// between the two paths. The query cache is not used.
+func (e *Engine) FullSearch(query string) []models.SearchResult {
+	results, _ := e.FullSearchWithStats(query)
+	return results
+}
+
+// FullSearchWithStats runs FullSearch and reports the work it did
+func (e *Engine) FullSearchWithStats(query string) ([]models.SearchResult, SearchStats) {
+	e.ensureIndexes()
+	start := time.Now()
+	stats := SearchStats{Path: PathFull}
+
+	query = e.rewriteQuery(query)
+	scoring := e.scoringQuery(query)
+	words := queryWords(scoring)
+
+	var results []models.SearchResult
+	if len(words) > 0 {
+		scoringStart := time.Now()
+		candidates := e.scoreAllKeys(scoring, words, &stats)
+		stats.Scored = len(candidates)
+		stats.ScoringTime = time.Since(scoringStart)
+		results = e.buildResults(candidates, query)
+	}
+
+	stats.Results = len(results)
+	stats.Total = time.Since(start)
+	return results, stats
+}
+
+// scoreAllKeys scores every table path that mentions a query word anywhere
+// in its key or text, including text past the index's token limits. The
+// index is only used to count how many query words list each key. Words
+// naming a sort field ("by name") do not make a table a candidate by
+// themselves.
+func (e *Engine) scoreAllKeys(query string, words []string, stats *SearchStats) []scoredCandidate {
+	topics := topicWords(query, words)
+	var candidates []scoredCandidate
+	for _, db := range e.dbs {
+		candidateKeys := make(map[string]int)
+		for key, entry := range db.Table {
+			if mentionsAnyWord(key+" "+entry.ReferenceText+" "+entry.Text, topics) {
+				candidateKeys[key] = indexMatchCount(db, key, words)
+			}
+		}
+		e.applyPathFilter(candidateKeys)
+		stats.CandidateKeys += len(candidateKeys)
+
+		candidates = append(candidates, e.scoreCandidates(db, candidateKeys, query, words)...)
+	}
+	return candidates
+}
+
+// topicWords drops the words of a "by <field>" phrase from words, keeping
+// all of them when nothing else would remain
+func topicWords(query string, words []string) []string {
+	sortWords := eql.SortPhraseWords(query)
+	var topics []string
+	for _, word := range words {
+		if !slices.Contains(sortWords, word) {
+			topics = append(topics, word)
+		}
+	}
+	if len(topics) == 0 {
+		return words
+	}
+	return topics
+}
+
+func mentionsAnyWord(text string, words []string) bool {
+	text = strings.ToLower(text)
+	for _, word := range words {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Package search builds the inverted and description indexes the indexed
+// search looks words up in, with the same tokenizer queries go through.
+package search
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// indexBuildMu serializes index builds, so engines sharing a database never
+// build its indexes concurrently
+var indexBuildMu sync.Mutex
+
+// ensureIndexes builds the missing indexes of the engine's databases the
+// first time it is called, e.g. for a database decoded from an old cache or
+// built in code without embedding.FromTable, and sorts each database's index
+// terms for prefix lookups
+func (e *Engine) ensureIndexes() {
+	e.indexOnce.Do(func() {
+		indexBuildMu.Lock()
+		defer indexBuildMu.Unlock()
+		e.terms = make([][]string, len(e.dbs))
+		for i, db := range e.dbs {
+			BuildIndexes(db, min(constants.MaxWorkers, runtime.NumCPU()))
+			e.terms[i] = sortedTerms(db.InvertedIndex)
+		}
+	})
+}
+
+// sortedTerms returns the words of an index in order
+func sortedTerms(index map[string][]string) []string {
+	terms := make([]string, 0, len(index))
+	for term := range index {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+// BuildIndexes builds the word and description indexes of db by splitting
+// the table into contiguous runs of sorted keys, indexing each run on its
+// own goroutine and concatenating the partial indexes in order. Every key
+// list is therefore sorted and free of repeats, and the indexes are the same
+// for any number of workers. An index that is already built is kept.
+func BuildIndexes(db *models.EmbeddingDB, workers int) {
+	if len(db.InvertedIndex) > 0 && len(db.DescriptionIndex) > 0 {
+		// Already built
+		return
+	}
+
+	keys := make([]string, 0, len(db.Table))
+	for key := range db.Table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	workers = max(1, min(workers, len(keys)))
+	partials := make([]shardIndexes, workers)
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		shard := keys[w*len(keys)/workers : (w+1)*len(keys)/workers]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			partials[w] = indexShard(db.Table, shard)
+		}()
+	}
+	wg.Wait()
+
+	if len(db.InvertedIndex) == 0 {
+		db.InvertedIndex = make(map[string][]string)
+		for _, partial := range partials {
+			mergeIndex(db.InvertedIndex, partial.words)
+		}
+	}
+	if len(db.DescriptionIndex) == 0 {
+		db.DescriptionIndex = make(map[string][]string)
+		for _, partial := range partials {
+			mergeIndex(db.DescriptionIndex, partial.descriptions)
+		}
+	}
+}
+
+// shardIndexes holds the partial indexes built from one run of keys
+type shardIndexes struct {
+	words        map[string][]string
+	descriptions map[string][]string
+}
+
+func mergeIndex(index, partial map[string][]string) {
+	for word, keys := range partial {
+		index[word] = append(index[word], keys...)
+	}
+}
+
+// indexShard indexes the given keys, listing each key at most once per word
+func indexShard(table map[string]models.EmbeddingEntry, keys []string) shardIndexes {
+	index := shardIndexes{words: make(map[string][]string), descriptions: make(map[string][]string)}
+	for _, key := range keys {
+		entry := table[key]
+		for token := range entryTokens(key, entry) {
+			index.words[token] = append(index.words[token], key)
+		}
+		for token := range descriptionTokens(entry) {
+			index.descriptions[token] = append(index.descriptions[token], key)
+		}
+	}
+	return index
+}
+
+// entryTokens returns the distinct words an entry is indexed under
+func entryTokens(key string, entry models.EmbeddingEntry) map[string]bool {
+	tokens := make(map[string]bool)
+
+	// Index key tokens
+	for _, token := range Tokenize(key) {
+		tokens[token] = true
+	}
+
+	// Index reference text tokens (limited to avoid memory bloat)
+	for i, token := range Tokenize(entry.ReferenceText) {
+		if i > 50 { // Limit to first 50 tokens
+			break
+		}
+		tokens[token] = true
+	}
+
+	// Also index Text field for better matching
+	for i, token := range Tokenize(entry.Text) {
+		if i > 30 { // Limit tokens from Text field
+			break
+		}
+		tokens[token] = true
+	}
+
+	return tokens
+}
+
+// descriptionTokens returns the distinct words of an entry's description
+// and their synonym-expanded forms, without the token limits of
+// entryTokens, so long descriptions can still be found by their later words
+func descriptionTokens(entry models.EmbeddingEntry) map[string]bool {
+	words := Tokenize(entry.Description())
+	tokens := make(map[string]bool)
+	for _, token := range append(words, ExpandSynonyms(words)...) {
+		tokens[token] = true
+	}
+	return tokens
+}
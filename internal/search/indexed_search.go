@@ -3,33 +3,133 @@
 package search
 
 import (
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
-	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
 	"github.com/eda-labs/eda-embeddingsearch/internal/download"
 	"github.com/eda-labs/eda-embeddingsearch/internal/eql"
 	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
 )
 
 // IndexedSearch performs fast search using the prebuilt inverted index.
+// When the engine holds several databases, candidates from all of them are
+// scored together and each result records the database it came from.
+// Queries without any searchable words return no results. With
+// WithQueryCache, repeated queries are served from the cache.
 func (e *Engine) IndexedSearch(query string) []models.SearchResult {
-	words := ExpandSynonyms(Tokenize(query))
+	results, _ := e.SearchWithStats(query)
+	return results
+}
+
+// SearchWithStats runs IndexedSearch and reports how the results were found
+func (e *Engine) SearchWithStats(query string) ([]models.SearchResult, SearchStats) {
+	e.ensureIndexes()
+	start := time.Now()
+	results, stats := e.cachedSearch(e.rewriteQuery(query))
+	stats.Results = len(results)
+	stats.Total = time.Since(start)
+	return results, stats
+}
 
-	isSROSDB := e.detectSROSDatabase()
-	candidateKeys := e.getCandidateKeys(words, query, isSROSDB)
+// cachedSearch serves a rewritten query from the query cache when enabled
+func (e *Engine) cachedSearch(query string) ([]models.SearchResult, SearchStats) {
+	stats := SearchStats{Path: PathIndexed}
+	if e.cache == nil {
+		results := e.search(query, &stats)
+		return results, stats
+	}
 
-	// If no candidates from index, return no results
-	if len(candidateKeys) == 0 {
+	key := queryCacheKey(query)
+	fingerprint := dbFingerprint(e.dbs)
+	if results, cached, ok := e.cache.get(key, fingerprint); ok {
+		cached.Cached = true
+		return results, cached
+	}
+	results := e.search(query, &stats)
+	e.cache.put(key, results, stats)
+	return results, stats
+}
+
+// search runs the uncached search for an already rewritten query. Scoring
+// ignores conversational lead-ins; EQL is still built from the full query.
+func (e *Engine) search(query string, stats *SearchStats) []models.SearchResult {
+	scoring := e.scoringQuery(query)
+	words := queryWords(scoring)
+	if len(words) == 0 {
 		return nil
 	}
 
-	// Score candidates and generate results
-	candidates := e.scoreCandidates(candidateKeys, query, words)
+	scoringStart := time.Now()
+	var candidates []scoredCandidate
+	for _, db := range e.dbs {
+		candidateKeys := getCandidateKeys(db, words, scoring, detectSROSDatabase(db), e.interfaceCandidateLimit)
+		e.applyPathFilter(candidateKeys)
+		stats.CandidateKeys += len(candidateKeys)
+
+		// If no candidates from index, skip this database
+		if len(candidateKeys) == 0 {
+			continue
+		}
+
+		candidates = append(candidates, e.scoreCandidates(db, candidateKeys, scoring, words)...)
+	}
+
+	// No query word is in the index at all; score every table instead
+	if stats.CandidateKeys == 0 {
+		stats.Path = PathFull
+		stats.Fallback = true
+		candidates = e.scoreAllKeys(scoring, words, stats)
+	}
+	stats.Scored = len(candidates)
+	stats.ScoringTime = time.Since(scoringStart)
+
+	return e.buildResults(candidates, query)
+}
+
+// buildResults deduplicates and ranks scored candidates and turns the best
+// of them into results
+func (e *Engine) buildResults(candidates []scoredCandidate, query string) []models.SearchResult {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Generate results from the best candidates across all databases
+	candidates = e.dedupCandidates(candidates)
+	sortCandidates(candidates)
+	candidates = e.dropWeakCandidates(candidates)
+	if len(candidates) == 0 {
+		return nil
+	}
 	return e.generateIndexedSearchResults(candidates, query)
 }
 
-func (e *Engine) detectSROSDatabase() bool {
-	for key := range e.db.Table {
+// dropWeakCandidates truncates sorted candidates at the first one scoring
+// below the engine's minimum score
+func (e *Engine) dropWeakCandidates(candidates []scoredCandidate) []scoredCandidate {
+	for i, cand := range candidates {
+		if cand.score < e.minScore {
+			return candidates[:i]
+		}
+	}
+	return candidates
+}
+
+// applyPathFilter drops candidates rejected by the engine's path filter
+func (e *Engine) applyPathFilter(candidateKeys map[string]int) {
+	if e.pathFilter.IsEmpty() {
+		return
+	}
+	for key := range candidateKeys {
+		if !e.pathFilter.Match(key) {
+			delete(candidateKeys, key)
+		}
+	}
+}
+
+func detectSROSDatabase(db *models.EmbeddingDB) bool {
+	for key := range db.Table {
 		if strings.Contains(key, ".sros.") {
 			return true
 		}
@@ -37,23 +137,29 @@ func (e *Engine) detectSROSDatabase() bool {
 	return false
 }
 
-func (e *Engine) getCandidateKeys(words []string, query string, isSROSDB bool) map[string]int {
+func getCandidateKeys(db *models.EmbeddingDB, words []string, query string, isSROSDB bool, interfaceLimit int) map[string]int {
 	candidateKeys := make(map[string]int)
 
 	// Use inverted index to get candidate keys
-	e.addIndexedCandidates(words, candidateKeys)
+	addIndexedCandidates(db, words, candidateKeys)
+
+	// Queries describing intent rather than naming path segments are
+	// matched against the table descriptions instead
+	if !matchesAnyPath(candidateKeys, words) {
+		addDescriptionCandidates(db, words, candidateKeys)
+	}
 
 	// For SROS database or queries, ensure we get interface-related entries
 	if shouldAddInterfaceCandidates(words, query, isSROSDB) {
-		e.addInterfaceCandidates(candidateKeys)
+		addInterfaceCandidates(db, candidateKeys, interfaceLimit)
 	}
 
 	return candidateKeys
 }
 
-func (e *Engine) addIndexedCandidates(words []string, candidateKeys map[string]int) {
+func addIndexedCandidates(db *models.EmbeddingDB, words []string, candidateKeys map[string]int) {
 	for _, word := range words {
-		if keys, exists := e.db.InvertedIndex[word]; exists {
+		if keys, exists := db.InvertedIndex[word]; exists {
 			for _, key := range keys {
 				candidateKeys[key]++
 			}
@@ -61,6 +167,39 @@ func (e *Engine) addIndexedCandidates(words []string, candidateKeys map[string]i
 	}
 }
 
+// addDescriptionCandidates adds the keys whose description contains a query
+// word, counting one match per word. Keys that are already candidates keep
+// their index match count.
+func addDescriptionCandidates(db *models.EmbeddingDB, words []string, candidateKeys map[string]int) {
+	found := make(map[string]int)
+	for _, word := range words {
+		for _, key := range db.DescriptionIndex[word] {
+			found[key]++
+		}
+	}
+	for key, count := range found {
+		if _, ok := candidateKeys[key]; !ok {
+			candidateKeys[key] = count
+		}
+	}
+}
+
+// matchesAnyPath reports whether a query word is a segment of any candidate
+// table path
+func matchesAnyPath(candidateKeys map[string]int, words []string) bool {
+	for key := range candidateKeys {
+		segments := strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+			return r == '.' || r == '-' || r == '_'
+		})
+		for _, segment := range segments {
+			if slices.Contains(words, segment) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func shouldAddInterfaceCandidates(words []string, query string, isSROSDB bool) bool {
 	if !isSROSDB && download.DetectPlatformFromQuery(query) != models.SROS {
 		return false
@@ -74,44 +213,137 @@ func shouldAddInterfaceCandidates(words []string, query string, isSROSDB bool) b
 	return false
 }
 
-func (e *Engine) addInterfaceCandidates(candidateKeys map[string]int) {
-	for indexWord, keys := range e.db.InvertedIndex {
+// addInterfaceCandidates adds every key indexed under a word containing
+// "interface". With a positive limit, candidates are only added until the
+// set holds limit keys, preferring keys listed under the most such words
+// and then shorter paths.
+func addInterfaceCandidates(db *models.EmbeddingDB, candidateKeys map[string]int, limit int) {
+	if limit > 0 && len(candidateKeys) >= limit {
+		return
+	}
+
+	flood := make(map[string]int)
+	for indexWord, keys := range db.InvertedIndex {
 		if strings.Contains(indexWord, "interface") {
 			for _, key := range keys {
-				candidateKeys[key]++
+				flood[key]++
 			}
 		}
 	}
-}
 
-func (e *Engine) generateIndexedSearchResults(candidates []scoredCandidate, query string) []models.SearchResult {
-	results := make([]models.SearchResult, 0, constants.MaxSearchResults)
+	if limit <= 0 || len(candidateKeys)+len(flood) <= limit {
+		for key, count := range flood {
+			addFloodCandidate(candidateKeys, key, count)
+		}
+		return
+	}
 
-	for i, cand := range candidates {
-		if i >= constants.MaxSearchResults {
+	for _, key := range rankFloodKeys(flood) {
+		if len(candidateKeys) >= limit {
 			break
 		}
+		addFloodCandidate(candidateKeys, key, flood[key])
+	}
+}
 
-		entry := e.db.Table[cand.key]
+// addFloodCandidate adds an interface table unless the index already found
+// it, in which case its match count stays the number of query words it
+// matched rather than being inflated by the flood
+func addFloodCandidate(candidateKeys map[string]int, key string, count int) {
+	if _, ok := candidateKeys[key]; !ok {
+		candidateKeys[key] = count
+	}
+}
+
+// rankFloodKeys orders flood candidates by how many interface words list
+// them, then by path length and lexically
+func rankFloodKeys(flood map[string]int) []string {
+	keys := make([]string, 0, len(flood))
+	for key := range flood {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if flood[a] != flood[b] {
+			return flood[a] > flood[b]
+		}
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return a < b
+	})
+	return keys
+}
+
+func (e *Engine) generateIndexedSearchResults(candidates []scoredCandidate, query string) []models.SearchResult {
+	if e.maxResults > 0 && len(candidates) > e.maxResults {
+		candidates = candidates[:e.maxResults]
+	}
+	results := make([]models.SearchResult, 0, len(candidates))
+
+	for _, cand := range candidates {
+		entry := cand.db.Table[cand.key]
 		description, fields := parseEmbeddingInfo(entry.Text)
 
 		eqlQuery := models.EQLQuery{
 			Table:       cand.key,
-			Fields:      eql.ExtractFields(query, cand.key, &entry),
+			Fields:      eql.ExtractFieldsWithLimit(query, cand.key, &entry, e.maxFields),
 			WhereClause: eql.GenerateWhereClauseWithValidation(cand.key, query, fields),
 			OrderBy:     eql.ExtractOrderBy(query, cand.key, &entry),
-			Limit:       eql.ExtractLimit(query),
+			Limit:       eql.ExtractLimit(query, cand.key),
 			Delta:       eql.ExtractDelta(query),
 		}
 
 		results = append(results, models.SearchResult{
 			Key:             cand.key,
 			Score:           cand.score,
+			NormalizedScore: normalizeScore(cand.score, candidates[0].score),
 			EQLQuery:        eqlQuery,
 			Description:     description,
 			AvailableFields: fields,
+			Source:          cand.db.Name,
+			Comparison:      comparisonQueries(eqlQuery, query, fields),
+			FleetWide:       eql.IsFleetWide(query) && strings.Contains(cand.key, ".namespace.node."),
+			ReferenceText:   e.referenceText(entry),
 		})
 	}
 
 	return results
 }
+
+// comparisonQueries splits a query comparing several nodes into one query
+// per node, identical to base except for the node filter
+func comparisonQueries(base models.EQLQuery, query string, fields []string) []models.NodeQuery {
+	if !strings.Contains(base.Table, ".namespace.node.") {
+		return nil
+	}
+	nodes := eql.ComparedNodes(query)
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	queries := make([]models.NodeQuery, len(nodes))
+	for i, node := range nodes {
+		nodeQuery := base
+		nodeQuery.WhereClause = eql.GenerateNodeWhereClause(base.Table, query, node, fields)
+		queries[i] = models.NodeQuery{Node: node, EQLQuery: nodeQuery}
+	}
+	return queries
+}
+
+// normalizeScore expresses a score relative to the best score so results can
+// be compared across queries regardless of how many words they contain
+func normalizeScore(score, best float64) float64 {
+	if best <= 0 {
+		return 0
+	}
+	return score / best
+}
+
+// referenceText returns the entry's ReferenceText when results should carry it
+func (e *Engine) referenceText(entry models.EmbeddingEntry) string {
+	if !e.includeReferenceText {
+		return ""
+	}
+	return entry.ReferenceText
+}
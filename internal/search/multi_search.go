@@ -0,0 +1,73 @@
+// Package search answers queries naming several concepts with one ranked
+// result list per concept.
+package search
+
+import (
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/eql"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// ConceptResults holds the results for one concept of a multi-concept query
+type ConceptResults struct {
+	Concept string // the part of the query the results answer
+	Results []models.SearchResult
+}
+
+// MultiSearch splits a query such as "show bgp neighbors and their
+// interfaces on leaf1" on its conjunctions and searches each part
+// separately. Node names anywhere in the query apply to every part. Parts
+// that find nothing, or whose top table matches the previous part's, are
+// folded into the previous part; leading parts that find nothing are folded
+// into the next one. A query that does not yield at least two distinct
+// concepts returns a single ConceptResults for the whole query.
+func (e *Engine) MultiSearch(query string) []ConceptResults {
+	whole := func() []ConceptResults {
+		return []ConceptResults{{Concept: query, Results: e.IndexedSearch(query)}}
+	}
+
+	segments := eql.SplitConcepts(query)
+	if len(segments) < 2 {
+		return whole()
+	}
+
+	nodes := eql.ExtractNodeNames(query)
+	var concepts []ConceptResults
+	pending := "" // leading segments that found nothing on their own
+	for _, segment := range segments {
+		if pending != "" {
+			segment = pending + " and " + segment
+			pending = ""
+		}
+		results := e.IndexedSearch(withNodes(segment, nodes))
+
+		switch {
+		case len(concepts) == 0 && len(results) == 0:
+			pending = segment
+		case len(concepts) == 0:
+			concepts = append(concepts, ConceptResults{Concept: segment, Results: results})
+		case len(results) == 0 || results[0].Key == concepts[len(concepts)-1].Results[0].Key:
+			last := &concepts[len(concepts)-1]
+			last.Concept += " and " + segment
+			if merged := e.IndexedSearch(withNodes(last.Concept, nodes)); len(merged) > 0 {
+				last.Results = merged
+			}
+		default:
+			concepts = append(concepts, ConceptResults{Concept: segment, Results: results})
+		}
+	}
+
+	if len(concepts) < 2 {
+		return whole()
+	}
+	return concepts
+}
+
+// withNodes appends the query's node names to a segment naming none
+func withNodes(segment string, nodes []string) string {
+	if len(nodes) == 0 || len(eql.ExtractNodeNames(segment)) > 0 {
+		return segment
+	}
+	return segment + " on " + strings.Join(nodes, " and ")
+}
@@ -0,0 +1,38 @@
+// Package search provides path filters that restrict results to, or away
+// from, parts of the table tree.
+package search
+
+import "strings"
+
+// PathFilter restricts table paths by substring. A path must contain at least
+// one Include entry (when any are given) and none of the Exclude entries.
+type PathFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// IsEmpty reports whether the filter has no restrictions
+func (f PathFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Match reports whether a table path passes the filter
+func (f PathFilter) Match(key string) bool {
+	keyLower := strings.ToLower(key)
+
+	for _, exclude := range f.Exclude {
+		if strings.Contains(keyLower, strings.ToLower(exclude)) {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, include := range f.Include {
+		if strings.Contains(keyLower, strings.ToLower(include)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+// Package search prepares raw queries for tokenization and matching.
+package search
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/eql"
+)
+
+// rewriteQuery applies the configured QueryRewriter, if any
+func (e *Engine) rewriteQuery(query string) string {
+	if e.rewriter == nil {
+		return query
+	}
+	return e.rewriter(query)
+}
+
+// scoringQuery strips conversational lead-ins so "show me the interfaces"
+// scores like "interfaces", drops fleet-wide wording such as "for all nodes",
+// spells subinterface terms as one word,
+// points MAC to IP questions at ARP tables and top talkers and flaps at
+// interface statistics. Prefixes are removed repeatedly from the start of
+// the query; if nothing searchable would remain the query is kept as is.
+func (e *Engine) scoringQuery(query string) string {
+	query = eql.FlapsToTransitions(eql.TopTalkersToTraffic(mentionARP(eql.NormalizeSubinterfaceTerms(query))))
+	stripped := eql.StripFleetWide(query)
+	for {
+		next := stripLeadIn(stripped, e.config.ConversationalPrefixes)
+		if next == stripped {
+			break
+		}
+		stripped = next
+	}
+
+	if len(queryWords(stripped)) == 0 {
+		return query
+	}
+	return stripped
+}
+
+// macToIPPattern matches "mac to ip bindings", "mac/ip table" and similar
+var macToIPPattern = regexp.MustCompile(`(?i)\bmac(?:\s+to\s+|\s*[-/]\s*|\s+)ipv?4?(?:\s+(?:bindings?|mappings?|table|entries))?\b`)
+
+// mentionARP rewrites questions about MAC to IP bindings, which is what ARP
+// tables hold, as "arp entries"
+func mentionARP(query string) string {
+	return macToIPPattern.ReplaceAllString(query, "arp entries")
+}
+
+// stripLeadIn removes the first prefix that starts query as whole words
+func stripLeadIn(query string, prefixes []string) string {
+	lower := strings.ToLower(query)
+	for _, prefix := range prefixes {
+		rest, ok := strings.CutPrefix(lower, prefix)
+		if ok && (rest == "" || rest[0] == ' ') {
+			return strings.TrimSpace(query[len(prefix):])
+		}
+	}
+	return query
+}
+
+// queryWords tokenizes a query for index lookups. Quote characters are
+// dropped so quoted phrases still contribute their words as candidates,
+// while the phrases themselves are matched intact during scoring. IP
+// addresses are replaced by their address family and MAC addresses by "mac".
+func queryWords(query string) []string {
+	tokens, namesMasked := queryTokens(query)
+	words := ExpandSynonyms(tokens)
+
+	// Interface identifiers are masked so "ethernet-1/1" does not count as
+	// "ethernet". When little else is left they still imply an interface.
+	if namesMasked && contentWordCount(words) <= 1 && !slices.Contains(words, "interface") {
+		words = append(words, "interface")
+	}
+
+	// Masked addresses still tell which address family is meant
+	for _, family := range eql.AddressFamilies(query) {
+		if !slices.Contains(words, family) {
+			words = append(words, family)
+		}
+	}
+	if len(eql.ExtractMACAddresses(query)) > 0 && !slices.Contains(words, "mac") {
+		words = append(words, "mac")
+	}
+	return words
+}
+
+// queryTokens tokenizes a query with quotes dropped and addresses and
+// interface names masked, before synonym expansion. The boolean reports
+// whether any interface name was masked.
+func queryTokens(query string) ([]string, bool) {
+	query = eql.MaskAddresses(strings.ReplaceAll(query, `"`, " "))
+	masked := eql.MaskInterfaceNames(query)
+	return Tokenize(masked), masked != query
+}
+
+// AppliedSynonyms reports which words of query are replaced by synonyms or
+// typo corrections before searching, after rewriting and lead-in stripping
+func (e *Engine) AppliedSynonyms(query string) []Synonym {
+	tokens, _ := queryTokens(e.scoringQuery(e.rewriteQuery(query)))
+	return AppliedSynonyms(tokens)
+}
+
+// TypoCorrections reports which words of query are known misspellings that
+// would be corrected before searching
+func (e *Engine) TypoCorrections(query string) []Synonym {
+	tokens, _ := queryTokens(e.scoringQuery(e.rewriteQuery(query)))
+	return TypoCorrections(tokens)
+}
+
+// contentWordCount counts words other than leading verbs like "show"
+func contentWordCount(words []string) int {
+	count := 0
+	for _, w := range words {
+		if w != "show" && w != "get" && w != "list" && w != "all" {
+			count++
+		}
+	}
+	return count
+}
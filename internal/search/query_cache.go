@@ -0,0 +1,130 @@
+// Package search caches search results per normalized query so repeated
+// queries skip candidate scoring.
+package search
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// queryCache is a fixed-size LRU cache of search results. It is safe for
+// concurrent use.
+type queryCache struct {
+	mu          sync.Mutex
+	size        int
+	entries     map[string]*list.Element
+	order       *list.List // front is most recently used
+	fingerprint int
+}
+
+type queryCacheEntry struct {
+	key      string
+	results  []models.SearchResult
+	path     string // SearchStats.Path of the search that produced results
+	fallback bool   // SearchStats.Fallback of that search
+}
+
+func newQueryCache(size int) *queryCache {
+	return &queryCache{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+// queryCacheKey normalizes a rewritten query. Case and word order are kept
+// because conditions, sort fields and quoted phrases depend on them; only
+// surrounding and repeated whitespace is folded.
+func queryCacheKey(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// dbFingerprint summarizes the size of every database so the cache can be
+// dropped when entries are added or removed after the engine was built
+func dbFingerprint(dbs []*models.EmbeddingDB) int {
+	sum := 0
+	for _, db := range dbs {
+		sum = sum*31 + len(db.Table)
+		sum = sum*31 + len(db.InvertedIndex)
+	}
+	return sum
+}
+
+// get returns a deep copy of the cached results for key, with the search path
+// and fallback flag they were found with. A fingerprint differing from the
+// one the cache was filled under clears the cache first.
+func (c *queryCache) get(key string, fingerprint int) ([]models.SearchResult, SearchStats, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if fingerprint != c.fingerprint {
+		c.resetLocked()
+		c.fingerprint = fingerprint
+		return nil, SearchStats{}, false
+	}
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, SearchStats{}, false
+	}
+	c.order.MoveToFront(elem)
+	entry := elem.Value.(*queryCacheEntry)
+	return models.CloneResults(entry.results), SearchStats{Path: entry.path, Fallback: entry.fallback}, true
+}
+
+// put stores a deep copy of results for key along with the path and fallback
+// flag of stats, evicting the least recently used entry when the cache is
+// full
+func (c *queryCache) put(key string, results []models.SearchResult, stats SearchStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &queryCacheEntry{key: key, results: models.CloneResults(results), path: stats.Path, fallback: stats.Fallback}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*queryCacheEntry).key)
+	}
+}
+
+func (c *queryCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resetLocked()
+}
+
+func (c *queryCache) resetLocked() {
+	clear(c.entries)
+	c.order.Init()
+}
+
+// WithQueryCache keeps the results of the last size distinct queries so
+// repeated queries are answered without rescoring. Queries are keyed after
+// the QueryRewriter runs, with whitespace folded. Non-positive sizes leave
+// caching disabled.
+func WithQueryCache(size int) Option {
+	return func(e *Engine) {
+		if size > 0 {
+			e.cache = newQueryCache(size)
+		}
+	}
+}
+
+// ClearQueryCache drops all cached results. Call it after modifying a
+// database in place; additions and removals of entries are also detected
+// automatically.
+func (e *Engine) ClearQueryCache() {
+	if e.cache != nil {
+		e.cache.clear()
+	}
+}
@@ -0,0 +1,49 @@
+// Package search scores the whole corpus for offline analysis of ranking
+// behavior.
+package search
+
+// ScoredKey is a table path with its raw score for a query
+type ScoredKey struct {
+	Key    string
+	Score  float64
+	Source string // name of the database holding the table
+}
+
+// ScoreAll scores every table path in every database for query and returns
+// those scoring above zero and at least the engine's minimum score, best
+// first. Unlike IndexedSearch it does not stop at the top results or build
+// EQL queries, and it skips the per-table score thresholds, so it is meant
+// for analyzing rankings rather than answering queries. The path filter and
+// query rewriter still apply; the query cache does not.
+func (e *Engine) ScoreAll(query string) []ScoredKey {
+	e.ensureIndexes()
+	scoring := e.scoringQuery(e.rewriteQuery(query))
+	words := queryWords(scoring)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var candidates []scoredCandidate
+	for _, db := range e.dbs {
+		matchCounts := make(map[string]int)
+		addIndexedCandidates(db, words, matchCounts)
+		for key, entry := range db.Table {
+			if !e.pathFilter.IsEmpty() && !e.pathFilter.Match(key) {
+				continue
+			}
+			score := e.calculateCandidateScore(entry, key, matchCounts[key], scoring, words)
+			if score > 0 && score >= e.minScore {
+				candidates = append(candidates, scoredCandidate{key: key, score: score, db: db})
+			}
+		}
+	}
+
+	candidates = e.dedupCandidates(candidates)
+	sortCandidates(candidates)
+
+	scored := make([]ScoredKey, len(candidates))
+	for i, cand := range candidates {
+		scored[i] = ScoredKey{Key: cand.key, Score: cand.score, Source: cand.db.Name}
+	}
+	return scored
+}
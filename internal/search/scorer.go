@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"slices"
 	"strings"
+	"unicode"
 
 	"github.com/eda-labs/eda-embeddingsearch/internal/eql"
 	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
@@ -64,38 +65,83 @@ func (e *Engine) countBasedScore(count int, thresholds []struct {
 	return 0
 }
 
+// scoreComponent is one named part of an entry's score
+type scoreComponent struct {
+	name  string
+	score float64
+}
+
 // scoreEntry calculates the relevance score for a candidate entry using
 // various heuristics and matching rules.
 func (e *Engine) scoreEntry(key string, entry models.EmbeddingEntry, query string, words []string) float64 {
+	return sumComponents(e.scoreEntryComponents(key, entry, query, words))
+}
+
+// scoreEntryComponents breaks scoreEntry down into its named parts
+func (e *Engine) scoreEntryComponents(key string, entry models.EmbeddingEntry, query string, words []string) []scoreComponent {
 	keyTokens := Tokenize(key)
 	textTokens := Tokenize(entry.ReferenceText + " " + entry.Text)
 	queryLower := strings.ToLower(query)
+	expanded := expandQuery(queryLower, words)
 	keyLower := strings.ToLower(key)
 
-	score := 0.0
-
-	// Keyword scoring
-	score += e.keywordScoreV2(keyTokens, textTokens, words)
-
-	// Description scoring
-	score += e.descriptionScoreV2(queryLower, entry, words)
-
-	// Context-based scoring
-	score += e.contextScore(queryLower, key, keyLower, words)
-
-	// Field extraction scoring
 	extractedFields := eql.ExtractFields(query, key, &entry)
-	score += float64(len(extractedFields)) * e.config.FieldExtractScore
-
-	// Special query scoring
-	score += e.specialQueryScore(queryLower, key, extractedFields)
+	description, fields := parseEmbeddingInfo(entry.Text)
+
+	return []scoreComponent{
+		{"keywords", e.keywordScoreV2(keyTokens, textTokens, words)},
+		{"description", e.descriptionScoreV2(expanded, entry, words)},
+		{"phrase", e.phraseMatchScore(keyTokens, description, words)},
+		{"quoted phrase", e.quotedPhraseScore(query, description)},
+		{"context", e.contextScore(expanded, key, keyLower, words)},
+		{"extracted fields", float64(len(extractedFields)) * e.config.FieldExtractScore},
+		{"by field", e.byFieldScore(queryLower, keyTokens, words, fields)},
+		// Fields named verbatim identify the table holding those metrics
+		{"named fields", float64(len(eql.NamedFields(queryLower, fields))) * e.config.NamedFieldMatch},
+		{"special query", e.specialQueryScore(expanded, key, extractedFields)},
+		{"path depth", e.pathDepthScore(keyTokens)},
+		{"penalties", e.penaltyScore(expanded, key)},
+	}
+}
 
-	// Path depth scoring
-	score += e.pathDepthScore(keyTokens)
+// byFieldScore rewards a table holding the field named by "by <field>", so
+// the table with that metric ranks first. It only applies when another query
+// word already matches the table path: "processes by name" must not favor
+// every table that has a name field.
+func (e *Engine) byFieldScore(queryLower string, keyTokens, words, fields []string) float64 {
+	field := eql.FindByField(queryLower, fields)
+	if field == "" {
+		return 0
+	}
+	fieldTokens := Tokenize(field)
+	for _, w := range words {
+		if !slices.Contains(fieldTokens, w) && slices.Contains(keyTokens, w) {
+			return e.config.ByFieldMatch
+		}
+	}
+	return 0
+}
 
-	// Penalty scoring
-	score += e.penaltyScore(queryLower, key)
+// expandQuery appends the synonym-expanded query words that queryLower does
+// not already contain, so substring checks treat "iface stats" like
+// "interface statistics" while the user's own wording, such as a plural,
+// still matches
+func expandQuery(queryLower string, words []string) string {
+	var b strings.Builder
+	b.WriteString(queryLower)
+	for _, w := range words {
+		if !strings.Contains(queryLower, w) {
+			b.WriteString(" " + w)
+		}
+	}
+	return b.String()
+}
 
+func sumComponents(components []scoreComponent) float64 {
+	score := 0.0
+	for _, c := range components {
+		score += c.score
+	}
 	return score
 }
 
@@ -177,7 +223,12 @@ func (e *Engine) descriptionScoreV2(queryLower string, entry models.EmbeddingEnt
 		{"get", "retrieve", e.config.DescriptionGetMatch},
 	}
 
+	// "all fields" asks for every column, not for tables listing everything
+	wantsAllFields := eql.WantsAllFields(queryLower)
 	for _, p := range patterns {
+		if wantsAllFields && p.QueryPattern == "all" {
+			continue
+		}
 		score += e.containsAllScore(queryLower+" "+descLower, []string{p.QueryPattern, p.DescPattern}, p.Score)
 	}
 
@@ -187,6 +238,54 @@ func (e *Engine) descriptionScoreV2(queryLower string, entry models.EmbeddingEnt
 	return score
 }
 
+// phraseMatchScore rewards query words that appear contiguously and in order
+// in the key or description. The longest such run of at least two words is
+// scored per word, so longer phrases earn more than scattered matches.
+func (e *Engine) phraseMatchScore(keyTokens []string, description string, words []string) float64 {
+	if len(words) < 2 {
+		return 0
+	}
+
+	keyPhrase := " " + strings.Join(ExpandSynonyms(keyTokens), " ") + " "
+	score := float64(longestPhraseMatch(words, keyPhrase)) * e.config.PhraseMatchBonus
+
+	descPhrase := " " + strings.Join(ExpandSynonyms(Tokenize(description)), " ") + " "
+	score += float64(longestPhraseMatch(words, descPhrase)) * e.config.DescriptionPhraseMatch
+
+	return score
+}
+
+// quotedPhraseScore rewards entries whose description contains a phrase the
+// user quoted, matched as a whole and case-insensitively
+func (e *Engine) quotedPhraseScore(query, description string) float64 {
+	phrases := eql.ExtractQuotedPhrases(query)
+	if len(phrases) == 0 {
+		return 0
+	}
+
+	descLower := strings.ToLower(description)
+
+	score := 0.0
+	for _, phrase := range phrases {
+		score += e.conditionalScore(strings.Contains(descLower, strings.ToLower(phrase)), e.config.QuotedPhraseMatch)
+	}
+	return score
+}
+
+// longestPhraseMatch returns the length of the longest run of consecutive
+// words (at least two) found in text, or 0 when there is none
+func longestPhraseMatch(words []string, text string) int {
+	for length := len(words); length >= 2; length-- {
+		for start := 0; start+length <= len(words); start++ {
+			phrase := " " + strings.Join(words[start:start+length], " ") + " "
+			if strings.Contains(text, phrase) {
+				return length
+			}
+		}
+	}
+	return 0
+}
+
 // contextScore handles various context-based scoring rules
 func (e *Engine) contextScore(queryLower, key, keyLower string, words []string) float64 {
 	score := 0.0
@@ -202,6 +301,12 @@ func (e *Engine) contextScore(queryLower, key, keyLower string, words []string)
 	// BGP-related scoring
 	score += e.bgpContextScore(queryLower, key)
 
+	// ARP/ND neighbor scoring
+	score += e.l3NeighborScore(queryLower, key)
+
+	// Address families of routing protocols
+	score += e.afiSafiScore(queryLower, key)
+
 	// Segment and suffix matching
 	score += e.segmentMatchScoreV2(keyLower, words)
 	score += e.suffixMatchScore(key, words)
@@ -215,12 +320,29 @@ func (e *Engine) contextScore(queryLower, key, keyLower string, words []string)
 	// Subinterface matching
 	score += e.subinterfaceMatchScore(queryLower, key)
 
+	// Configuration comparison intent
+	score += e.conditionalScore(eql.HasDiffIntent(queryLower) && strings.Contains(key, ".configure."), e.config.ConfigDiffBonus)
+
+	// Counters since the last clear live in statistics tables
+	score += e.sinceClearScore(queryLower, key)
+
 	return score
 }
 
+// sinceClearScore favors statistics tables for counters since the last clear
+func (e *Engine) sinceClearScore(queryLower, key string) float64 {
+	if !eql.HasSinceClear(queryLower) {
+		return 0
+	}
+	if strings.Contains(key, "statistics") {
+		return e.config.SinceClearBonus
+	}
+	return e.config.SinceClearPenalty
+}
+
 // bgpContextScore handles BGP-specific scoring
 func (e *Engine) bgpContextScore(queryLower, key string) float64 {
-	if !strings.Contains(queryLower, "bgp") {
+	if !hasBGPContext(queryLower) {
 		return 0
 	}
 
@@ -257,6 +379,68 @@ func (e *Engine) bgpContextScore(queryLower, key string) float64 {
 	return score
 }
 
+// hasBGPContext checks if the query is about BGP peering rather than any
+// other kind of neighbor
+func hasBGPContext(queryLower string) bool {
+	words := strings.Fields(queryLower)
+	for i, word := range words {
+		switch word {
+		case "bgp", "ebgp", "ibgp", "peer", "peers", "peering", "asn":
+			return true
+		case "as":
+			// "as 65001", not "as well"
+			if i+1 < len(words) && unicode.IsDigit(rune(words[i+1][0])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasL3NeighborContext checks if the query is about ARP or IPv6 neighbor
+// discovery entries. BGP context wins when both are present.
+func hasL3NeighborContext(queryLower string) bool {
+	if hasBGPContext(queryLower) {
+		return false
+	}
+	for _, word := range strings.Fields(queryLower) {
+		if word == "arp" || word == "mac" || word == "nd" {
+			return true
+		}
+	}
+	l3Phrases := []string{"ip neighbor", "ipv4 neighbor", "ipv6 neighbor", "neighbor discovery", "neighbor-discovery", "link-layer"}
+	for _, phrase := range l3Phrases {
+		if strings.Contains(queryLower, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// l3NeighborScore favors ARP and neighbor discovery tables over BGP
+// neighbors for queries about IP-to-MAC neighbors
+func (e *Engine) l3NeighborScore(queryLower, key string) float64 {
+	if !hasL3NeighborContext(queryLower) {
+		return 0
+	}
+	switch {
+	case strings.Contains(key, ".arp.") || strings.Contains(key, ".neighbor-discovery."):
+		return e.config.L3NeighborMatch
+	case strings.Contains(key, ".bgp."):
+		return e.config.L3NeighborBGPPenalty
+	}
+	return 0
+}
+
+// afiSafiScore favors AFI-SAFI tables when a BGP query names an address
+// family, since only they can filter on it
+func (e *Engine) afiSafiScore(queryLower, key string) float64 {
+	if !hasBGPContext(queryLower) || len(eql.ExtractAFISAFI(queryLower)) == 0 {
+		return 0
+	}
+	return e.conditionalScore(strings.Contains(key, ".afi-safi"), e.config.AFISAFIMatch)
+}
+
 // hasSessionStateKeywords checks if query has session state related keywords
 func hasSessionStateKeywords(queryLower string) bool {
 	sessionKeywords := []string{"established", "down", "up", "active", "session", "state", "status"}
@@ -392,6 +576,16 @@ func (e *Engine) specialQueryScore(queryLower, key string, extractedFields []str
 		}
 	}
 
+	// Optical power lives in per-channel transceiver readings
+	if eql.MentionsOpticalPower(queryLower) && strings.Contains(key, "transceiver") {
+		for _, field := range extractedFields {
+			if strings.HasSuffix(field, "-power") {
+				score += e.config.OpticalPowerFieldBonus
+				break
+			}
+		}
+	}
+
 	return score
 }
 
@@ -411,6 +605,15 @@ func (e *Engine) penaltyScore(queryLower, key string) float64 {
 		score += e.config.MaintenancePenalty
 	}
 
+	// Deprecated or internal path penalty, unless the query asks for it
+	keyLower := strings.ToLower(key)
+	for _, pattern := range e.config.DeprecatedPathPatterns {
+		if strings.Contains(keyLower, pattern) && !strings.Contains(queryLower, pattern) {
+			score += e.config.DeprecatedPathPenalty
+			break
+		}
+	}
+
 	return score
 }
 
@@ -2,8 +2,15 @@
 // across different matching dimensions.
 package search
 
+import "github.com/eda-labs/eda-embeddingsearch/internal/constants"
+
 // ScoringConfig contains all the scoring weights and penalties
 type ScoringConfig struct {
+	// Candidates must score above these to be kept; SR OS tables use their
+	// own, lower bar
+	CandidateThreshold     float64
+	SROSCandidateThreshold float64
+
 	// Keyword matching scores
 	LastSegmentMatch      float64
 	KeywordMatchInterface float64
@@ -20,6 +27,13 @@ type ScoringConfig struct {
 	DescriptionGetMatch   float64
 	DescriptionMultiMatch float64
 
+	// Contiguous phrase matching, awarded per phrase word
+	PhraseMatchBonus       float64
+	DescriptionPhraseMatch float64
+
+	// Quoted phrase found verbatim in the description
+	QuotedPhraseMatch float64
+
 	// Interface scoring
 	InterfaceEndMatch        float64
 	InterfaceStatsMatch      float64
@@ -35,6 +49,13 @@ type ScoringConfig struct {
 	BGPMaintenancePenalty        float64
 	BGPMaintenanceSessionPenalty float64
 
+	// ARP/ND neighbor scoring
+	L3NeighborMatch      float64
+	L3NeighborBGPPenalty float64
+
+	// Address family named in a BGP query
+	AFISAFIMatch float64
+
 	// Path depth scoring
 	PathDepthBonus2        float64
 	PathDepthBonus3        float64
@@ -52,25 +73,44 @@ type ScoringConfig struct {
 	ExactTableMatch          float64
 	BigramMatch              float64
 	FieldExtractScore        float64
+	ByFieldMatch             float64
+	NamedFieldMatch          float64
 	SequenceMatch            float64
 	SequencePartialMatch     float64
 
 	// Context bonuses
 	ShowStateBonus     float64
 	AllWordsMatchBonus float64
+	ConfigDiffBonus    float64
+	SinceClearBonus    float64
 
 	// Penalties
 	ProtocolPenalty    float64
 	MaintenancePenalty float64
+	SinceClearPenalty  float64
+
+	// Paths containing any of these lowercase substrings are penalized
+	// unless the query mentions the substring itself
+	DeprecatedPathPatterns []string
+	DeprecatedPathPenalty  float64
 
 	// Special query scoring
-	ErrorFieldBonus     float64
-	BandwidthFieldBonus float64
+	ErrorFieldBonus        float64
+	BandwidthFieldBonus    float64
+	OpticalPowerFieldBonus float64
+
+	// Conversational lead-ins stripped from the start of a query before
+	// scoring, e.g. "show me" or "can you get"
+	ConversationalPrefixes []string
 }
 
 // DefaultScoringConfig returns the default scoring configuration
 func DefaultScoringConfig() *ScoringConfig {
 	return &ScoringConfig{
+		// Candidate thresholds
+		CandidateThreshold:     constants.DefaultScoreThreshold,
+		SROSCandidateThreshold: constants.SROSScoreThreshold,
+
 		// Keyword matching scores
 		LastSegmentMatch:      10,
 		KeywordMatchInterface: 8,
@@ -87,6 +127,13 @@ func DefaultScoringConfig() *ScoringConfig {
 		DescriptionGetMatch:   2,
 		DescriptionMultiMatch: 5,
 
+		// Contiguous phrase matching, awarded per phrase word
+		PhraseMatchBonus:       4,
+		DescriptionPhraseMatch: 2,
+
+		// Quoted phrase found verbatim in the description
+		QuotedPhraseMatch: 40,
+
 		// Interface scoring
 		InterfaceEndMatch:        20,
 		InterfaceStatsMatch:      15,
@@ -102,6 +149,13 @@ func DefaultScoringConfig() *ScoringConfig {
 		BGPMaintenancePenalty:        -10,
 		BGPMaintenanceSessionPenalty: -25,
 
+		// ARP/ND neighbor scoring
+		L3NeighborMatch:      15,
+		L3NeighborBGPPenalty: -20,
+
+		// Address family named in a BGP query
+		AFISAFIMatch: 40,
+
 		// Path depth scoring
 		PathDepthBonus2:        20,
 		PathDepthBonus3:        10,
@@ -119,19 +173,35 @@ func DefaultScoringConfig() *ScoringConfig {
 		ExactTableMatch:          6,
 		BigramMatch:              2,
 		FieldExtractScore:        1.5,
+		ByFieldMatch:             60,
+		NamedFieldMatch:          25,
 		SequenceMatch:            8,
 		SequencePartialMatch:     4,
 
 		// Context bonuses
 		ShowStateBonus:     5,
 		AllWordsMatchBonus: 3,
+		ConfigDiffBonus:    15,
+		SinceClearBonus:    30,
 
 		// Penalties
 		ProtocolPenalty:    -10,
 		MaintenancePenalty: -8,
+		SinceClearPenalty:  -15,
+
+		DeprecatedPathPatterns: []string{"deprecated", "debug", "internal", "obsolete"},
+		DeprecatedPathPenalty:  -15,
 
 		// Special query scoring
-		ErrorFieldBonus:     10,
-		BandwidthFieldBonus: 10,
+		ErrorFieldBonus:        10,
+		BandwidthFieldBonus:    10,
+		OpticalPowerFieldBonus: 30,
+
+		ConversationalPrefixes: []string{
+			"can you please", "could you please", "can you", "could you", "would you",
+			"i want to see", "i would like to see", "i'd like to see", "i need to see",
+			"i want", "i need", "please", "show me", "give me", "get me", "tell me", "find me",
+			"let me see", "the",
+		},
 	}
 }
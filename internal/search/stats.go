@@ -0,0 +1,39 @@
+// Package search reports how a search found its results, for diagnosing
+// slow or weak queries.
+package search
+
+import (
+	"fmt"
+	"time"
+)
+
+// Search paths reported in SearchStats
+const (
+	PathIndexed = "indexed" // candidates came from the inverted index
+	PathFull    = "full"    // table text was scanned without the index
+)
+
+// SearchStats describes the work done by one search
+type SearchStats struct {
+	Path          string        // which search path produced the results
+	Cached        bool          // results were served from the query cache
+	Fallback      bool          // the index had no candidates, so the full scan ran
+	CandidateKeys int           // candidate keys after path filtering, across all databases
+	Scored        int           // candidates scoring above the threshold
+	Results       int           // results returned
+	ScoringTime   time.Duration // time spent scoring candidates
+	Total         time.Duration // time spent in the whole search
+}
+
+// String renders the stats on a single line
+func (s SearchStats) String() string {
+	path := s.Path
+	if s.Fallback {
+		path += " (fallback)"
+	}
+	if s.Cached {
+		path += " (cached)"
+	}
+	return fmt.Sprintf("path=%s candidates=%d scored=%d results=%d scoring=%s total=%s",
+		path, s.CandidateKeys, s.Scored, s.Results, s.ScoringTime, s.Total)
+}
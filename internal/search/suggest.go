@@ -0,0 +1,143 @@
+// Package search provides prefix-based table suggestions for interactive
+// typeahead, answered from the inverted index.
+package search
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Suggest returns table paths matching what the user has typed so far. Every
+// completed word must match a path, and the word still being typed matches
+// any index term it prefixes. Paths whose own segments match rank first, then
+// paths hit by more prefixed terms. A non-positive limit falls back to
+// MaxSearchResults.
+func (e *Engine) Suggest(prefix string, limit int) []string {
+	e.ensureIndexes()
+	words, partial := splitSuggestPrefix(prefix)
+	if len(words) == 0 && partial == "" {
+		return nil
+	}
+	if limit <= 0 {
+		limit = constants.MaxSearchResults
+	}
+
+	hits := make(map[string]int)
+	for i, db := range e.dbs {
+		for key, count := range suggestionHits(db, e.terms[i], words, partial) {
+			if count > hits[key] {
+				hits[key] = count
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(hits))
+	inPath := make(map[string]bool, len(hits))
+	for key := range hits {
+		keys = append(keys, key)
+		inPath[key] = pathMatchesPrefix(key, words, partial)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if inPath[keys[i]] != inPath[keys[j]] {
+			return inPath[keys[i]]
+		}
+		if hits[keys[i]] != hits[keys[j]] {
+			return hits[keys[i]] > hits[keys[j]]
+		}
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
+
+// splitSuggestPrefix separates completed words from the word being typed.
+// Input ending in a separator has no partial word.
+func splitSuggestPrefix(prefix string) (words []string, partial string) {
+	lower := strings.ToLower(prefix)
+	parts := strings.FieldsFunc(lower, func(r rune) bool {
+		return r == ' ' || r == '.' || r == '-' || r == '_'
+	})
+	if len(parts) == 0 {
+		return nil, ""
+	}
+
+	if !strings.ContainsAny(lower[len(lower)-1:], " .-_") {
+		partial = parts[len(parts)-1]
+		parts = parts[:len(parts)-1]
+	}
+
+	if len(parts) > 0 {
+		words = ExpandSynonyms(Tokenize(strings.Join(parts, " ")))
+	}
+	return words, partial
+}
+
+// suggestionHits counts, per key, the index terms matching the partial word,
+// restricted to keys that contain every completed word. The terms prefixed by
+// the partial word are a contiguous run of the sorted terms.
+func suggestionHits(db *models.EmbeddingDB, terms, words []string, partial string) map[string]int {
+	var hits map[string]int
+
+	if partial != "" {
+		hits = make(map[string]int)
+		for _, term := range terms[sort.SearchStrings(terms, partial):] {
+			if !strings.HasPrefix(term, partial) {
+				break
+			}
+			for _, key := range db.InvertedIndex[term] {
+				hits[key]++
+			}
+		}
+	}
+
+	for _, word := range words {
+		keys := db.InvertedIndex[word]
+		if hits == nil {
+			hits = make(map[string]int, len(keys))
+			for _, key := range keys {
+				hits[key] = 1
+			}
+			continue
+		}
+
+		matched := make(map[string]int, len(keys))
+		for _, key := range keys {
+			if count, ok := hits[key]; ok {
+				matched[key] = count + 1
+			}
+		}
+		hits = matched
+	}
+
+	return hits
+}
+
+// pathMatchesPrefix reports whether the typed words match the table path
+// itself rather than only its description
+func pathMatchesPrefix(key string, words []string, partial string) bool {
+	segments := ExpandSynonyms(Tokenize(key))
+	for _, word := range words {
+		if !slices.Contains(segments, word) {
+			return false
+		}
+	}
+	if partial == "" {
+		return true
+	}
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, partial) {
+			return true
+		}
+	}
+	return false
+}
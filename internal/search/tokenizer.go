@@ -3,6 +3,7 @@
 package search
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
@@ -58,67 +59,84 @@ func Tokenize(s string) []string {
 	return tokens
 }
 
-// ExpandSynonyms expands words with their synonyms
-func ExpandSynonyms(words []string) []string {
-	//nolint:misspell // intentionally include common misspellings for expansion
-	synonyms := map[string]string{
-		"stats":         "statistics",
-		"stat":          "statistics",
-		"alarms":        "alarm",
-		"alarm":         "alarms",
-		"fanspeed":      "fan",
-		"fan-speed":     "fan",
-		"temp":          "temperature",
-		"temps":         "temperature",
-		"mtu":           "mtu",
-		"interswitch":   "link",
-		"links":         "link",
-		"iface":         "interface",
-		"ifaces":        "interface",
-		"intf":          "interface",
-		"intfs":         "interface",
-		"interfaces":    "interface", // Map plural to singular
-		"neighbors":     "neighbor",
-		"routes":        "route",
-		"metrics":       "metric",
-		"info":          "information",
-		"config":        "configure",
-		"configuration": "configure",
-		// Common typos
-		"inferface":  "interface",
-		"inferfaces": "interface",
-		"interace":   "interface",
-		"intrface":   "interface",
-		"interfce":   "interface",
-		"interfacs":  "interface",
-		"interfaes":  "interface",
-		"inerface":   "interface",
-		"inerfaces":  "interface",
-		"statitics":  "statistics",
-		"statsitics": "statistics",
-		"statistcs":  "statistics",
-		"statistis":  "statistics",
-		"neighors":   "neighbor",
-		"neigbors":   "neighbor",
-		"neighbor":   "neighbor",
-		"routers":    "router",
-		"sysem":      "system",
-		"systm":      "system",
-		"bandwith":   "bandwidth",
-		"bandwdth":   "bandwidth",
-		"alrms":      "alarm",
-		"alrm":       "alarm",
-		"confg":      "configure",
-		"cofig":      "configure",
-		"usge":       "usage",
-		"useage":     "usage",
-		"dwn":        "down",
-		"drps":       "drops",
-		"drop":       "drops",
+// synonyms maps plurals and abbreviations to the word used in table paths
+var synonyms = map[string]string{
+	"stats":         "statistics",
+	"stat":          "statistics",
+	"alarms":        "alarm",
+	"alarm":         "alarms",
+	"fanspeed":      "fan",
+	"fan-speed":     "fan",
+	"temp":          "temperature",
+	"temps":         "temperature",
+	"mtu":           "mtu",
+	"interswitch":   "link",
+	"links":         "link",
+	"iface":         "interface",
+	"ifaces":        "interface",
+	"intf":          "interface",
+	"intfs":         "interface",
+	"interfaces":    "interface", // Map plural to singular
+	"neighbors":     "neighbor",
+	"routes":        "route",
+	"transceivers":  "transceiver",
+	"metrics":       "metric",
+	"info":          "information",
+	"config":        "configure",
+	"configuration": "configure",
+	"neighbor":      "neighbor",
+	"routers":       "router",
+	"drop":          "drops",
+}
+
+// typos maps common misspellings to the word used in table paths
+//
+//nolint:misspell // intentionally include common misspellings for correction
+var typos = map[string]string{
+	"inferface":  "interface",
+	"inferfaces": "interface",
+	"interace":   "interface",
+	"intrface":   "interface",
+	"interfce":   "interface",
+	"interfacs":  "interface",
+	"interfaes":  "interface",
+	"inerface":   "interface",
+	"inerfaces":  "interface",
+	"statitics":  "statistics",
+	"statsitics": "statistics",
+	"statistcs":  "statistics",
+	"statistis":  "statistics",
+	"neighors":   "neighbor",
+	"neigbors":   "neighbor",
+	"sysem":      "system",
+	"systm":      "system",
+	"bandwith":   "bandwidth",
+	"bandwdth":   "bandwidth",
+	"alrms":      "alarm",
+	"alrm":       "alarm",
+	"confg":      "configure",
+	"cofig":      "configure",
+	"usge":       "usage",
+	"useage":     "usage",
+	"dwn":        "down",
+	"drps":       "drops",
+}
+
+// replacement returns the word w is searched as, if it is a synonym or a
+// known typo
+func replacement(w string) (string, bool) {
+	if s, ok := synonyms[w]; ok {
+		return s, true
 	}
+	s, ok := typos[w]
+	return s, ok
+}
+
+// ExpandSynonyms expands words with their synonyms and corrects known typos
+func ExpandSynonyms(words []string) []string {
 	out := make([]string, 0, len(words))
 	for _, w := range words {
-		if s, ok := synonyms[w]; ok {
+		if s, ok := replacement(w); ok {
 			out = append(out, s)
 		} else {
 			out = append(out, w)
@@ -126,3 +144,39 @@ func ExpandSynonyms(words []string) []string {
 	}
 	return out
 }
+
+// Synonym records a query word that ExpandSynonyms replaced
+type Synonym struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// AppliedSynonyms lists the replacements ExpandSynonyms makes in words, in
+// query order and without repeats. Words mapping to themselves are left out.
+func AppliedSynonyms(words []string) []Synonym {
+	return collectReplacements(words, replacement)
+}
+
+// TypoCorrections lists the misspelled words ExpandSynonyms corrects, in
+// query order and without repeats
+func TypoCorrections(words []string) []Synonym {
+	return collectReplacements(words, func(w string) (string, bool) {
+		s, ok := typos[w]
+		return s, ok
+	})
+}
+
+func collectReplacements(words []string, lookup func(string) (string, bool)) []Synonym {
+	var applied []Synonym
+	for _, w := range words {
+		to, ok := lookup(w)
+		if !ok || to == w {
+			continue
+		}
+		synonym := Synonym{From: w, To: to}
+		if !slices.Contains(applied, synonym) {
+			applied = append(applied, synonym)
+		}
+	}
+	return applied
+}
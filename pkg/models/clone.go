@@ -0,0 +1,44 @@
+// Package models copies results deeply, so a cached result can be handed out
+// without sharing its slices and pointers with the caller.
+package models
+
+import "slices"
+
+// Clone returns a copy of the query sharing no memory with q
+func (q *EQLQuery) Clone() EQLQuery {
+	c := *q
+	c.Fields = slices.Clone(q.Fields)
+	c.OrderBy = slices.Clone(q.OrderBy)
+	if q.Delta != nil {
+		delta := *q.Delta
+		c.Delta = &delta
+	}
+	return c
+}
+
+// Clone returns a copy of the result sharing no memory with r, including
+// its query and per-node comparison queries
+func (r *SearchResult) Clone() SearchResult {
+	c := *r
+	c.EQLQuery = r.EQLQuery.Clone()
+	c.AvailableFields = slices.Clone(r.AvailableFields)
+	if r.Comparison != nil {
+		c.Comparison = make([]NodeQuery, len(r.Comparison))
+		for i, nq := range r.Comparison {
+			c.Comparison[i] = NodeQuery{Node: nq.Node, EQLQuery: nq.EQLQuery.Clone()}
+		}
+	}
+	return c
+}
+
+// CloneResults deep-copies every result with SearchResult.Clone
+func CloneResults(results []SearchResult) []SearchResult {
+	if results == nil {
+		return nil
+	}
+	clones := make([]SearchResult, len(results))
+	for i := range results {
+		clones[i] = results[i].Clone()
+	}
+	return clones
+}
@@ -0,0 +1,103 @@
+// Package models compares two embedding databases, e.g. the releases for two
+// firmware versions, to show how their schema changed.
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+)
+
+// DBDiff lists the table paths added, removed and changed between two
+// databases, each sorted by key
+type DBDiff struct {
+	Added   []string      `json:"added"`
+	Removed []string      `json:"removed"`
+	Changed []TableChange `json:"changed"`
+}
+
+// TableChange describes how a table present in both databases differs. The
+// descriptions are set only when they changed.
+type TableChange struct {
+	Key            string   `json:"key"`
+	AddedFields    []string `json:"addedFields,omitempty"`
+	RemovedFields  []string `json:"removedFields,omitempty"`
+	OldDescription string   `json:"oldDescription,omitempty"`
+	NewDescription string   `json:"newDescription,omitempty"`
+}
+
+// Empty reports whether the databases hold the same tables
+func (d DBDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffDBs compares the tables of before and after. A table counts as changed
+// when fields were added or removed or its description differs; the order of
+// fields is ignored.
+func DiffDBs(before, after *EmbeddingDB) DBDiff {
+	diff := DBDiff{Added: []string{}, Removed: []string{}, Changed: []TableChange{}}
+
+	for key, entry := range after.Table {
+		old, ok := before.Table[key]
+		if !ok {
+			diff.Added = append(diff.Added, key)
+			continue
+		}
+		if change, changed := diffEntries(key, old, entry); changed {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for key := range before.Table {
+		if _, ok := after.Table[key]; !ok {
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Key < diff.Changed[j].Key
+	})
+	return diff
+}
+
+func diffEntries(key string, before, after EmbeddingEntry) (TableChange, bool) {
+	oldDescription, oldFields := entryText(before)
+	newDescription, newFields := entryText(after)
+
+	change := TableChange{
+		Key:           key,
+		AddedFields:   missingFrom(oldFields, newFields),
+		RemovedFields: missingFrom(newFields, oldFields),
+	}
+	if oldDescription != newDescription {
+		change.OldDescription = oldDescription
+		change.NewDescription = newDescription
+	}
+
+	changed := len(change.AddedFields) > 0 || len(change.RemovedFields) > 0 || oldDescription != newDescription
+	return change, changed
+}
+
+// entryText decodes the description and fields of an entry's Text; an
+// unparseable Text yields neither
+func entryText(entry EmbeddingEntry) (string, []string) {
+	var text struct {
+		Description string   `json:"Description"`
+		Fields      []string `json:"Fields"`
+	}
+	_ = json.Unmarshal([]byte(entry.Text), &text)
+	return text.Description, text.Fields
+}
+
+// missingFrom returns the fields of candidates that base lacks, in
+// candidate order
+func missingFrom(base, candidates []string) []string {
+	var missing []string
+	for _, field := range candidates {
+		if !slices.Contains(base, field) && !slices.Contains(missing, field) {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
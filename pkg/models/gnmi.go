@@ -0,0 +1,71 @@
+// Package models translates EQL table keys into the gNMI paths that address
+// the same data on a device.
+package models
+
+import "strings"
+
+// gnmiListKeys names the key of well-known YANG lists per platform. An entry
+// of the form "parent.list" takes precedence over a bare "list", since lists
+// such as neighbor are keyed differently depending on where they live.
+var gnmiListKeys = map[EmbeddingType]map[string]string{
+	SRL: {
+		"interface":                   "name",
+		"subinterface":                "index",
+		"network-instance":            "name",
+		"bgp.neighbor":                "peer-address",
+		"bgp.group":                   "group-name",
+		"arp.neighbor":                "ipv4-address",
+		"neighbor-discovery.neighbor": "ipv6-address",
+		"afi-safi":                    "afi-safi-name",
+		"channel":                     "index",
+		"lldp.interface.neighbor":     "id",
+		"ipv4-filter":                 "name",
+		"ipv6-filter":                 "name",
+		"ipv4-filter.entry":           "sequence-id",
+		"ipv6-filter.entry":           "sequence-id",
+	},
+	SROS: {
+		"port":         "port-id",
+		"router":       "router-name",
+		"interface":    "interface-name",
+		"bgp.neighbor": "ip-address",
+		"bgp.group":    "group-name",
+		"card":         "slot-number",
+		"mda":          "mda-slot",
+		"vprn":         "service-name",
+		"vpls":         "service-name",
+	},
+}
+
+// EQLTableToGNMIPath converts a node table key into a gNMI path with
+// wildcard list keys, e.g. ".namespace.node.srl.interface.statistics"
+// becomes "/interface[name=*]/statistics". Tables outside a node, which have
+// no device path, yield "".
+func EQLTableToGNMIPath(table string) string {
+	platform, ok := PlatformFromTable(table)
+	if !ok || !strings.HasPrefix(table, ".namespace.node.") {
+		return ""
+	}
+
+	segments := strings.Split(strings.TrimPrefix(SchemaPath(table), "/"), "/")
+	var b strings.Builder
+	for i, segment := range segments {
+		b.WriteString("/" + segment)
+		if key := gnmiListKey(platform, segments[:i+1]); key != "" {
+			b.WriteString("[" + key + "=*]")
+		}
+	}
+	return b.String()
+}
+
+// gnmiListKey returns the key of the list ending path, trying the longest
+// known parent context first
+func gnmiListKey(platform EmbeddingType, path []string) string {
+	keys := gnmiListKeys[platform]
+	for start := 0; start < len(path); start++ {
+		if key, ok := keys[strings.Join(path[start:], ".")]; ok {
+			return key
+		}
+	}
+	return ""
+}
@@ -16,8 +16,42 @@ type EmbeddingEntry struct {
 
 // EmbeddingDB represents the database of embeddings
 type EmbeddingDB struct {
-	Table         map[string]EmbeddingEntry `json:"Table"`
-	InvertedIndex map[string][]string       `json:"-"` // word -> list of keys containing that word
+	Table            map[string]EmbeddingEntry `json:"Table"`
+	InvertedIndex    map[string][]string       `json:"-"` // word -> list of keys containing that word
+	DescriptionIndex map[string][]string       `json:"-"` // description word -> list of keys whose description has it
+	Name             string                    `json:"-"` // origin label, e.g. the file the DB was loaded from
+}
+
+// NewEmbeddingDB wraps a programmatically built table in a database. It is
+// the supported entry point for tests and for embedding the engine with a
+// custom corpus. The indexes are left empty; search.Engine builds them on
+// first use, or embedding.FromTable builds them up front.
+func NewEmbeddingDB(table map[string]EmbeddingEntry) *EmbeddingDB {
+	if table == nil {
+		table = make(map[string]EmbeddingEntry)
+	}
+	return &EmbeddingDB{Table: table, Name: "memory"}
+}
+
+// NewEmbeddingEntry builds an entry whose Text carries the description and
+// fields in the JSON shape the published embeddings use
+func NewEmbeddingEntry(referenceText, description string, fields []string) EmbeddingEntry {
+	if fields == nil {
+		fields = []string{}
+	}
+	text, _ := json.Marshal(struct {
+		Description string   `json:"Description"`
+		Fields      []string `json:"Fields"`
+	}{description, fields})
+
+	return EmbeddingEntry{ReferenceText: referenceText, Text: string(text)}
+}
+
+// Description returns the table description carried in the entry's Text, or
+// "" when Text cannot be parsed
+func (e EmbeddingEntry) Description() string {
+	description, _ := entryText(e)
+	return description
 }
 
 // EQLQuery represents an EQL query with all its components
@@ -46,45 +80,80 @@ type DeltaClause struct {
 // SearchResult represents a search result with scoring and EQL query
 type SearchResult struct {
 	Key             string
-	Score           float64
+	Score           float64 // raw heuristic score
+	NormalizedScore float64 // score relative to the top result, in the range 0-1
 	EQLQuery        EQLQuery
 	Description     string
 	AvailableFields []string
 	Explanation     string
+	Source          string      // name of the database the result came from
+	Comparison      []NodeQuery // one query per node when the query compares nodes
+	FleetWide       bool        // the query asked for every node, so no node filter applies
+	ReferenceText   string      // text the entry was indexed from, set only on request
 }
 
-// MarshalJSON customizes the JSON output for SearchResult
-func (sr *SearchResult) MarshalJSON() ([]byte, error) {
-	// Create a custom struct that matches the desired JSON format
-	type jsonResult struct {
-		Score           float64  `json:"score"`
-		Query           string   `json:"query"`
-		Table           string   `json:"table"`
-		Description     string   `json:"description,omitempty"`
-		AvailableFields []string `json:"availableFields,omitempty"`
-		Fields          []string `json:"fields,omitempty"`
-		Where           string   `json:"where,omitempty"`
-		OrderBy         []struct {
-			Field     string `json:"field"`
-			Direction string `json:"direction"`
-			Algorithm string `json:"algorithm,omitempty"`
-		} `json:"orderBy,omitempty"`
-		Limit int `json:"limit,omitempty"`
-		Delta *struct {
-			Unit  string `json:"unit"`
-			Value int    `json:"value"`
-		} `json:"delta,omitempty"`
-	}
+// NodeQuery is the query for one node of a comparison
+type NodeQuery struct {
+	Node     string
+	EQLQuery EQLQuery
+}
 
+// jsonResult is the JSON shape of a SearchResult. JSONSchema describes the
+// same struct, so the schema follows any change made here.
+type jsonResult struct {
+	Score           float64  `json:"score"`
+	NormalizedScore float64  `json:"normalizedScore"`
+	Query           string   `json:"query"`
+	Table           string   `json:"table"`
+	Description     string   `json:"description,omitempty"`
+	AvailableFields []string `json:"availableFields,omitempty"`
+	Fields          []string `json:"fields,omitempty"`
+	Where           string   `json:"where,omitempty"`
+	Source          string   `json:"source,omitempty"`
+	Platform        string   `json:"platform,omitempty"`
+	SchemaPath      string   `json:"schemaPath,omitempty"`
+	GNMIPath        string   `json:"gnmiPath,omitempty"`
+	OrderBy         []struct {
+		Field     string `json:"field"`
+		Direction string `json:"direction"`
+		Algorithm string `json:"algorithm,omitempty"`
+	} `json:"orderBy,omitempty"`
+	Limit int `json:"limit,omitempty"`
+	Delta *struct {
+		Unit  string `json:"unit"`
+		Value int    `json:"value"`
+	} `json:"delta,omitempty"`
+	Comparison []struct {
+		Node  string `json:"node"`
+		Query string `json:"query"`
+		Where string `json:"where,omitempty"`
+	} `json:"comparison,omitempty"`
+	FleetWide     bool   `json:"fleetWide,omitempty"`
+	ReferenceText string `json:"referenceText,omitempty"`
+}
+
+// MarshalJSON customizes the JSON output for SearchResult. It has a value
+// receiver so results are encoded the same whether held by value or pointer.
+func (sr SearchResult) MarshalJSON() ([]byte, error) {
 	result := jsonResult{
 		Score:           sr.Score,
+		NormalizedScore: sr.NormalizedScore,
 		Query:           sr.EQLQuery.String(),
 		Table:           sr.EQLQuery.Table,
 		Description:     sr.Description,
 		AvailableFields: sr.AvailableFields,
 		Fields:          sr.EQLQuery.Fields,
 		Where:           sr.EQLQuery.WhereClause,
+		Source:          sr.Source,
+		SchemaPath:      SchemaPath(sr.Key),
+		GNMIPath:        EQLTableToGNMIPath(sr.Key),
 		Limit:           sr.EQLQuery.Limit,
+		FleetWide:       sr.FleetWide,
+		ReferenceText:   sr.ReferenceText,
+	}
+
+	if platform, ok := PlatformFromTable(sr.Key); ok {
+		result.Platform = platform.String()
 	}
 
 	// Convert OrderBy
@@ -113,6 +182,21 @@ func (sr *SearchResult) MarshalJSON() ([]byte, error) {
 		}
 	}
 
+	// Convert per-node comparison queries
+	if len(sr.Comparison) > 0 {
+		result.Comparison = make([]struct {
+			Node  string `json:"node"`
+			Query string `json:"query"`
+			Where string `json:"where,omitempty"`
+		}, len(sr.Comparison))
+
+		for i, nq := range sr.Comparison {
+			result.Comparison[i].Node = nq.Node
+			result.Comparison[i].Query = nq.EQLQuery.String()
+			result.Comparison[i].Where = nq.EQLQuery.WhereClause
+		}
+	}
+
 	return json.Marshal(result)
 }
 
@@ -124,16 +208,82 @@ const (
 	SROS
 )
 
+// PlatformFromTable detects the platform a table path belongs to. The boolean
+// is false when the path carries no platform segment.
+func PlatformFromTable(tablePath string) (EmbeddingType, bool) {
+	switch {
+	case strings.Contains(tablePath, ".sros."):
+		return SROS, true
+	case strings.Contains(tablePath, ".srl."):
+		return SRL, true
+	default:
+		return SRL, false
+	}
+}
+
+// String returns the lowercase platform name used on the command line
+func (t EmbeddingType) String() string {
+	switch t {
+	case SRL:
+		return "srl"
+	case SROS:
+		return "sros"
+	default:
+		return fmt.Sprintf("EmbeddingType(%d)", int(t))
+	}
+}
+
+// SchemaPath turns a table key into a slash-separated schema path without
+// the namespace and node prefixes, e.g. ".namespace.node.srl.interface.statistics"
+// becomes "/interface/statistics". It returns "" for an empty key.
+func SchemaPath(tablePath string) string {
+	path := strings.Trim(tablePath, ".")
+	if path == "" {
+		return ""
+	}
+
+	for _, prefix := range []string{"namespace.node.srl.", "namespace.node.sros.", "namespace."} {
+		if strings.HasPrefix(path, prefix) {
+			path = strings.TrimPrefix(path, prefix)
+			break
+		}
+	}
+	return "/" + strings.ReplaceAll(path, ".", "/")
+}
+
 // String returns the string representation of an EQL query
 func (q *EQLQuery) String() string {
-	query := q.Table
+	return q.Render(nil)
+}
+
+// Clause identifies a part of a rendered EQL query
+type Clause int
+
+// Clauses in the order they are rendered
+const (
+	ClauseTable Clause = iota
+	ClauseFields
+	ClauseWhere
+	ClauseOrderBy
+	ClauseLimit
+	ClauseDelta
+)
+
+// Render builds the query string like String, passing each clause's text
+// through decorate, e.g. to colorize it. A nil decorate leaves text as is.
+func (q *EQLQuery) Render(decorate func(clause Clause, text string) string) string {
+	if decorate == nil {
+		decorate = func(_ Clause, text string) string { return text }
+	}
+
+	query := decorate(ClauseTable, q.Table)
 
 	if len(q.Fields) > 0 {
-		query += fmt.Sprintf(" fields [%s]", strings.Join(q.Fields, ", "))
+		query += " " + decorate(ClauseFields, fmt.Sprintf("fields [%s]", strings.Join(q.Fields, ", ")))
 	}
 
 	if q.WhereClause != "" {
-		query += " where (" + q.WhereClause + ")"
+		query += " " + decorate(ClauseWhere, "where ("+q.WhereClause+")")
 	}
 
 	if len(q.OrderBy) > 0 {
@@ -145,15 +295,15 @@ func (q *EQLQuery) String() string {
 			}
 			orderParts = append(orderParts, part)
 		}
-		query += fmt.Sprintf(" order by [%s]", strings.Join(orderParts, ", "))
+		query += " " + decorate(ClauseOrderBy, fmt.Sprintf("order by [%s]", strings.Join(orderParts, ", ")))
 	}
 
 	if q.Limit > 0 {
-		query += fmt.Sprintf(" limit %d", q.Limit)
+		query += " " + decorate(ClauseLimit, fmt.Sprintf("limit %d", q.Limit))
 	}
 
 	if q.Delta != nil {
-		query += fmt.Sprintf(" delta %s %d", q.Delta.Unit, q.Delta.Value)
+		query += " " + decorate(ClauseDelta, fmt.Sprintf("delta %s %d", q.Delta.Unit, q.Delta.Value))
 	}
 
 	return query
@@ -0,0 +1,38 @@
+// Package models rewrites the namespace prefix of EQL paths for API
+// contexts that address a concrete namespace or relative paths.
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// NamespacePrefix starts every table path the embeddings describe
+const NamespacePrefix = ".namespace."
+
+// WithNamespace returns a copy of the query whose paths have NamespacePrefix
+// replaced by the given namespace, e.g. ".eda.node.srl.interface" for "eda".
+// The table, fields, where clause and order by are all rewritten. An empty
+// namespace strips the prefix instead, leaving ".node.srl.interface".
+func (q *EQLQuery) WithNamespace(namespace string) EQLQuery {
+	replacement := "."
+	if namespace != "" {
+		replacement = "." + namespace + "."
+	}
+	rewrite := func(s string) string {
+		return strings.ReplaceAll(s, NamespacePrefix, replacement)
+	}
+
+	out := *q
+	out.Table = rewrite(q.Table)
+	out.WhereClause = rewrite(q.WhereClause)
+	out.Fields = slices.Clone(q.Fields)
+	for i, field := range out.Fields {
+		out.Fields[i] = rewrite(field)
+	}
+	out.OrderBy = slices.Clone(q.OrderBy)
+	for i := range out.OrderBy {
+		out.OrderBy[i].Field = rewrite(out.OrderBy[i].Field)
+	}
+	return out
+}
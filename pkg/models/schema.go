@@ -0,0 +1,94 @@
+// Package models derives a JSON Schema from the structs behind the JSON
+// output, so the published contract cannot drift from the marshaller.
+package models
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect JSONSchema produces
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonShaper is implemented by types whose JSON is produced from another
+// struct by a custom MarshalJSON
+type jsonShaper interface {
+	jsonShape() any
+}
+
+func (sr SearchResult) jsonShape() any {
+	return jsonResult{}
+}
+
+var shaperType = reflect.TypeFor[jsonShaper]()
+
+// JSONSchema describes how values of type t are encoded by encoding/json.
+// Struct fields are named by their json tags and are required unless tagged
+// omitempty; SearchResult is described by the shape its MarshalJSON emits.
+func JSONSchema(t reflect.Type) map[string]any {
+	if reflect.PointerTo(t).Implements(shaperType) {
+		shape := reflect.New(t).Interface().(jsonShaper).jsonShape()
+		return JSONSchema(reflect.TypeOf(shape))
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return JSONSchema(t.Elem())
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": JSONSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": JSONSchema(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	required := []string{}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, omitEmpty, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		properties[name] = JSONSchema(field.Type)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// jsonFieldName returns the name encoding/json gives an exported field and
+// whether it is omitted when empty; ok is false for skipped fields
+func jsonFieldName(field reflect.StructField) (name string, omitEmpty, ok bool) {
+	tag := field.Tag.Get("json")
+	if !field.IsExported() || tag == "-" {
+		return "", false, false
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, strings.Contains(options, "omitempty"), true
+}
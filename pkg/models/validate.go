@@ -0,0 +1,149 @@
+// Package models provides sanity checks for loaded embedding databases and
+// generated EQL queries.
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+)
+
+// ValidationError summarizes the problems Validate found in a database
+type ValidationError struct {
+	EmptyTable    bool
+	EmptyKeys     int // entries stored under an empty key
+	InvalidText   int // entries whose Text is not a JSON object
+	MissingFields int // entries whose Text has no Fields array
+	Malformed     int // distinct entries with at least one problem
+}
+
+func (e *ValidationError) Error() string {
+	if e.EmptyTable {
+		return "embedding table is empty"
+	}
+
+	var problems []string
+	if e.EmptyKeys > 0 {
+		problems = append(problems, fmt.Sprintf("%d with empty keys", e.EmptyKeys))
+	}
+	if e.InvalidText > 0 {
+		problems = append(problems, fmt.Sprintf("%d with unparseable Text", e.InvalidText))
+	}
+	if e.MissingFields > 0 {
+		problems = append(problems, fmt.Sprintf("%d without a Fields array", e.MissingFields))
+	}
+	return fmt.Sprintf("%d malformed entries: %s", e.Malformed, strings.Join(problems, ", "))
+}
+
+// Validate checks that the table is non-empty, that no entry has an empty
+// key and that every entry's Text is a JSON object with a Fields array. It
+// returns a *ValidationError describing any problems found.
+func (db *EmbeddingDB) Validate() error {
+	if len(db.Table) == 0 {
+		return &ValidationError{EmptyTable: true}
+	}
+
+	result := &ValidationError{}
+	for key, entry := range db.Table {
+		malformed := false
+		if strings.TrimSpace(key) == "" {
+			result.EmptyKeys++
+			malformed = true
+		}
+
+		var text struct {
+			Fields json.RawMessage `json:"Fields"`
+		}
+		switch {
+		case json.Unmarshal([]byte(entry.Text), &text) != nil:
+			result.InvalidText++
+			malformed = true
+		case !isJSONArray(text.Fields):
+			result.MissingFields++
+			malformed = true
+		}
+
+		if malformed {
+			result.Malformed++
+		}
+	}
+
+	if result.Malformed > 0 {
+		return result
+	}
+	return nil
+}
+
+func isJSONArray(raw json.RawMessage) bool {
+	trimmed := strings.TrimSpace(string(raw))
+	return strings.HasPrefix(trimmed, "[")
+}
+
+// Problems reported by EQLQuery.Validate, for use with errors.Is
+var (
+	ErrNoTable      = errors.New("query has no table")
+	ErrUnknownField = errors.New("field is not available on the table")
+	ErrInvalidLimit = errors.New("limit is out of range")
+	ErrInvalidDelta = errors.New("delta must be positive")
+)
+
+var (
+	// quotedValuePattern matches string literals, which may contain text
+	// that looks like a condition
+	quotedValuePattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+	// conditionFieldPattern captures the field on the left of each
+	// comparison in a WHERE clause
+	conditionFieldPattern = regexp.MustCompile(`(?:^|\(|\band\s|\bor\s|\bnot\s)\s*([\w.-]+)\s*(?:!=|>=|<=|=|>|<|~|\bin\b)`)
+)
+
+// Validate reports problems that would make EDA reject the query or return
+// something other than intended: a missing table, WHERE or ORDER BY fields
+// absent from availableFields, a limit outside 1 to constants.MaxLimitValue
+// and a non-positive delta. Fully qualified fields such as
+// .namespace.node.name refer to parent tables and are not checked, and
+// field checks are skipped when availableFields is empty.
+func (q *EQLQuery) Validate(availableFields []string) []error {
+	var problems []error
+	if strings.TrimSpace(q.Table) == "" {
+		problems = append(problems, ErrNoTable)
+	}
+
+	if len(availableFields) > 0 {
+		for _, field := range WhereFields(q.WhereClause) {
+			if !strings.HasPrefix(field, ".") && !slices.Contains(availableFields, field) {
+				problems = append(problems, fmt.Errorf("where clause references %q: %w", field, ErrUnknownField))
+			}
+		}
+		for _, ob := range q.OrderBy {
+			if !slices.Contains(availableFields, ob.Field) {
+				problems = append(problems, fmt.Errorf("order by references %q: %w", ob.Field, ErrUnknownField))
+			}
+		}
+	}
+
+	if q.Limit < 0 || q.Limit > constants.MaxLimitValue {
+		problems = append(problems, fmt.Errorf("limit %d, maximum %d: %w", q.Limit, constants.MaxLimitValue, ErrInvalidLimit))
+	}
+	if q.Delta != nil && q.Delta.Value <= 0 {
+		problems = append(problems, fmt.Errorf("delta %s %d: %w", q.Delta.Unit, q.Delta.Value, ErrInvalidDelta))
+	}
+	return problems
+}
+
+// WhereFields returns the fields compared in a WHERE clause, in order of
+// appearance and without repeats
+func WhereFields(where string) []string {
+	var fields []string
+	for _, match := range conditionFieldPattern.FindAllStringSubmatch(quotedValuePattern.ReplaceAllString(where, `""`), -1) {
+		if !slices.Contains(fields, match[1]) {
+			fields = append(fields, match[1])
+		}
+	}
+	return fields
+}
@@ -0,0 +1,152 @@
+// Package test contains behavioral tests for embeddings release selection.
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/download"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+func TestFindRelease(t *testing.T) {
+	for _, platform := range []models.EmbeddingType{models.SRL, models.SROS} {
+		newest, err := download.FindRelease(platform, "")
+		if err != nil {
+			t.Fatalf("FindRelease(%s, \"\") = %v", platform, err)
+		}
+		if newest.Platform != platform || !strings.Contains(newest.FileName, platform.String()) {
+			t.Errorf("newest %s release = %+v", platform, newest)
+		}
+
+		byVersion, err := download.FindRelease(platform, strings.ToUpper(newest.Version))
+		if err != nil || byVersion != newest {
+			t.Errorf("FindRelease(%s, %q) = %+v, %v", platform, newest.Version, byVersion, err)
+		}
+	}
+
+	if _, err := download.FindRelease(models.SRL, "1.0.0"); err == nil || !strings.Contains(err.Error(), "25.3.3") {
+		t.Errorf("unknown version error = %v, want it to list known versions", err)
+	}
+}
+
+func TestReleaseFilesAreDistinct(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, release := range download.Releases() {
+		if seen[release.FileName] {
+			t.Errorf("file name %s is shared by several releases", release.FileName)
+		}
+		seen[release.FileName] = true
+	}
+}
+
+func TestDescribeAll(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	downloader := download.NewDownloader()
+	srl, err := downloader.Describe(models.SRL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(srl.Dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(srl.Path, []byte(`{"Table":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sources := downloader.DescribeAll()
+	if len(sources) != len(download.Releases()) {
+		t.Fatalf("DescribeAll returned %d sources, want %d", len(sources), len(download.Releases()))
+	}
+	for _, source := range sources {
+		want := source.Path == srl.Path
+		if source.Present != want {
+			t.Errorf("%s %s present = %v, want %v", source.Platform, source.Version, source.Present, want)
+		}
+		if want && (source.Size != 12 || source.ModTime.IsZero()) {
+			t.Errorf("downloaded source = %+v, want size and mtime", source)
+		}
+	}
+}
+
+func TestEmbeddingsDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(download.EmbeddingsDirEnv, "")
+
+	if dir := download.DefaultEmbeddingsDir(); dir != filepath.Join(home, ".eda", "vscode", "embeddings") {
+		t.Errorf("default embeddings dir = %s, want it under %s", dir, home)
+	}
+
+	envDir := t.TempDir()
+	t.Setenv(download.EmbeddingsDirEnv, envDir)
+	source, err := download.NewDownloader().Describe(models.SRL, "")
+	if err != nil || source.Dir != envDir || filepath.Dir(source.Path) != envDir {
+		t.Errorf("with %s set, source = %+v, %v", download.EmbeddingsDirEnv, source, err)
+	}
+
+	flagDir := t.TempDir()
+	source, err = download.NewDownloaderWithDir(flagDir).Describe(models.SROS, "")
+	if err != nil || source.Dir != flagDir {
+		t.Errorf("explicit dir source = %+v, %v, want dir %s", source, err, flagDir)
+	}
+	if path := download.NewDownloaderWithDir(flagDir).GetEmbeddingPath(models.SRL); filepath.Dir(path) != flagDir {
+		t.Errorf("GetEmbeddingPath = %s, want it in %s", path, flagDir)
+	}
+}
+
+func TestEmbeddingsDirWithoutHome(t *testing.T) {
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+	t.Setenv(download.EmbeddingsDirEnv, "")
+
+	dir := download.DefaultEmbeddingsDir()
+	if !filepath.IsAbs(dir) || strings.Contains(dir, "~") {
+		t.Errorf("embeddings dir without a home = %q, want an absolute path", dir)
+	}
+
+	t.Setenv(download.EmbeddingsDirEnv, "~/embeddings")
+	if dir := download.DefaultEmbeddingsDir(); !filepath.IsAbs(dir) || strings.Contains(dir, "~") {
+		t.Errorf("embeddings dir from ~/embeddings = %q, want it expanded", dir)
+	}
+}
+
+func TestEmbeddingsDirTilde(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	source, err := download.NewDownloaderWithDir("~/embeddings").Describe(models.SRL, "")
+	if err != nil || source.Dir != filepath.Join(home, "embeddings") {
+		t.Errorf("source for ~/embeddings = %+v, %v, want dir under %s", source, err, home)
+	}
+}
+
+func TestEnsureEmbeddingsContextCanceled(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	downloader := download.NewDownloaderWithDir(dir)
+	if _, err := downloader.EnsureEmbeddingsContext(ctx, models.SRL, ""); err == nil {
+		t.Fatal("EnsureEmbeddingsContext with a canceled context succeeded")
+	}
+	if _, err := os.Stat(downloader.GetEmbeddingPath(models.SRL)); !os.IsNotExist(err) {
+		t.Errorf("canceled download left %s behind (stat error %v)", downloader.GetEmbeddingPath(models.SRL), err)
+	}
+
+	// An existing database needs no download, so the context does not matter
+	if err := os.WriteFile(downloader.GetEmbeddingPath(models.SRL), []byte(`{"Table":{}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloader.EnsureEmbeddingsContext(ctx, models.SRL, ""); err != nil {
+		t.Errorf("EnsureEmbeddingsContext with the database present = %v", err)
+	}
+}
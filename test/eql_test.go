@@ -0,0 +1,1382 @@
+// Package test contains unit tests for EQL generation helpers.
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/internal/eql"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+func TestFormatCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		value    string
+		expected string
+	}{
+		{
+			name:     "plain string",
+			field:    "oper-state",
+			value:    "up",
+			expected: `oper-state = "up"`,
+		},
+		{
+			name:     "embedded double quote",
+			field:    "description",
+			value:    `link to "spine1"`,
+			expected: `description = "link to \"spine1\""`,
+		},
+		{
+			name:     "embedded backslash",
+			field:    "description",
+			value:    `C:\configs`,
+			expected: `description = "C:\\configs"`,
+		},
+		{
+			name:     "backslash before quote",
+			field:    "description",
+			value:    `a\"b`,
+			expected: `description = "a\\\"b"`,
+		},
+		{
+			name:     "numeric operator passes through",
+			field:    "in-octets",
+			value:    "> 100",
+			expected: "in-octets > 100",
+		},
+		{
+			name:     "regex operator passes through",
+			field:    "ethernet-pmd",
+			value:    `~ "BASE-T"`,
+			expected: `ethernet-pmd ~ "BASE-T"`,
+		},
+		{
+			name:     "negated regex passes through",
+			field:    "ethernet-pmd",
+			value:    `!~ "BASE-T"`,
+			expected: `ethernet-pmd !~ "BASE-T"`,
+		},
+		{
+			name:     "set membership passes through",
+			field:    "port-speed",
+			value:    `in ["100G", "400G"]`,
+			expected: `port-speed in ["100G", "400G"]`,
+		},
+		{
+			name:     "leading bang is not an operator",
+			field:    "description",
+			value:    "!important",
+			expected: `description = "!important"`,
+		},
+		{
+			name:     "null check passes through",
+			field:    "vendor",
+			value:    "!= null",
+			expected: "vendor != null",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eql.FormatCondition(tt.field, tt.value); got != tt.expected {
+				t.Errorf("FormatCondition(%q, %q) = %s, want %s", tt.field, tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQuoteString(t *testing.T) {
+	tests := map[string]string{
+		"leaf1":     `"leaf1"`,
+		`say "hi"`:  `"say \"hi\""`,
+		`back\path`: `"back\\path"`,
+		"":          `""`,
+	}
+
+	for input, expected := range tests {
+		if got := eql.QuoteString(input); got != expected {
+			t.Errorf("QuoteString(%q) = %s, want %s", input, got, expected)
+		}
+	}
+}
+
+func TestEthernetPMDConditions(t *testing.T) {
+	const table = ".namespace.node.srl.interface.transceiver"
+	fields := []string{"form-factor", "vendor", "ethernet-pmd"}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"copper transceivers", `ethernet-pmd ~ "BASE-T"`},
+		{"electrical transceivers", `ethernet-pmd ~ "BASE-T"`},
+		{"fiber transceivers", `ethernet-pmd !~ "BASE-T"`},
+		{"optical transceivers", `ethernet-pmd !~ "BASE-T"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := eql.GenerateWhereClauseWithValidation(table, tt.query, fields)
+			if got != tt.expected {
+				t.Errorf("where clause for %q = %s, want %s", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractExplicitFields(t *testing.T) {
+	available := []string{"name", "admin-state", "oper-state", "mtu", "description"}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"show name, oper-state, mtu for interfaces", []string{"name", "oper-state", "mtu"}},
+		{"get mtu and description on leaf1", []string{"mtu", "description"}},
+		{"show name, oper-state and mtu", []string{"name", "oper-state", "mtu"}},
+		{"fields: admin-state", []string{"admin-state"}},
+		{"show name, bogus-field for interfaces", []string{"name"}},
+		{"show interfaces that are down", nil},
+		{"show mtu for interfaces", nil},
+		{"show foo, bar", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := eql.ExtractExplicitFields(tt.query, available)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("ExtractExplicitFields(%q) = %v, want %v", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractFieldsRankingAndCap(t *testing.T) {
+	entry := &models.EmbeddingEntry{
+		Text: `{"Description":"Interface statistics","Fields":["in-octets","out-octets","in-packets","out-packets","in-error-packets","out-error-packets"]}`,
+	}
+	const table = ".namespace.node.srl.interface.statistics"
+	const query = "show errors traffic packets"
+
+	all := eql.ExtractFieldsWithLimit(query, table, entry, 0)
+	expected := []string{"in-error-packets", "out-error-packets", "in-octets", "out-octets", "in-packets", "out-packets"}
+	if !slices.Equal(all, expected) {
+		t.Fatalf("uncapped fields = %v, want %v", all, expected)
+	}
+
+	capped := eql.ExtractFields(query, table, entry)
+	if len(capped) != constants.MaxExtractedFields {
+		t.Fatalf("capped fields = %v, want %d entries", capped, constants.MaxExtractedFields)
+	}
+	if !slices.Equal(capped, expected[:len(capped)]) {
+		t.Errorf("capped fields = %v, want prefix of %v", capped, expected)
+	}
+}
+
+func TestWantsAllFields(t *testing.T) {
+	tests := map[string]bool{
+		"show all fields for interface statistics": true,
+		"every field of bgp neighbors":             true,
+		"show everything for interfaces":           true,
+		"list all the columns of the route table":  true,
+		"show all interfaces":                      false,
+		"show interface state":                     false,
+	}
+
+	for query, expected := range tests {
+		if got := eql.WantsAllFields(query); got != expected {
+			t.Errorf("WantsAllFields(%q) = %v, want %v", query, got, expected)
+		}
+	}
+
+	entry := &models.EmbeddingEntry{
+		Text: `{"Description":"Interfaces","Fields":["name","admin-state","oper-state"]}`,
+	}
+	if fields := eql.ExtractFields("show all fields for interface state", ".namespace.node.srl.interface", entry); len(fields) != 0 {
+		t.Errorf("expected no field projection, got %v", fields)
+	}
+}
+
+func TestExtractInterfaceNames(t *testing.T) {
+	tests := []struct {
+		query    string
+		platform models.EmbeddingType
+		expected []string
+	}{
+		{"show ethernet-1/1", models.SRL, []string{"ethernet-1/1"}},
+		{"stats for eth1/1 and e1-2", models.SRL, []string{"ethernet-1/1", "ethernet-1/2"}},
+		{"Ethernet1/3/1 breakout", models.SRL, []string{"ethernet-1/3/1"}},
+		{"interface 1/5 state", models.SRL, []string{"ethernet-1/5"}},
+		{"port 1/1/1 speed", models.SROS, []string{"1/1/1"}},
+		{"port 1/1/c2/1", models.SROS, []string{"1/1/c2/1"}},
+		{"port 1/1/1 speed", models.SRL, nil},
+		{"show interfaces", models.SRL, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := eql.ExtractInterfaceNames(tt.query, tt.platform)
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("ExtractInterfaceNames(%q) = %v, want %v", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInterfaceNameConditions(t *testing.T) {
+	tests := []struct {
+		table    string
+		query    string
+		fields   []string
+		expected string
+	}{
+		{
+			table:    ".namespace.node.srl.interface",
+			query:    "show eth1/1",
+			fields:   []string{"name", "oper-state"},
+			expected: `name = "ethernet-1/1"`,
+		},
+		{
+			table:    ".namespace.node.srl.interface.statistics",
+			query:    "statistics on ethernet-1/1",
+			fields:   []string{"in-octets", "out-octets"},
+			expected: `.namespace.node.srl.interface.name = "ethernet-1/1"`,
+		},
+		{
+			table:    ".namespace.node.sros.state.port",
+			query:    "show port 1/1/1",
+			fields:   []string{"port-id", "oper-state"},
+			expected: `port-id = "1/1/1"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := eql.GenerateWhereClauseWithValidation(tt.table, tt.query, tt.fields)
+			if got != tt.expected {
+				t.Errorf("where clause for %q = %s, want %s", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractDelta(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *models.DeltaClause
+	}{
+		{"stream interface stats every 5 seconds", &models.DeltaClause{Unit: "seconds", Value: 5}},
+		{"cpu every 250 milliseconds", &models.DeltaClause{Unit: "milliseconds", Value: 250}},
+		{"cpu every 250ms", &models.DeltaClause{Unit: "milliseconds", Value: 250}},
+		{"queue depth every 500 microseconds", &models.DeltaClause{Unit: "microseconds", Value: 500}},
+		{"queue depth every 500us", &models.DeltaClause{Unit: "microseconds", Value: 500}},
+		{"queue depth every 10 microseconds", &models.DeltaClause{Unit: "microseconds", Value: constants.MinDeltaMicroseconds}},
+		{"real-time cpu", &models.DeltaClause{Unit: "seconds", Value: constants.RealTimeIntervalSeconds}},
+		{"show cpu", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := eql.ExtractDelta(tt.query)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ExtractDelta(%q) = %+v, want %+v", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractOrderByField(t *testing.T) {
+	entry := &models.EmbeddingEntry{
+		Text: `{"Description":"Interface statistics","Fields":["in-octets","out-octets","in-discards","out-discards","carrier-transitions"]}`,
+	}
+	const table = ".namespace.node.srl.interface.statistics"
+
+	tests := []struct {
+		query     string
+		field     string
+		direction string
+	}{
+		{"top 10 interfaces by out-discards", "out-discards", "descending"},
+		{"top 10 interfaces by out discards", "out-discards", "descending"},
+		{"highest 5 by carrier transitions", "carrier-transitions", "descending"},
+		{"top 5 interfaces by traffic", "in-octets", "descending"},
+		{"lowest 3 interfaces by in-discards", "in-discards", "ascending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			orderBy := eql.ExtractOrderBy(tt.query, table, entry)
+			if len(orderBy) == 0 {
+				t.Fatalf("no order by extracted for %q", tt.query)
+			}
+			if orderBy[0].Field != tt.field || orderBy[0].Direction != tt.direction {
+				t.Errorf("order by = %s %s, want %s %s", orderBy[0].Field, orderBy[0].Direction, tt.field, tt.direction)
+			}
+		})
+	}
+}
+
+func TestExtractLimitDefaults(t *testing.T) {
+	const (
+		interfaces = ".namespace.node.srl.interface"
+		alarms     = ".namespace.alarms.v1.alarm"
+	)
+
+	tests := []struct {
+		query string
+		table string
+		limit int
+	}{
+		{"show interfaces", interfaces, 0},
+		{"top interfaces", interfaces, constants.DefaultTopLimit},
+		{"interfaces with the highest mtu", interfaces, constants.DefaultTopLimit},
+		{"top 3 interfaces", interfaces, 3},
+		{"list all interfaces", interfaces, 0},
+		{"show all interfaces with the highest mtu", interfaces, 0},
+		{"show network topology", interfaces, 0},
+		{"recent alarms", alarms, constants.DefaultRecentLimit},
+		{"latest critical alarms", alarms, constants.DefaultRecentLimit},
+		{"first 5 recent alarms", alarms, 5},
+		{"recent interface changes", interfaces, 0},
+	}
+
+	for _, tt := range tests {
+		if got := eql.ExtractLimit(tt.query, tt.table); got != tt.limit {
+			t.Errorf("ExtractLimit(%q, %s) = %d, want %d", tt.query, tt.table, got, tt.limit)
+		}
+	}
+}
+
+func TestOrdinalRank(t *testing.T) {
+	entry := &models.EmbeddingEntry{
+		Text: `{"Description":"Interface statistics","Fields":["in-octets","in-error-packets","out-error-packets"]}`,
+	}
+	const table = ".namespace.node.srl.interface.statistics"
+
+	tests := []struct {
+		query     string
+		limit     int
+		field     string
+		direction string
+	}{
+		{"interface with the second highest traffic", 2, "in-octets", "descending"},
+		{"interfaces with the third most errors", 3, "in-error-packets", "descending"},
+		{"4th lowest traffic", 4, "in-octets", "ascending"},
+		{"top 5 interfaces by traffic", 5, "in-octets", "descending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if limit := eql.ExtractLimit(tt.query, table); limit != tt.limit {
+				t.Errorf("limit = %d, want %d", limit, tt.limit)
+			}
+			orderBy := eql.ExtractOrderBy(tt.query, table, entry)
+			if len(orderBy) == 0 || orderBy[0].Field != tt.field || orderBy[0].Direction != tt.direction {
+				t.Errorf("order by = %+v, want %s %s", orderBy, tt.field, tt.direction)
+			}
+		})
+	}
+
+	for query, expected := range map[string]int{
+		"second highest cpu": 2,
+		"the 3rd most drops": 3,
+		"second interface":   0,
+		"highest cpu":        0,
+	} {
+		if got := eql.ExtractOrdinal(query); got != expected {
+			t.Errorf("ExtractOrdinal(%q) = %d, want %d", query, got, expected)
+		}
+	}
+}
+
+func TestExtractOrderByExplicitDirection(t *testing.T) {
+	entry := &models.EmbeddingEntry{
+		Text: `{"Description":"Interfaces","Fields":["name","mtu","oper-state"]}`,
+	}
+	const table = ".namespace.node.srl.interface"
+
+	tests := []struct {
+		query    string
+		expected models.OrderByClause
+	}{
+		{"sort interfaces by name descending", models.OrderByClause{Field: "name", Direction: "descending", Algorithm: "natural"}},
+		{"interfaces ordered by mtu desc", models.OrderByClause{Field: "mtu", Direction: "descending"}},
+		{"sort by mtu asc", models.OrderByClause{Field: "mtu", Direction: "ascending"}},
+		{"sort interfaces by name natural", models.OrderByClause{Field: "name", Direction: "ascending", Algorithm: "natural"}},
+		{"sort interfaces descending", models.OrderByClause{Field: "name", Direction: "descending", Algorithm: "natural"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			orderBy := eql.ExtractOrderBy(tt.query, table, entry)
+			if len(orderBy) != 1 || orderBy[0] != tt.expected {
+				t.Errorf("ExtractOrderBy(%q) = %+v, want [%+v]", tt.query, orderBy, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractOrderByPerFieldDirection(t *testing.T) {
+	entry := &models.EmbeddingEntry{
+		Text: `{"Description":"Interfaces","Fields":["name","mtu","in-error-packets","in-octets","out-octets"]}`,
+	}
+	const table = ".namespace.node.srl.interface"
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"interfaces by errors descending then name ascending", "in-error-packets descending, name ascending natural"},
+		{"sort interfaces by mtu asc then by name desc", "mtu ascending, name descending natural"},
+		{"interfaces sorted by in-octets desc, out-octets asc", "in-octets descending, out-octets ascending"},
+		{"interfaces by mtu descending then in-octets", "mtu descending, in-octets descending"},
+		{"top 5 interfaces by in-octets, out-octets", "in-octets descending, out-octets descending"},
+		{"interfaces by mtu then name", "mtu ascending, name ascending natural"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query := models.EQLQuery{Table: table, OrderBy: eql.ExtractOrderBy(tt.query, table, entry)}
+			if want := table + " order by [" + tt.expected + "]"; query.String() != want {
+				t.Errorf("ExtractOrderBy(%q) renders %q, want %q", tt.query, query.String(), want)
+			}
+		})
+	}
+}
+
+func TestNaturalSortForNameFields(t *testing.T) {
+	tests := []struct {
+		fields    []string
+		query     string
+		field     string
+		algorithm string
+	}{
+		{[]string{"name", "mtu"}, "sort by name", "name", "natural"},
+		{[]string{"interface-name", "if-index"}, "sort by interface-name desc", "interface-name", "natural"},
+		{[]string{"port-id", "oper-state"}, "order ports by port-id", "port-id", "natural"},
+		{[]string{"name", "mtu"}, "sort by mtu", "mtu", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			data, _ := json.Marshal(map[string][]string{"Fields": tt.fields})
+			entry := &models.EmbeddingEntry{Text: string(data)}
+
+			orderBy := eql.ExtractOrderBy(tt.query, ".namespace.node.srl.interface", entry)
+			if len(orderBy) != 1 {
+				t.Fatalf("ExtractOrderBy(%q) = %+v, want one clause", tt.query, orderBy)
+			}
+			if orderBy[0].Field != tt.field || orderBy[0].Algorithm != tt.algorithm {
+				t.Errorf("got %s algorithm %q, want %s algorithm %q", orderBy[0].Field, orderBy[0].Algorithm, tt.field, tt.algorithm)
+			}
+
+			query := models.EQLQuery{Table: ".namespace.node.srl.interface", OrderBy: orderBy}
+			if tt.algorithm != "" && !strings.Contains(query.String(), " natural]") {
+				t.Errorf("EQL %q does not request natural ordering", query.String())
+			}
+		})
+	}
+}
+
+func TestGenerateWhereClauseNodes(t *testing.T) {
+	const table = ".namespace.node.srl.interface"
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "single node",
+			query:    "interfaces on leaf1",
+			expected: `.namespace.node.name = "leaf1"`,
+		},
+		{
+			name:     "node names are lowercased",
+			query:    "interfaces on Leaf1",
+			expected: `.namespace.node.name = "leaf1"`,
+		},
+		{
+			name:     "multiple nodes use in",
+			query:    "interfaces on leaf1 and spine2",
+			expected: `.namespace.node.name in ["leaf1", "spine2"]`,
+		},
+		{
+			name:     "duplicate nodes collapse",
+			query:    "compare leaf1 with leaf1",
+			expected: `.namespace.node.name = "leaf1"`,
+		},
+		{
+			name:     "node condition precedes field conditions",
+			query:    "interfaces on leaf1 with mtu > 1500",
+			expected: `.namespace.node.name = "leaf1" and mtu > 1500`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eql.GenerateWhereClause(table, tt.query); got != tt.expected {
+				t.Errorf("GenerateWhereClause(%q) = %s, want %s", tt.query, got, tt.expected)
+			}
+		})
+	}
+
+	// Tables outside the node namespace never get a node condition
+	if got := eql.GenerateWhereClause(".namespace.alarms.v1.current-alarm", "alarms on leaf1"); strings.Contains(got, ".namespace.node.name") {
+		t.Errorf("alarm table got node condition: %s", got)
+	}
+}
+
+func TestGenerateWhereClauseIsDeterministic(t *testing.T) {
+	const table = ".namespace.node.srl.interface"
+	const query = "down interfaces on leaf1 with mtu > 1500 and admin-state enabled"
+	const expected = `.namespace.node.name = "leaf1" and admin-state = "enable" and mtu > 1500 and oper-state = "down"`
+
+	for i := 0; i < 50; i++ {
+		if got := eql.GenerateWhereClause(table, query); got != expected {
+			t.Fatalf("GenerateWhereClause(%q) = %s, want %s", query, got, expected)
+		}
+	}
+}
+
+func TestUnitThresholds(t *testing.T) {
+	const (
+		apps       = ".namespace.node.srl.system.app-management.application.statistics"
+		rates      = ".namespace.node.srl.interface.traffic-rate"
+		statistics = ".namespace.node.srl.interface.statistics"
+		bgp        = ".namespace.node.srl.network-instance.protocols.bgp.neighbor"
+	)
+	tests := []struct {
+		table, query, expected string
+	}{
+		// Without a direction either rate may cross the threshold
+		{rates, "traffic over 10 gbps", "(in-bps > 10000000000 or out-bps > 10000000000)"},
+		{rates, "egress traffic under 500 mbps", "out-bps < 500000000"},
+		{rates, "inbound traffic over 1 gbps", "in-bps > 1000000000"},
+		{apps, "processes using more than 2 gb", "memory-usage > 2147483648"},
+		// Each unit only applies where a field stores it
+		{apps, "processes over 10 gbps", ""},
+		{rates, "interfaces using more than 2 gb", ""},
+		{statistics, "traffic over 10 gbps", ""},
+		{bgp, "neighbors over 10 gbps", ""},
+		{bgp, "neighbors using more than 2 gb", ""},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, composite := range eql.ExtractCompositeConditions(tt.query, tt.table) {
+			got = append(got, composite.String())
+		}
+		conditions := eql.ExtractConditions(tt.query, tt.table)
+		for _, field := range []string{"in-bps", "out-bps", "memory-usage"} {
+			if value, ok := conditions[field]; ok {
+				got = append(got, eql.FormatCondition(field, value))
+			}
+		}
+		if strings.Join(got, " and ") != tt.expected {
+			t.Errorf("unit conditions for %q on %s = %v, want %s", tt.query, tt.table, got, tt.expected)
+		}
+	}
+}
+
+func TestUnappliedUnitThresholds(t *testing.T) {
+	tests := []struct {
+		table, query string
+		expected     []string
+	}{
+		{".namespace.node.srl.interface.statistics", "traffic over 10 gbps", []string{"over 10 gbps"}},
+		{".namespace.node.srl.network-instance.protocols.bgp.neighbor", "neighbors using more than 2 gb", []string{"more than 2 gb"}},
+		{".namespace.node.srl.interface.traffic-rate", "traffic over 10 gbps", nil},
+		{".namespace.node.srl.system.app-management.application.statistics", "processes using more than 2 gb", nil},
+		// Speed ranges become a port speed condition instead
+		{".namespace.node.srl.interface.ethernet", "ports with speed at most 1000 mbps", nil},
+	}
+	for _, tt := range tests {
+		if got := eql.UnappliedUnitThresholds(tt.query, tt.table); !slices.Equal(got, tt.expected) {
+			t.Errorf("UnappliedUnitThresholds(%q, %s) = %q, want %q", tt.query, tt.table, got, tt.expected)
+		}
+	}
+}
+
+func TestSpeedRanges(t *testing.T) {
+	tests := []struct {
+		table, query, expected string
+	}{
+		{".namespace.node.srl.interface.ethernet", "interfaces faster than 25g", `port-speed in ["40G", "50G", "100G", "200G", "400G", "800G"]`},
+		{".namespace.node.srl.interface.ethernet", "ports between 10g and 100g", `port-speed in ["10G", "25G", "40G", "50G", "100G"]`},
+		{".namespace.node.srl.interface.ethernet", "ports with speed at most 1000 mbps", `port-speed in ["10M", "100M", "1G"]`},
+		{".namespace.node.srl.interface.ethernet", "100g interfaces", `port-speed = "100G"`},
+		{".namespace.node.srl.interface.ethernet", "ports faster than 800g", ""},
+		{".namespace.node.sros.state.port.ethernet", "ports at least 40g", "oper-speed >= 40000"},
+		// Rates are only stored on traffic-rate tables
+		{".namespace.node.srl.interface.statistics", "traffic over 10 gbps", ""},
+	}
+	for _, tt := range tests {
+		conditions := eql.ExtractConditions(tt.query, tt.table)
+		var got []string
+		for _, field := range []string{"port-speed", "oper-speed", "in-bps"} {
+			if value, ok := conditions[field]; ok {
+				got = append(got, eql.FormatCondition(field, value))
+			}
+		}
+		if strings.Join(got, " and ") != tt.expected {
+			t.Errorf("conditions for %q on %s = %v, want %s", tt.query, tt.table, got, tt.expected)
+		}
+	}
+}
+
+func TestSROSSpeedField(t *testing.T) {
+	tests := []struct {
+		table, query, expected string
+	}{
+		// State tables report oper-speed, configure tables set speed
+		{".namespace.node.sros.state.port.ethernet", "100g ports", "oper-speed = 100000"},
+		{".namespace.node.sros.configure.port.ethernet", "100g ports", "speed = 100000"},
+		{".namespace.node.sros.state.port.ethernet", "ports faster than 25g", "oper-speed > 25000"},
+		{".namespace.node.sros.configure.port.ethernet", "ports between 10g and 100g", "speed >= 10000 and speed <= 100000"},
+	}
+	for _, tt := range tests {
+		if got := eql.GenerateWhereClause(tt.table, tt.query); got != tt.expected {
+			t.Errorf("GenerateWhereClause(%s, %q) = %s, want %s", tt.table, tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestASNumberRanges(t *testing.T) {
+	const table = ".namespace.node.srl.network-instance.protocols.bgp.neighbor"
+	fields := []string{"peer-address", "peer-as", "session-state"}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"bgp neighbors with as between 65000 and 65100", "peer-as >= 65000 and peer-as <= 65100"},
+		{"bgp peers in asn 65200-65100", "peer-as >= 65100 and peer-as <= 65200"},
+		{"bgp neighbors with as numbers from 100 to 200", "peer-as >= 100 and peer-as <= 200"},
+		{"bgp neighbors with private asns", "peer-as >= 64512 and peer-as <= 65534"},
+		{"bgp neighbor as 65001", "peer-as = 65001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := eql.GenerateWhereClauseWithValidation(table, tt.query, fields); got != tt.expected {
+				t.Errorf("where clause for %q = %s, want %s", tt.query, got, tt.expected)
+			}
+		})
+	}
+
+	// Ranges only apply to BGP tables
+	if got := eql.GenerateWhereClause(".namespace.node.srl.interface", "interfaces private asns"); got != "" {
+		t.Errorf("non-BGP table got condition %q", got)
+	}
+}
+
+func TestExclusionConditions(t *testing.T) {
+	const (
+		ifTable    = ".namespace.node.srl.interface"
+		statsTable = ".namespace.node.srl.interface.statistics"
+	)
+
+	tests := []struct {
+		table    string
+		query    string
+		expected string
+	}{
+		{statsTable, "interface statistics without errors", "in-error-packets = 0 and out-error-packets = 0"},
+		{statsTable, "interface statistics excluding discards on leaf1", `.namespace.node.name = "leaf1" and in-discards = 0 and out-discards = 0`},
+		{statsTable, "interface statistics except mgmt0", `.namespace.node.srl.interface.name != "mgmt0"`},
+		{ifTable, "show interfaces excluding management interface", `name != "mgmt0"`},
+		{ifTable, "interfaces that are up except mgmt0", `name != "mgmt0" and oper-state = "up"`},
+		{ifTable, "interfaces except down", `oper-state != "down"`},
+		{ifTable, "interfaces other than the disabled ones", `admin-state != "disable"`},
+		{ifTable, "interfaces without vlans", ""},
+		{ifTable, "interfaces on leaf1 and spine1 but not management", `.namespace.node.name in ["leaf1", "spine1"] and name != "mgmt0"`},
+		{ifTable, "interfaces on leaf1 not management and not system", `.namespace.node.name = "leaf1" and name != "mgmt0" and name != "system0"`},
+		{ifTable, "interfaces that are not up", `oper-state != "up"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := eql.GenerateWhereClause(tt.table, tt.query); got != tt.expected {
+				t.Errorf("where clause for %q = %s, want %s", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPercentThresholds(t *testing.T) {
+	const memory = ".namespace.node.srl.platform.control.memory"
+	const apps = ".namespace.node.srl.system.app-management.application.statistics"
+	high := fmt.Sprintf("> %d", constants.DefaultHighMemoryThreshold)
+
+	tests := []struct {
+		table    string
+		query    string
+		field    string
+		expected string
+	}{
+		{memory, "memory above 80% utilization", "utilization", "> 80"},
+		{memory, "memory utilization over 90 percent", "utilization", "> 90"},
+		{memory, "memory under 12.5%", "utilization", "< 12.5"},
+		{memory, "memory with utilization >= 95%", "utilization", ">= 95"},
+		{apps, "processes with cpu over 90 percent", "cpu-utilization", "> 90"},
+		{apps, "processes using more than 50% memory", "memory-utilization", "> 50"},
+		{memory, "high memory utilization", "utilization", high},
+		{apps, "processes with high cpu utilization", "cpu-utilization", high},
+		// Tables without a utilization field get no condition
+		{".namespace.node.srl.network-instance.protocols.bgp.neighbor", "neighbors above 80% utilization", "utilization", ""},
+		{".namespace.node.srl.interface.statistics", "interfaces with high utilization", "utilization", ""},
+	}
+
+	for _, tt := range tests {
+		conditions := eql.ExtractConditions(tt.query, tt.table)
+		if got := conditions[tt.field]; got != tt.expected {
+			t.Errorf("%s for %q on %s = %q, want %q (conditions %v)", tt.field, tt.query, tt.table, got, tt.expected, conditions)
+		}
+	}
+
+	// The number is not also read as a plain numeric condition
+	if conditions := eql.ExtractConditions("utilization > 75%", memory); len(conditions) != 1 {
+		t.Errorf("conditions for utilization > 75%% = %v, want only utilization", conditions)
+	}
+}
+
+func TestNumericConditions(t *testing.T) {
+	const table = ".namespace.node.srl.platform.control.temperature"
+
+	tests := []struct {
+		query string
+		where string
+	}{
+		{"temperature below -5", "temperature < -5"},
+		{"utilization over 99.5", "utilization > 99.5"},
+		{"temperature < -12.75", "temperature < -12.75"},
+		{"mtu>1500.5", "mtu > 1500.5"},
+		{"temperature equal to -5", "temperature = -5"},
+		{"mtu at least 9000", "mtu >= 9000"},
+		{"interfaces with more than 10 errors", ""},
+		{"turnover 5", ""},
+	}
+
+	for _, tt := range tests {
+		if got := eql.GenerateWhereClause(table, tt.query); got != tt.where {
+			t.Errorf("GenerateWhereClause(%q) = %q, want %q", tt.query, got, tt.where)
+		}
+	}
+}
+
+func TestOpticalPowerThresholds(t *testing.T) {
+	const channel = ".namespace.node.srl.interface.transceiver.channel"
+
+	tests := []struct {
+		query      string
+		conditions map[string]string
+	}{
+		{"transceivers with rx power below -10 dBm", map[string]string{"input-power": "< -10"}},
+		{"transceiver channels with input power below -7.5", map[string]string{"input-power": "< -7.5"}},
+		{"optics with tx power above -3.25 dbm", map[string]string{"output-power": "> -3.25"}},
+		{"output power at least 0.5 dbm", map[string]string{"output-power": ">= 0.5"}},
+		{"rx power below -12 dbm and tx power above 1.5 dbm", map[string]string{"input-power": "< -12", "output-power": "> 1.5"}},
+		{"transceiver power < -3 dbm", map[string]string{"input-power": "< -3"}},
+	}
+
+	for _, tt := range tests {
+		if got := eql.ExtractConditions(tt.query, channel); !maps.Equal(got, tt.conditions) {
+			t.Errorf("ExtractConditions(%q) = %v, want %v", tt.query, got, tt.conditions)
+		}
+	}
+
+	// Power thresholds only apply to transceiver tables
+	if got := eql.ExtractConditions("rx power below -10 dbm", ".namespace.node.srl.interface"); len(got) != 0 {
+		t.Errorf("conditions on the interface table = %v, want none", got)
+	}
+}
+
+func TestHighMemory(t *testing.T) {
+	const memory = ".namespace.node.srl.platform.control.memory"
+	const apps = ".namespace.node.srl.system.app-management.application.statistics"
+	high := fmt.Sprintf("> %d", constants.DefaultHighMemoryThreshold)
+
+	tests := []struct {
+		table    string
+		query    string
+		field    string
+		expected string
+	}{
+		{apps, "processes using high memory", "memory-utilization", high},
+		{apps, "applications under memory pressure", "memory-utilization", high},
+		{memory, "high memory usage on leaf1", "utilization", high},
+		{memory, "high memory above 95%", "utilization", "> 95"},
+		{apps, "processes with high memory using more than 2 gb", "memory-utilization", ""},
+		{".namespace.node.srl.interface", "interfaces with high memory", "utilization", ""},
+	}
+
+	for _, tt := range tests {
+		if got := eql.ExtractConditions(tt.query, tt.table)[tt.field]; got != tt.expected {
+			t.Errorf("%s for %q on %s = %q, want %q", tt.field, tt.query, tt.table, got, tt.expected)
+		}
+	}
+}
+
+func TestAdminStatePerPlatform(t *testing.T) {
+	tests := []struct {
+		table    string
+		query    string
+		expected string
+	}{
+		{".namespace.node.srl.interface", "disabled interfaces", "disable"},
+		{".namespace.node.srl.interface", "enabled interfaces", "enable"},
+		{".namespace.node.sros.state.port", "disabled ports", "down"},
+		{".namespace.node.sros.state.router.interface", "enabled router interfaces", "up"},
+		{".namespace.node.sros.configure.port", "disabled ports", "disable"},
+		{".namespace.node.sros.configure.router.interface", "enabled interfaces", "enable"},
+		{".namespace.node.sros.state.router.bgp.neighbor", "disabled bgp neighbors", ""},
+	}
+
+	for _, tt := range tests {
+		if got := eql.ExtractConditions(tt.query, tt.table)["admin-state"]; got != tt.expected {
+			t.Errorf("admin-state for %q on %s = %q, want %q", tt.query, tt.table, got, tt.expected)
+		}
+	}
+
+	const where = `admin-state != "down"`
+	if got := eql.GenerateWhereClause(".namespace.node.sros.state.port", "ports other than the disabled ones"); got != where {
+		t.Errorf("exclusion on SR OS port = %s, want %s", got, where)
+	}
+}
+
+func TestPlatformGatedMappings(t *testing.T) {
+	const (
+		srlEthernet  = ".namespace.node.srl.interface.ethernet"
+		srosEthernet = ".namespace.node.sros.state.port.ethernet"
+	)
+	tests := []struct {
+		table, query string
+		field        string
+		expected     string
+	}{
+		// SR Linux only: port-speed enums and enable/disable admin state
+		{srlEthernet, "100g interfaces", "port-speed", "100G"},
+		{srosEthernet, "100g ports", "port-speed", ""},
+		{".namespace.node.srl.interface", "enabled interfaces", "admin-state", "enable"},
+		{".namespace.node.sros.state.router.interface", "enabled interfaces", "admin-state", "up"},
+		// SR OS only: speeds in Mbps
+		{srosEthernet, "100g ports", "oper-speed", "= 100000"},
+		{srlEthernet, "100g interfaces", "oper-speed", ""},
+		// Tables of neither platform get no platform-specific mapping
+		{".namespace.interface.ethernet", "100g interfaces", "port-speed", ""},
+		{".namespace.interface.ethernet", "100g interfaces", "oper-speed", ""},
+	}
+	for _, tt := range tests {
+		if got := eql.ExtractConditions(tt.query, tt.table)[tt.field]; got != tt.expected {
+			t.Errorf("%s for %q on %s = %q, want %q", tt.field, tt.query, tt.table, got, tt.expected)
+		}
+	}
+}
+
+func TestExtractConditionList(t *testing.T) {
+	const table = ".namespace.node.srl.interface"
+
+	got := eql.ExtractConditionList("interfaces that are up except system and except mgmt0", table)
+	expected := []eql.Condition{
+		{Field: "name", Value: `!= "system0"`},
+		{Field: "name", Value: `!= "mgmt0"`},
+		{Field: "oper-state", Value: "up"},
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("ExtractConditionList = %v, want %v", got, expected)
+	}
+
+	// The map form keeps one value per field
+	if conditions := eql.ExtractConditions("interfaces except system and except mgmt0", table); conditions["name"] != `!= "mgmt0"` {
+		t.Errorf("ExtractConditions name = %q, want the last exclusion", conditions["name"])
+	}
+}
+
+func TestUnmatchedNodeTokens(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"cpu on leaf1", nil},
+		{"cpu usage leaf01a", []string{"leaf01a"}},
+		{"bgp neighbors spine-core and leaf2, spine-core", []string{"spine-core"}},
+		{"cpu on leaf01a", nil},
+		{"interfaces on all leafs and spines", nil},
+		{"leaf-list entries", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := eql.UnmatchedNodeTokens(tt.query); !slices.Equal(got, tt.expected) {
+				t.Errorf("UnmatchedNodeTokens(%q) = %v, want %v", tt.query, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSubinterfaceIndex(t *testing.T) {
+	const (
+		subTable = ".namespace.node.srl.interface.subinterface"
+		arpTable = ".namespace.node.srl.interface.subinterface.ipv4.arp.neighbor"
+		ifTable  = ".namespace.node.srl.interface"
+	)
+
+	tests := []struct {
+		table    string
+		query    string
+		expected string
+	}{
+		{subTable, "subinterface 100 on ethernet-1/1", `.namespace.node.srl.interface.name = "ethernet-1/1" and index = 100`},
+		{subTable, "sub-interface 200", "index = 200"},
+		{subTable, "unit 5 of eth1/2", `.namespace.node.srl.interface.name = "ethernet-1/2" and index = 5`},
+		{subTable, "show ethernet-1/1.100", `.namespace.node.srl.interface.name = "ethernet-1/1" and index = 100`},
+		{arpTable, "arp entries on ethernet-1/3.10", `.namespace.node.srl.interface.name = "ethernet-1/3" and .namespace.node.srl.interface.subinterface.index = 10`},
+		{ifTable, "subinterface 100 on ethernet-1/1", `name = "ethernet-1/1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := eql.GenerateWhereClause(tt.table, tt.query); got != tt.expected {
+				t.Errorf("where clause for %q on %s = %s, want %s", tt.query, tt.table, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeSubinterfaceTerms(t *testing.T) {
+	tests := map[string]string{
+		"sub-interface 200":      "subinterface 200",
+		"Sub-Interfaces on leaf": "subinterfaces on leaf",
+		"unit 5":                 "subinterface 5",
+		"subif 3":                "subinterface 3",
+		"show ethernet-1/1.100":  "show ethernet-1/1.100 subinterface",
+		"units of traffic":       "units of traffic",
+	}
+	for query, want := range tests {
+		if got := eql.NormalizeSubinterfaceTerms(query); got != want {
+			t.Errorf("NormalizeSubinterfaceTerms(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
+
+func TestExtractAddresses(t *testing.T) {
+	query := "routes to 10.0.0.0/8, 2001:db8::/32 via 192.168.1.1 or fe80::1 (rd 65000:100, version 25.3.3)"
+
+	var prefixes []string
+	for _, prefix := range eql.ExtractPrefixes(query) {
+		prefixes = append(prefixes, prefix.String())
+	}
+	if want := []string{"10.0.0.0/8", "2001:db8::/32"}; !slices.Equal(prefixes, want) {
+		t.Errorf("ExtractPrefixes = %v, want %v", prefixes, want)
+	}
+
+	var addrs []string
+	for _, addr := range eql.ExtractAddresses(query) {
+		addrs = append(addrs, addr.String())
+	}
+	if want := []string{"192.168.1.1", "fe80::1"}; !slices.Equal(addrs, want) {
+		t.Errorf("ExtractAddresses = %v, want %v", addrs, want)
+	}
+
+	if got := eql.AddressFamilies("arp for 10.1.1.1"); !slices.Equal(got, []string{"ipv4"}) {
+		t.Errorf("AddressFamilies = %v, want [ipv4]", got)
+	}
+}
+
+func TestRouteConditions(t *testing.T) {
+	const (
+		v4Table  = ".namespace.node.srl.network-instance.route-table.ipv4-unicast.route"
+		ribTable = ".namespace.node.example.bgp-rib.route"
+	)
+
+	tests := []struct {
+		table    string
+		query    string
+		expected string
+	}{
+		{v4Table, "routes to 10.0.0.0/8", `ipv4-prefix = "10.0.0.0/8"`},
+		{v4Table, "routes to 10.0.0.0/8 or 172.16.0.0/12", `ipv4-prefix in ["10.0.0.0/8", "172.16.0.0/12"]`},
+		{v4Table, "routes to 2001:db8::/32", ""},
+		{v4Table, "bgp routes", `route-type = "bgp"`},
+		{v4Table, "connected routes", `route-type = "local"`},
+		{ribTable, "evpn routes with rd 65000:100", `address-family = "evpn" and route-distinguisher = "65000:100"`},
+		{ribTable, "ipv6 routes to 2001:db8::/32", `address-family = "ipv6-unicast" and ipv6-prefix = "2001:db8::/32"`},
+		{".namespace.node.srl.interface", "interfaces on 10.0.0.0/8", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := eql.GenerateWhereClause(tt.table, tt.query); got != tt.expected {
+				t.Errorf("where clause for %q on %s = %s, want %s", tt.query, tt.table, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNeighborConditions(t *testing.T) {
+	const (
+		arpTable = ".namespace.node.srl.interface.subinterface.ipv4.arp.neighbor"
+		ndTable  = ".namespace.node.srl.interface.subinterface.ipv6.neighbor-discovery.neighbor"
+		ethTable = ".namespace.node.srl.interface.ethernet"
+	)
+
+	tests := []struct {
+		table    string
+		query    string
+		expected string
+	}{
+		{arpTable, "static arp entries", `origin = "static"`},
+		{arpTable, "dynamic arp entries on leaf1", `.namespace.node.name = "leaf1" and origin = "dynamic"`},
+		{arpTable, "arp entry for 10.1.1.1", `ipv4-address = "10.1.1.1"`},
+		{arpTable, "arp entry for fe80::1", ""},
+		{arpTable, "arp entry for mac aa:bb:cc:dd:ee:ff", `link-layer-address = "AA:BB:CC:DD:EE:FF"`},
+		{ndTable, "ipv6 neighbors that are stale", `current-state = "stale"`},
+		{ndTable, "nd entries for fe80::1 and fe80::2", `ipv6-address in ["fe80::1", "fe80::2"]`},
+		{ethTable, "interface with mac aabb.ccdd.eeff", `hw-mac-address = "AA:BB:CC:DD:EE:FF"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := eql.GenerateWhereClause(tt.table, tt.query); got != tt.expected {
+				t.Errorf("where clause for %q on %s = %s, want %s", tt.query, tt.table, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExtractMACAddresses(t *testing.T) {
+	got := eql.ExtractMACAddresses("macs AA-BB-CC-00-11-22, aabb.ccdd.eeff and 00:1a:2b:3c:4d:5e but not 25.3.3")
+	want := []string{"AA:BB:CC:00:11:22", "AA:BB:CC:DD:EE:FF", "00:1A:2B:3C:4D:5E"}
+	if !slices.Equal(got, want) {
+		t.Errorf("ExtractMACAddresses = %v, want %v", got, want)
+	}
+}
+
+func TestMACNotations(t *testing.T) {
+	tables := map[string]string{
+		".namespace.node.srl.interface.ethernet":                          "hw-mac-address",
+		".namespace.node.srl.network-instance.bridge-table.mac-table.mac": "address",
+		".namespace.node.sros.state.port.ethernet":                        "hw-mac-address",
+	}
+
+	for _, notation := range []string{"aa:bb:cc:dd:ee:ff", "AA-BB-CC-DD-EE-FF", "aabb.ccdd.eeff"} {
+		for table, field := range tables {
+			want := field + ` = "AA:BB:CC:DD:EE:FF"`
+			if got := eql.GenerateWhereClause(table, "find mac "+notation); got != want {
+				t.Errorf("where clause for %s on %s = %s, want %s", notation, table, got, want)
+			}
+		}
+		if masked := strings.TrimSpace(eql.MaskAddresses(notation)); masked != "" {
+			t.Errorf("MaskAddresses(%q) = %q, want it blanked", notation, masked)
+		}
+	}
+}
+
+func TestSinceClear(t *testing.T) {
+	for query, expected := range map[string]bool{
+		"errors since last clear":             true,
+		"drops since reset":                   true,
+		"traffic since counters were cleared": true,
+		"interfaces down since yesterday":     false,
+		"clear bgp sessions":                  false,
+	} {
+		if got := eql.HasSinceClear(query); got != expected {
+			t.Errorf("HasSinceClear(%q) = %v, want %v", query, got, expected)
+		}
+	}
+
+	entry := &models.EmbeddingEntry{
+		Text: `{"Description":"Interface statistics","Fields":["in-octets","out-octets","in-octets-since-clear","in-error-packets","last-clear"]}`,
+	}
+	const table = ".namespace.node.srl.interface.statistics"
+
+	fields := eql.ExtractFields("traffic since last clear", table, entry)
+	expected := []string{"in-octets-since-clear", "out-octets", "last-clear"}
+	if !slices.Equal(fields, expected) {
+		t.Errorf("fields = %v, want %v", fields, expected)
+	}
+
+	if fields := eql.ExtractFields("traffic", table, entry); slices.Contains(fields, "last-clear") {
+		t.Errorf("last-clear projected without a since-clear phrase: %v", fields)
+	}
+	if fields := eql.ExtractFields("interface state since last clear", ".namespace.node.srl.interface", entry); slices.Contains(fields, "last-clear") {
+		t.Errorf("last-clear projected outside a statistics table: %v", fields)
+	}
+}
+
+func TestNamedFields(t *testing.T) {
+	available := []string{"in-octets", "out-octets", "in-error-packets", "name"}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"stream out-octets and in-octets every 5 seconds", []string{"out-octets", "in-octets"}},
+		{"In-Octets, in-error-packets", []string{"in-octets", "in-error-packets"}},
+		{"interface name and octets", nil},
+		{"in-octets-since-clear", nil},
+	}
+	for _, tt := range tests {
+		if got := eql.NamedFields(tt.query, available); !slices.Equal(got, tt.expected) {
+			t.Errorf("NamedFields(%q) = %v, want %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestFleetWide(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+		stripped string
+	}{
+		{"show interfaces for all nodes", true, "show interfaces"},
+		{"interfaces on every node", true, "interfaces"},
+		{"fleet-wide bgp neighbors", true, "bgp neighbors"},
+		{"bgp sessions across the fleet", true, "bgp sessions"},
+		{"interfaces on leaf1", false, "interfaces on leaf1"},
+		{"all interfaces", false, "all interfaces"},
+	}
+	for _, tt := range tests {
+		if got := eql.IsFleetWide(tt.query); got != tt.expected {
+			t.Errorf("IsFleetWide(%q) = %v, want %v", tt.query, got, tt.expected)
+		}
+		if got := eql.StripFleetWide(tt.query); got != tt.stripped {
+			t.Errorf("StripFleetWide(%q) = %q, want %q", tt.query, got, tt.stripped)
+		}
+	}
+
+	if where := eql.GenerateWhereClause(".namespace.node.srl.interface", "interfaces on leaf1 for all nodes"); where != "" {
+		t.Errorf("fleet-wide query kept a node filter: %s", where)
+	}
+}
+
+func TestAFISAFI(t *testing.T) {
+	for query, expected := range map[string][]string{
+		"ipv6 bgp neighbors":          {"ipv6-unicast"},
+		"bgp v4 and v6 peers":         {"ipv4-unicast", "ipv6-unicast"},
+		"dual-stack bgp neighbors":    {"ipv4-unicast", "ipv6-unicast"},
+		"ipv4 multicast bgp families": {"ipv4-multicast"},
+		"evpn peers":                  {"evpn"},
+		"bgp neighbors":               nil,
+	} {
+		if got := eql.ExtractAFISAFI(query); !slices.Equal(got, expected) {
+			t.Errorf("ExtractAFISAFI(%q) = %v, want %v", query, got, expected)
+		}
+	}
+
+	tests := []struct {
+		table    string
+		query    string
+		expected string
+	}{
+		{".namespace.node.srl.network-instance.protocols.bgp.neighbor.afi-safi", "ipv6 bgp neighbors", `afi-safi-name = "ipv6-unicast"`},
+		{".namespace.node.srl.network-instance.protocols.bgp.neighbor.afi-safi", "dual stack bgp", `afi-safi-name in ["ipv4-unicast", "ipv6-unicast"]`},
+		{".namespace.node.sros.state.router.bgp.neighbor", "ipv6 multicast bgp neighbors", `family = "mcast-ipv6"`},
+		{".namespace.node.srl.network-instance.protocols.bgp.neighbor", "ipv6 bgp neighbors", ""},
+		{".namespace.node.srl.interface.subinterface", "ipv6 subinterfaces", ""},
+	}
+	for _, tt := range tests {
+		if got := eql.GenerateWhereClause(tt.table, tt.query); got != tt.expected {
+			t.Errorf("where clause for %q on %s = %s, want %s", tt.query, tt.table, got, tt.expected)
+		}
+	}
+}
+
+func TestTransceiverValues(t *testing.T) {
+	const (
+		transceiver = ".namespace.node.srl.interface.transceiver"
+		ethernet    = ".namespace.node.srl.interface.ethernet"
+	)
+
+	tests := []struct {
+		table    string
+		query    string
+		expected string
+	}{
+		{transceiver, "transceiver with serial XYZ123", `vendor-serial-number = "XYZ123"`},
+		{transceiver, `transceiver serial number: "AB 12-x"`, `vendor-serial-number = "AB 12-x"`},
+		{transceiver, `transceivers from vendor "Finisar Corp"`, `vendor = "Finisar Corp"`},
+		{transceiver, "vendor FINISAR transceivers", `vendor = "FINISAR"`},
+		{transceiver, "transceivers with vendor part number", ""},
+		{transceiver, "serial numbers of transceivers", ""},
+		{ethernet, "ethernet ports with serial XYZ123", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := eql.GenerateWhereClause(tt.table, tt.query); got != tt.expected {
+				t.Errorf("where clause for %q on %s = %s, want %s", tt.query, tt.table, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestComparedNodes(t *testing.T) {
+	tests := map[string][]string{
+		"compare interface errors between leaf1 and leaf2": {"leaf1", "leaf2"},
+		"interface errors leaf1 vs spine1":                 {"leaf1", "spine1"},
+		"interface errors on leaf1 and leaf2":              nil,
+		"compare interface errors on leaf1":                nil,
+	}
+	for query, expected := range tests {
+		if got := eql.ComparedNodes(query); !slices.Equal(got, expected) {
+			t.Errorf("ComparedNodes(%q) = %v, want %v", query, got, expected)
+		}
+	}
+
+	const table = ".namespace.node.srl.interface"
+	query := "compare interfaces that are down between leaf1 and leaf2"
+	got := eql.GenerateNodeWhereClause(table, query, "leaf2", []string{"oper-state"})
+	if want := `.namespace.node.name = "leaf2" and oper-state = "down"`; got != want {
+		t.Errorf("GenerateNodeWhereClause = %s, want %s", got, want)
+	}
+}
+
+func TestSplitConcepts(t *testing.T) {
+	tests := map[string][]string{
+		"show bgp neighbors and their interfaces on leaf1": {"show bgp neighbors", "their interfaces on leaf1"},
+		"cpu usage plus memory usage":                      {"cpu usage", "memory usage"},
+		"alarms along with interface state":                {"alarms", "interface state"},
+		"interfaces on leaf1 and leaf2":                    {"interfaces on leaf1 and leaf2"},
+		"routes to 10.0.0.0/8 and 192.168.0.0/16":          {"routes to 10.0.0.0/8 and 192.168.0.0/16"},
+		"bgp peers with as between 64512 and 65534":        {"bgp peers with as between 64512 and 65534"},
+		"interfaces and":                                   {"interfaces and"},
+	}
+	for query, expected := range tests {
+		if got := eql.SplitConcepts(query); !slices.Equal(got, expected) {
+			t.Errorf("SplitConcepts(%q) = %q, want %q", query, got, expected)
+		}
+	}
+}
+
+func TestInferFieldType(t *testing.T) {
+	tests := map[string]eql.FieldType{
+		"in-packets":    eql.FieldNumeric,
+		"out-octets":    eql.FieldNumeric,
+		"count":         eql.FieldNumeric,
+		"mtu":           eql.FieldNumeric,
+		"oper-state":    eql.FieldEnum,
+		"vlan-tagging":  eql.FieldBool,
+		"admin-enabled": eql.FieldBool,
+		"description":   eql.FieldString,
+		".namespace.node.srl.interface.oper-state": eql.FieldEnum,
+	}
+	for field, expected := range tests {
+		if got := eql.InferFieldType(field); got != expected {
+			t.Errorf("InferFieldType(%q) = %s, want %s", field, got, expected)
+		}
+	}
+}
+
+func TestFormatTypedCondition(t *testing.T) {
+	tests := []struct {
+		field, value string
+		expected     string
+		ok           bool
+	}{
+		{"mtu", "9000", "mtu = 9000", true},
+		{"in-octets", "> 100", "in-octets > 100", true},
+		{"in-octets", "high", "", false},
+		{"vlan-tagging", "true", "vlan-tagging = true", true},
+		{"vlan-tagging", "disabled", "vlan-tagging = false", true},
+		{"vlan-tagging", "maybe", "", false},
+		{"oper-state", "down", `oper-state = "down"`, true},
+		{"oper-state", "> 5", "", false},
+		{"description", "> 5", "description > 5", true},
+	}
+	for _, tt := range tests {
+		got, ok := eql.FormatTypedCondition(tt.field, tt.value)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("FormatTypedCondition(%q, %q) = %s, %v, want %s, %v", tt.field, tt.value, got, ok, tt.expected, tt.ok)
+		}
+	}
+
+	const table = ".namespace.node.srl.interface"
+	if got, want := eql.GenerateWhereClause(table, "tagged interfaces with mtu 9000"), "mtu = 9000 and vlan-tagging = true"; got != want {
+		t.Errorf("GenerateWhereClause = %s, want %s", got, want)
+	}
+}
+
+func TestHealthConditions(t *testing.T) {
+	const statistics = ".namespace.node.srl.interface.statistics"
+	tests := []struct {
+		table, query, expected string
+		fields                 []string
+	}{
+		{".namespace.node.srl.interface", "unhealthy interfaces", `oper-state = "down"`, []string{"name", "oper-state"}},
+		{statistics, "show degraded interfaces", "(in-error-packets > 0 or out-error-packets > 0)", []string{"name", "in-error-packets", "out-error-packets"}},
+		{statistics, "interfaces with problems", `(oper-state = "down" or in-error-packets > 0 or out-error-packets > 0)`, nil},
+		{statistics, "healthy interfaces", "in-error-packets = 0 and out-error-packets = 0", []string{"in-error-packets", "out-error-packets"}},
+		{".namespace.node.srl.interface", "healthy interfaces that are down", `oper-state = "down"`, []string{"oper-state"}},
+		{".namespace.node.srl.network-instance.protocols.bgp.neighbor", "unhealthy bgp peers", "", []string{"session-state"}},
+	}
+	for _, tt := range tests {
+		var got string
+		if tt.fields == nil {
+			got = eql.GenerateWhereClause(tt.table, tt.query)
+		} else {
+			got = eql.GenerateWhereClauseWithValidation(tt.table, tt.query, tt.fields)
+		}
+		if got != tt.expected {
+			t.Errorf("where clause for %q on %s = %s, want %s", tt.query, tt.table, got, tt.expected)
+		}
+	}
+
+	composites := eql.ExtractCompositeConditions("unhealthy interfaces", statistics)
+	if len(composites) != 1 || len(composites[0].Any) != 3 {
+		t.Errorf("ExtractCompositeConditions = %v, want one composite of 3 alternatives", composites)
+	}
+}
+
+func TestDiffIntent(t *testing.T) {
+	for query, expected := range map[string]bool{
+		"what changed on leaf1":             true,
+		"diff interface configuration":      true,
+		"difference in bgp config":          true,
+		"modified interfaces":               true,
+		"interfaces with different mtu":     false,
+		"unchanged interfaces":              false,
+		"interfaces that differ in speed":   false,
+		"show interfaces with descriptions": false,
+	} {
+		if got := eql.HasDiffIntent(query); got != expected {
+			t.Errorf("HasDiffIntent(%q) = %v, want %v", query, got, expected)
+		}
+	}
+
+	entry := &models.EmbeddingEntry{Text: `{"Fields":["admin-state","description","mtu","name","oper-state","last-change"]}`}
+	const table = ".namespace.node.srl.interface"
+	if got := eql.ExtractFields("interfaces with different mtu", table, entry); !slices.Equal(got, []string{"mtu"}) {
+		t.Errorf("fields for a different-mtu query = %v, want [mtu]", got)
+	}
+	if got := eql.ExtractFields("what changed on interfaces", table, entry); !slices.Contains(got, "last-change") {
+		t.Errorf("fields for a changed query = %v, want last-change", got)
+	}
+}
+
+func TestDescriptionPhraseIsLiteral(t *testing.T) {
+	const table = ".namespace.node.srl.interface"
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`interfaces with description "Uplink to spine"`, `description ~ "Uplink to spine"`},
+		{`interfaces with description "uplink (spine1)"`, `description ~ "uplink \\(spine1\\)"`},
+		{`interfaces with description "10.0.0.1/31"`, `description ~ "10\\.0\\.0\\.1/31"`},
+		{`interfaces with description "a*b"`, `description ~ "a\\*b"`},
+		{`interfaces where description contains "uplink \"a\""`, `description ~ "uplink \"a\""`},
+	}
+	for _, tt := range tests {
+		if got := eql.GenerateWhereClause(table, tt.query); !strings.Contains(got, tt.expected) {
+			t.Errorf("GenerateWhereClause(%q) = %s, want it to contain %s", tt.query, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Package test contains helpers for loading the committed fixture database.
+package test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/embedding"
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+// Fixture databases committed under testdata, one per platform like the
+// released embeddings.
+const (
+	srlFixture  = "testdata/fixture_srl.json"
+	srosFixture = "testdata/fixture_sros.json"
+)
+
+// loadFixtureDB decodes a fixture database and builds its inverted index.
+// It deliberately bypasses the loader so no binary cache is written next to
+// the committed file.
+func loadFixtureDB(tb testing.TB, path string) *models.EmbeddingDB {
+	tb.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("failed to read fixture db: %v", err)
+	}
+
+	var db models.EmbeddingDB
+	if err := json.Unmarshal(data, &db); err != nil {
+		tb.Fatalf("failed to decode fixture db: %v", err)
+	}
+	db.Name = filepath.Base(path)
+	embedding.BuildInvertedIndex(&db)
+
+	return &db
+}
+
+func newFixtureEngine(tb testing.TB, path string) *search.Engine {
+	tb.Helper()
+	return search.NewEngine(loadFixtureDB(tb, path))
+}
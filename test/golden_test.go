@@ -0,0 +1,86 @@
+// Package test contains the golden-query regression harness that pins
+// natural-language queries to the EQL they are expected to produce.
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+const goldenQueriesPath = "testdata/golden_queries.json"
+
+// goldenCase is one expectation from testdata/golden_queries.json
+type goldenCase struct {
+	Fixture               string   `json:"fixture"`
+	Query                 string   `json:"query"`
+	ExpectedTopTable      string   `json:"expectedTopTable"`
+	ExpectedWhereContains []string `json:"expectedWhereContains"`
+}
+
+func TestGoldenQueries(t *testing.T) {
+	data, err := os.ReadFile(goldenQueriesPath)
+	if err != nil {
+		t.Fatalf("failed to read golden queries: %v", err)
+	}
+
+	var cases []goldenCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		t.Fatalf("failed to decode golden queries: %v", err)
+	}
+
+	engines := map[string]*search.Engine{
+		"srl":  newFixtureEngine(t, srlFixture),
+		"sros": newFixtureEngine(t, srosFixture),
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Fixture+"/"+tc.Query, func(t *testing.T) {
+			engine, ok := engines[tc.Fixture]
+			if !ok {
+				t.Fatalf("unknown fixture %q", tc.Fixture)
+			}
+
+			results := engine.IndexedSearch(tc.Query)
+			if len(results) == 0 {
+				t.Fatalf("query %q returned no results, want top table %s", tc.Query, tc.ExpectedTopTable)
+			}
+
+			top := results[0]
+			if top.EQLQuery.Table != tc.ExpectedTopTable {
+				t.Errorf("top table mismatch for %q\n  want: %s\n  got:  %s\n%s",
+					tc.Query, tc.ExpectedTopTable, top.EQLQuery.Table, describeRanking(results))
+			}
+
+			for _, want := range tc.ExpectedWhereContains {
+				if !strings.Contains(top.EQLQuery.WhereClause, want) {
+					t.Errorf("where clause mismatch for %q\n  want substring: %s\n  got clause:     %s",
+						tc.Query, want, top.EQLQuery.WhereClause)
+				}
+			}
+
+			if problems := top.EQLQuery.Validate(top.AvailableFields); len(problems) > 0 {
+				t.Errorf("generated query for %q does not validate: %v", tc.Query, problems)
+			}
+		})
+	}
+}
+
+// describeRanking renders the leading results so a failing expectation shows
+// what displaced the expected table
+func describeRanking(results []models.SearchResult) string {
+	var b strings.Builder
+	b.WriteString("  ranking:\n")
+	for i, result := range results {
+		if i >= 5 {
+			break
+		}
+		fmt.Fprintf(&b, "    %d. %-70s %.2f\n", i+1, result.EQLQuery.Table, result.Score)
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+// Package test contains behavioral tests for loading embedding databases.
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/cache"
+	"github.com/eda-labs/eda-embeddingsearch/internal/embedding"
+)
+
+func TestLoaderWithoutCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	mtime := time.Now().Add(-time.Hour)
+	writeDB := func(description string) {
+		t.Helper()
+		text := `{"Table":{".namespace.node.srl.interface":{"Text":"{\"Description\":\"` + description + `\",\"Fields\":[\"name\"]}"}}}`
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		// Keep the modification time so only the content changes
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cacheManager := cache.NewCacheManager()
+	cachePath := cacheManager.GetBinaryCachePath(path)
+
+	writeDB("original")
+	if _, err := embedding.NewLoader(cacheManager, embedding.WithoutCache()).Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Fatalf("loading without cache wrote %s (stat error %v)", cachePath, err)
+	}
+
+	// A cached load followed by an edit with the same mtime serves stale data
+	if _, err := embedding.NewLoader(cache.NewCacheManager()).Load(path); err != nil {
+		t.Fatal(err)
+	}
+	writeDB("edited")
+	stale, err := embedding.NewLoader(cache.NewCacheManager()).Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stale.Table[".namespace.node.srl.interface"].Description(); got != "original" {
+		t.Fatalf("cached load description = %q, want the stale %q", got, "original")
+	}
+
+	fresh, err := embedding.NewLoader(cache.NewCacheManager(), embedding.WithoutCache()).Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fresh.Table[".namespace.node.srl.interface"].Description(); got != "edited" {
+		t.Errorf("uncached load description = %q, want %q", got, "edited")
+	}
+	if fresh.Name != "db.json" || len(fresh.InvertedIndex) == 0 {
+		t.Errorf("uncached load has name %q and %d index words", fresh.Name, len(fresh.InvertedIndex))
+	}
+}
+
+func TestLoaderClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	text := `{"Table":{".namespace.node.srl.interface":{"Text":"{\"Description\":\"interfaces\",\"Fields\":[\"name\"]}"}}}`
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := embedding.NewLoader(cache.NewCacheManager())
+	first, err := loader.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := loader.Load(path); again != first {
+		t.Fatal("second load did not come from the memory cache")
+	}
+
+	if err := loader.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := loader.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded == first {
+		t.Error("load after Close returned the database released from memory")
+	}
+}
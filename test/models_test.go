@@ -0,0 +1,413 @@
+// Package test contains unit tests for the shared models.
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/embedding"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+func TestSchemaPath(t *testing.T) {
+	tests := map[string]string{
+		".namespace.node.srl.interface.statistics": "/interface/statistics",
+		".namespace.node.sros.state.port":          "/state/port",
+		".namespace.alarms.v1.current-alarm":       "/alarms/v1/current-alarm",
+		"":                                         "",
+	}
+
+	for key, expected := range tests {
+		if got := models.SchemaPath(key); got != expected {
+			t.Errorf("SchemaPath(%q) = %q, want %q", key, got, expected)
+		}
+	}
+}
+
+func TestEQLTableToGNMIPath(t *testing.T) {
+	tests := map[string]string{
+		".namespace.node.srl.interface.statistics":                                    "/interface[name=*]/statistics",
+		".namespace.node.srl.interface.subinterface.ipv6.neighbor-discovery.neighbor": "/interface[name=*]/subinterface[index=*]/ipv6/neighbor-discovery/neighbor[ipv6-address=*]",
+		".namespace.node.srl.network-instance.protocols.bgp.neighbor":                 "/network-instance[name=*]/protocols/bgp/neighbor[peer-address=*]",
+		".namespace.node.sros.state.router.bgp.neighbor":                              "/state/router[router-name=*]/bgp/neighbor[ip-address=*]",
+		".namespace.node.sros.configure.port.ethernet":                                "/configure/port[port-id=*]/ethernet",
+		".namespace.alarms.v1.current-alarm":                                          "",
+		"":                                                                            "",
+	}
+
+	for key, expected := range tests {
+		if got := models.EQLTableToGNMIPath(key); got != expected {
+			t.Errorf("EQLTableToGNMIPath(%q) = %q, want %q", key, got, expected)
+		}
+	}
+}
+
+func TestSearchResultJSONPlatform(t *testing.T) {
+	tests := []struct {
+		key      string
+		platform string
+	}{
+		{".namespace.node.srl.interface", "srl"},
+		{".namespace.node.sros.state.port", "sros"},
+		{".namespace.alarms.v1.current-alarm", ""},
+	}
+
+	for _, tt := range tests {
+		result := models.SearchResult{Key: tt.key, EQLQuery: models.EQLQuery{Table: tt.key}}
+		data, err := json.Marshal(&result)
+		if err != nil {
+			t.Fatalf("marshal %s: %v", tt.key, err)
+		}
+
+		var decoded map[string]any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("unmarshal %s: %v", tt.key, err)
+		}
+
+		platform, _ := decoded["platform"].(string)
+		if platform != tt.platform {
+			t.Errorf("platform for %s = %q, want %q", tt.key, platform, tt.platform)
+		}
+		if decoded["schemaPath"] != models.SchemaPath(tt.key) {
+			t.Errorf("schemaPath for %s = %v, want %q", tt.key, decoded["schemaPath"], models.SchemaPath(tt.key))
+		}
+	}
+}
+
+func TestPlatformFromTable(t *testing.T) {
+	tests := []struct {
+		table    string
+		platform models.EmbeddingType
+		ok       bool
+	}{
+		{".namespace.node.srl.interface", models.SRL, true},
+		{".namespace.node.sros.state.port", models.SROS, true},
+		{".namespace.node.sros.configure.router.interface", models.SROS, true},
+		{".namespace.alarms.v1.current-alarm", models.SRL, false},
+		// A segment merely starting with the platform name does not count
+		{".namespace.node.srlinux.interface", models.SRL, false},
+	}
+	for _, tt := range tests {
+		platform, ok := models.PlatformFromTable(tt.table)
+		if platform != tt.platform || ok != tt.ok {
+			t.Errorf("PlatformFromTable(%s) = %v, %v, want %v, %v", tt.table, platform, ok, tt.platform, tt.ok)
+		}
+	}
+}
+
+func TestEmbeddingDBValidate(t *testing.T) {
+	if err := loadFixtureDB(t, srlFixture).Validate(); err != nil {
+		t.Errorf("fixture failed validation: %v", err)
+	}
+
+	var validationErr *models.ValidationError
+
+	empty := &models.EmbeddingDB{}
+	if err := empty.Validate(); !errors.As(err, &validationErr) || !validationErr.EmptyTable {
+		t.Errorf("empty table: got %v, want EmptyTable error", err)
+	}
+
+	db := &models.EmbeddingDB{Table: map[string]models.EmbeddingEntry{
+		".namespace.node.srl.interface": {Text: `{"Description":"ok","Fields":["name"]}`},
+		"":                              {Text: `{"Fields":[]}`},
+		".bad.json":                     {Text: `not json`},
+		".no.fields":                    {Text: `{"Description":"no fields"}`},
+		".null.fields":                  {Text: `{"Fields":null}`},
+	}}
+
+	err := db.Validate()
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+	if validationErr.EmptyKeys != 1 || validationErr.InvalidText != 1 || validationErr.MissingFields != 2 || validationErr.Malformed != 4 {
+		t.Errorf("unexpected counts: %+v", validationErr)
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	db := &models.EmbeddingDB{Table: map[string]models.EmbeddingEntry{
+		".a": {Text: `{"Fields":["x"]}`},
+		".b": {Text: `{"Fields":["x","y","z"]}`},
+		".c": {Text: `{"Fields":["x","y","z","w","v"]}`},
+		".d": {Text: `{"Fields":["x","y"]}`},
+		".e": {Text: `broken`},
+	}}
+	embedding.BuildInvertedIndex(db)
+
+	stats := embedding.ComputeStats(db)
+	if stats.Entries != 5 || stats.ParseFailures != 1 {
+		t.Errorf("entries/failures = %d/%d, want 5/1", stats.Entries, stats.ParseFailures)
+	}
+	if stats.MinFields != 1 || stats.MaxFields != 5 || stats.MedianFields != 2.5 {
+		t.Errorf("min/max/median = %d/%d/%.1f, want 1/5/2.5", stats.MinFields, stats.MaxFields, stats.MedianFields)
+	}
+	if stats.IndexedTerms != len(db.InvertedIndex) {
+		t.Errorf("indexed terms = %d, want %d", stats.IndexedTerms, len(db.InvertedIndex))
+	}
+}
+
+func TestParallelIndexMatchesSerial(t *testing.T) {
+	table := embedding.NewSyntheticDB(500).Table
+
+	serial := models.NewEmbeddingDB(table)
+	embedding.BuildInvertedIndexWithWorkers(serial, 1)
+
+	for _, workers := range []int{2, 7, 1000} {
+		parallel := models.NewEmbeddingDB(table)
+		embedding.BuildInvertedIndexWithWorkers(parallel, workers)
+		if !reflect.DeepEqual(parallel.InvertedIndex, serial.InvertedIndex) {
+			t.Errorf("index built with %d workers differs from the serial build", workers)
+		}
+		if !reflect.DeepEqual(parallel.DescriptionIndex, serial.DescriptionIndex) {
+			t.Errorf("description index built with %d workers differs from the serial build", workers)
+		}
+	}
+
+	for word, keys := range serial.InvertedIndex {
+		if !slices.IsSorted(keys) || len(slices.Compact(slices.Clone(keys))) != len(keys) {
+			t.Errorf("keys for %q are not sorted and unique: %v", word, keys)
+		}
+	}
+}
+
+func TestEQLQueryWithNamespace(t *testing.T) {
+	q := models.EQLQuery{
+		Table:       ".namespace.node.srl.interface.statistics",
+		Fields:      []string{"in-octets"},
+		WhereClause: `.namespace.node.name = "leaf1"`,
+		OrderBy:     []models.OrderByClause{{Field: "in-octets", Direction: "descending"}},
+	}
+	const original = `.namespace.node.srl.interface.statistics fields [in-octets] where (.namespace.node.name = "leaf1") order by [in-octets descending]`
+
+	tests := map[string]string{
+		"eda": `.eda.node.srl.interface.statistics fields [in-octets] where (.eda.node.name = "leaf1") order by [in-octets descending]`,
+		"":    `.node.srl.interface.statistics fields [in-octets] where (.node.name = "leaf1") order by [in-octets descending]`,
+	}
+	for namespace, expected := range tests {
+		rewritten := q.WithNamespace(namespace)
+		if got := rewritten.String(); got != expected {
+			t.Errorf("WithNamespace(%q) = %s, want %s", namespace, got, expected)
+		}
+	}
+	if got := q.String(); got != original {
+		t.Errorf("WithNamespace modified the original query: %s", got)
+	}
+}
+
+func TestEQLQueryRender(t *testing.T) {
+	q := models.EQLQuery{
+		Table:       ".namespace.node.srl.interface",
+		Fields:      []string{"name", "mtu"},
+		WhereClause: `oper-state = "up"`,
+		OrderBy:     []models.OrderByClause{{Field: "mtu", Direction: "descending"}},
+		Limit:       5,
+		Delta:       &models.DeltaClause{Unit: "seconds", Value: 1},
+	}
+
+	if got := q.Render(nil); got != q.String() {
+		t.Errorf("Render(nil) = %q, want %q", got, q.String())
+	}
+
+	var clauses []models.Clause
+	got := q.Render(func(clause models.Clause, text string) string {
+		clauses = append(clauses, clause)
+		return "<" + text + ">"
+	})
+	const want = `<.namespace.node.srl.interface> <fields [name, mtu]> <where (oper-state = "up")> <order by [mtu descending]> <limit 5> <delta seconds 1>`
+	if got != want {
+		t.Errorf("Render = %q, want %q", got, want)
+	}
+	wantClauses := []models.Clause{models.ClauseTable, models.ClauseFields, models.ClauseWhere, models.ClauseOrderBy, models.ClauseLimit, models.ClauseDelta}
+	if !slices.Equal(clauses, wantClauses) {
+		t.Errorf("clauses = %v, want %v", clauses, wantClauses)
+	}
+}
+
+func TestWhereFields(t *testing.T) {
+	where := `.namespace.node.name in ["leaf1", "leaf2"] and (oper-state = "down" or description ~ "a = b") and in-octets >= 100 and not admin-state != "enable"`
+	want := []string{".namespace.node.name", "oper-state", "description", "in-octets", "admin-state"}
+	if got := models.WhereFields(where); !slices.Equal(got, want) {
+		t.Errorf("WhereFields = %v, want %v", got, want)
+	}
+}
+
+func TestEQLQueryValidate(t *testing.T) {
+	available := []string{"name", "oper-state", "in-octets"}
+
+	valid := models.EQLQuery{
+		Table:       ".namespace.node.srl.interface",
+		WhereClause: `.namespace.node.name = "leaf1" and oper-state = "up"`,
+		OrderBy:     []models.OrderByClause{{Field: "in-octets", Direction: "descending"}},
+		Limit:       10,
+		Delta:       &models.DeltaClause{Unit: "seconds", Value: 5},
+	}
+	if problems := valid.Validate(available); len(problems) != 0 {
+		t.Errorf("valid query problems = %v", problems)
+	}
+
+	invalid := models.EQLQuery{
+		WhereClause: `mtu > 1500 and oper-state = "up"`,
+		OrderBy:     []models.OrderByClause{{Field: "cpu", Direction: "descending"}},
+		Limit:       5000,
+		Delta:       &models.DeltaClause{Unit: "seconds", Value: 0},
+	}
+	problems := invalid.Validate(available)
+	for _, want := range []error{models.ErrNoTable, models.ErrUnknownField, models.ErrInvalidLimit, models.ErrInvalidDelta} {
+		if !slices.ContainsFunc(problems, func(err error) bool { return errors.Is(err, want) }) {
+			t.Errorf("problems %v lack %v", problems, want)
+		}
+	}
+	if len(problems) != 5 {
+		t.Errorf("got %d problems, want 5 (mtu and cpu unknown): %v", len(problems), problems)
+	}
+
+	if problems := invalid.Validate(nil); len(problems) != 3 {
+		t.Errorf("without available fields got %v, want only table, limit and delta problems", problems)
+	}
+}
+
+func TestJSONSchemaMatchesMarshal(t *testing.T) {
+	result := &models.SearchResult{
+		Key:             ".namespace.node.srl.interface",
+		Score:           42,
+		NormalizedScore: 1,
+		EQLQuery: models.EQLQuery{
+			Table:       ".namespace.node.srl.interface",
+			Fields:      []string{"name"},
+			WhereClause: `name = "mgmt0"`,
+			OrderBy:     []models.OrderByClause{{Field: "name", Direction: "ascending", Algorithm: "natural"}},
+			Limit:       5,
+			Delta:       &models.DeltaClause{Unit: "seconds", Value: 1},
+		},
+		Description:     "interfaces",
+		AvailableFields: []string{"name"},
+		Source:          "fixture",
+		Comparison:      []models.NodeQuery{{Node: "leaf1", EQLQuery: models.EQLQuery{Table: "t", WhereClause: "w"}}},
+		FleetWide:       true,
+		ReferenceText:   ".namespace.node.srl.interface interfaces",
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := models.JSONSchema(reflect.TypeFor[models.SearchResult]())
+	checkSchema(t, "result", decoded, schema)
+
+	// A fully populated result uses every property the schema lists
+	if got, want := len(decoded.(map[string]any)), len(schema["properties"].(map[string]any)); got != want {
+		t.Errorf("marshalled %d properties, schema lists %d", got, want)
+	}
+}
+
+// checkSchema verifies value against the subset of JSON Schema that
+// models.JSONSchema and output.Schema emit
+func checkSchema(t *testing.T, path string, value any, schema map[string]any) {
+	t.Helper()
+	for _, problem := range schemaProblems(path, value, schema) {
+		t.Error(problem)
+	}
+}
+
+func schemaProblems(path string, value any, schema map[string]any) []string {
+	if alternatives, ok := schema["oneOf"].([]any); ok {
+		matches := 0
+		for _, alternative := range alternatives {
+			if len(schemaProblems(path, value, alternative.(map[string]any))) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return []string{fmt.Sprintf("%s matches %d of the oneOf schemas, want exactly 1", path, matches)}
+		}
+		return nil
+	}
+
+	var problems []string
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s = %v, want an object", path, value)}
+		}
+		properties := schema["properties"].(map[string]any)
+		for key, v := range object {
+			property, ok := properties[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is not in the schema", path, key))
+				continue
+			}
+			problems = append(problems, schemaProblems(path+"."+key, v, property.(map[string]any))...)
+		}
+		for _, key := range schema["required"].([]string) {
+			if _, ok := object[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is required but missing", path, key))
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s = %v, want an array", path, value)}
+		}
+		if maxItems, ok := schema["maxItems"].(int); ok && len(items) > maxItems {
+			problems = append(problems, fmt.Sprintf("%s has %d items, want at most %d", path, len(items), maxItems))
+		}
+		for _, item := range items {
+			problems = append(problems, schemaProblems(path+"[]", item, schema["items"].(map[string]any))...)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			problems = append(problems, fmt.Sprintf("%s = %v, want a string", path, value))
+		}
+	case "number", "integer":
+		if _, ok := value.(float64); !ok {
+			problems = append(problems, fmt.Sprintf("%s = %v, want a number", path, value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s = %v, want a boolean", path, value))
+		}
+	}
+	return problems
+}
+
+func TestDiffDBs(t *testing.T) {
+	before := models.NewEmbeddingDB(map[string]models.EmbeddingEntry{
+		".a": models.NewEmbeddingEntry(".a", "table a", []string{"x", "y"}),
+		".b": models.NewEmbeddingEntry(".b", "table b", []string{"x"}),
+		".c": models.NewEmbeddingEntry(".c", "table c", []string{"x", "y"}),
+		".d": models.NewEmbeddingEntry(".d", "table d", nil),
+	})
+	after := models.NewEmbeddingDB(map[string]models.EmbeddingEntry{
+		".a": models.NewEmbeddingEntry(".a", "table a", []string{"y", "x"}),
+		".c": models.NewEmbeddingEntry(".c", "table c", []string{"y", "z"}),
+		".d": models.NewEmbeddingEntry(".d", "renamed d", nil),
+		".e": models.NewEmbeddingEntry(".e", "table e", nil),
+	})
+
+	diff := models.DiffDBs(before, after)
+	if !slices.Equal(diff.Added, []string{".e"}) || !slices.Equal(diff.Removed, []string{".b"}) {
+		t.Errorf("added = %v, removed = %v", diff.Added, diff.Removed)
+	}
+
+	expected := []models.TableChange{
+		{Key: ".c", AddedFields: []string{"z"}, RemovedFields: []string{"x"}},
+		{Key: ".d", OldDescription: "table d", NewDescription: "renamed d"},
+	}
+	if !reflect.DeepEqual(diff.Changed, expected) {
+		t.Errorf("changed = %+v, want %+v", diff.Changed, expected)
+	}
+
+	if !models.DiffDBs(before, before).Empty() {
+		t.Error("a database differs from itself")
+	}
+}
@@ -0,0 +1,256 @@
+// Package test contains behavioral tests for the result formatters.
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/internal/output"
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+func formatFixtureResults(t *testing.T, formatter output.Formatter, results []models.SearchResult) string {
+	t.Helper()
+	var buf bytes.Buffer
+	opts := output.Options{AppliedSynonyms: []search.Synonym{{From: "intf", To: "interface"}}}
+	if err := formatter.Format(&buf, results, opts); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	return buf.String()
+}
+
+func TestFormatters(t *testing.T) {
+	results := newFixtureEngine(t, srlFixture).IndexedSearch("interface statistics")
+	if len(results) < 2 {
+		t.Fatalf("got %d results, want at least 2", len(results))
+	}
+	top := results[0].EQLQuery.String()
+	others := min(len(results), constants.MaxSearchResults) - 1
+
+	var doc struct {
+		TopMatch struct {
+			Query string `json:"query"`
+		} `json:"topMatch"`
+		Others []json.RawMessage `json:"others"`
+	}
+	if err := json.Unmarshal([]byte(formatFixtureResults(t, output.JSON{}, results)), &doc); err != nil {
+		t.Fatalf("JSON output does not parse: %v", err)
+	}
+	if doc.TopMatch.Query != top || len(doc.Others) != others {
+		t.Errorf("JSON top match = %q with %d others, want %q with %d", doc.TopMatch.Query, len(doc.Others), top, others)
+	}
+
+	yaml := formatFixtureResults(t, output.YAML{}, results)
+	for _, want := range []string{"topMatch:\n", "  query: \"" + top + "\"\n", "others:\n  - score: ", "appliedSynonyms:\n  - from: \"intf\"\n"} {
+		if !strings.Contains(yaml, want) {
+			t.Errorf("YAML output lacks %q:\n%s", want, yaml)
+		}
+	}
+
+	if text := formatFixtureResults(t, output.Text{}, results); !strings.Contains(text, "Top match") || !strings.Contains(text, top) {
+		t.Errorf("text output lacks the top match:\n%s", text)
+	}
+
+	tmpl, err := output.NewTemplate(`{{(index .Results 0).EQLQuery.String}} {{len .AppliedSynonyms}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := formatFixtureResults(t, tmpl, results), top+" 1"; got != want {
+		t.Errorf("template output = %q, want %q", got, want)
+	}
+}
+
+func TestFormattersNoMatches(t *testing.T) {
+	for _, name := range output.Names {
+		formatter, err := output.ForName(name)
+		if err != nil {
+			t.Fatalf("ForName(%q): %v", name, err)
+		}
+		if got := formatFixtureResults(t, formatter, nil); !strings.Contains(got, "No matches found") {
+			t.Errorf("%s output for no results = %q, want a no-match message", name, got)
+		}
+	}
+
+	if _, err := output.ForName("xml"); err == nil {
+		t.Error("ForName accepted an unknown format")
+	}
+	if _, err := output.NewTemplate("{{.Results"); err == nil {
+		t.Error("NewTemplate accepted an unterminated action")
+	}
+}
+
+func TestJSONOutputUsesModelMarshaller(t *testing.T) {
+	results := newFixtureEngine(t, srlFixture).IndexedSearch("top 5 interfaces by in-octets on leaf1")
+	if len(results) < 2 {
+		t.Fatalf("got %d results, want at least 2", len(results))
+	}
+
+	var doc struct {
+		TopMatch json.RawMessage   `json:"topMatch"`
+		Others   []json.RawMessage `json:"others"`
+	}
+	if err := json.Unmarshal([]byte(formatFixtureResults(t, output.JSON{}, results)), &doc); err != nil {
+		t.Fatalf("JSON output does not parse: %v", err)
+	}
+
+	printed := append([]json.RawMessage{doc.TopMatch}, doc.Others...)
+	for i, raw := range printed {
+		byValue, err := json.Marshal(results[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		byPointer, err := json.Marshal(&results[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			t.Fatal(err)
+		}
+		if compact.String() != string(byValue) || string(byValue) != string(byPointer) {
+			t.Errorf("result %d printed as %s, model marshals %s (pointer %s)", i, compact.String(), byValue, byPointer)
+		}
+	}
+}
+
+func TestAllResults(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	const query = "interface"
+
+	capped := search.NewEngine(db).IndexedSearch(query)
+	all := search.NewEngine(db, search.WithMaxResults(0)).IndexedSearch(query)
+	if len(capped) != constants.MaxSearchResults || len(all) <= len(capped) {
+		t.Fatalf("got %d capped and %d uncapped results, want %d and more", len(capped), len(all), constants.MaxSearchResults)
+	}
+	for i := range capped {
+		if capped[i].Key != all[i].Key {
+			t.Errorf("result %d = %s uncapped, want %s as when capped", i, all[i].Key, capped[i].Key)
+		}
+	}
+
+	var doc struct {
+		Others []json.RawMessage `json:"others"`
+	}
+	var buf bytes.Buffer
+	if err := (output.JSON{}).Format(&buf, all, output.Options{All: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("JSON output does not parse: %v", err)
+	}
+	if len(doc.Others) != len(all)-1 {
+		t.Errorf("JSON with All lists %d others, want %d", len(doc.Others), len(all)-1)
+	}
+	if got := len(output.NewDocument(all, output.Options{}).Others); got != constants.MaxSearchResults-1 {
+		t.Errorf("document without All lists %d others, want %d", got, constants.MaxSearchResults-1)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	results := newFixtureEngine(t, srlFixture).IndexedSearch("interface statistics on leaf1")
+	if len(results) < 2 {
+		t.Fatalf("got %d results, want at least 2", len(results))
+	}
+
+	var buf bytes.Buffer
+	if err := output.Extract(&buf, results); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if want := results[0].EQLQuery.String() + "\n"; buf.String() != want {
+		t.Errorf("Extract wrote %q, want only the top match %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := output.Extract(&buf, nil); !errors.Is(err, output.ErrNoMatches) {
+		t.Errorf("Extract with no results = %v, want ErrNoMatches", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Extract with no results wrote %q, want nothing", buf.String())
+	}
+}
+
+func TestFormatMulti(t *testing.T) {
+	concepts := newFixtureEngine(t, srlFixture).MultiSearch("show bgp neighbors and their interfaces on leaf1")
+	if len(concepts) != 2 {
+		t.Fatalf("got %d concepts, want 2", len(concepts))
+	}
+	concepts = append(concepts, search.ConceptResults{Concept: "zzqqx"})
+	format := func(formatter output.MultiFormatter) string {
+		var buf bytes.Buffer
+		if err := formatter.FormatMulti(&buf, concepts, output.Options{}); err != nil {
+			t.Fatalf("FormatMulti: %v", err)
+		}
+		return buf.String()
+	}
+
+	var doc struct {
+		Concepts []struct {
+			Concept  string `json:"concept"`
+			TopMatch *struct {
+				Table string `json:"table"`
+			} `json:"topMatch"`
+		} `json:"concepts"`
+	}
+	if err := json.Unmarshal([]byte(format(output.JSON{})), &doc); err != nil {
+		t.Fatalf("JSON output does not parse: %v", err)
+	}
+	if len(doc.Concepts) != 3 || doc.Concepts[0].TopMatch == nil || doc.Concepts[0].TopMatch.Table != concepts[0].Results[0].Key || doc.Concepts[2].TopMatch != nil {
+		t.Errorf("JSON concepts = %+v, want a top match for each concept that has one", doc.Concepts)
+	}
+
+	yaml := format(output.YAML{})
+	for _, want := range []string{"concepts:\n  - concept: \"show bgp neighbors\"\n    topMatch:\n", "  - concept: \"zzqqx\"\n"} {
+		if !strings.Contains(yaml, want) {
+			t.Errorf("YAML output lacks %q:\n%s", want, yaml)
+		}
+	}
+
+	text := format(output.Text{})
+	for _, concept := range concepts {
+		if !strings.Contains(text, "Concept: "+concept.Concept+"\n") {
+			t.Errorf("text output lacks a heading for %q:\n%s", concept.Concept, text)
+		}
+	}
+
+	tmpl, err := output.NewTemplate(`{{len .Results}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := any(tmpl).(output.MultiFormatter); ok {
+		t.Error("Template implements MultiFormatter, but templates only know TemplateData")
+	}
+}
+
+func TestOutputSchema(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+	results := engine.IndexedSearch("interface statistics")
+	concepts := engine.MultiSearch("show bgp neighbors and their interfaces on leaf1")
+
+	documents := map[string]func(w *bytes.Buffer) error{
+		"results":  func(w *bytes.Buffer) error { return output.JSON{}.Format(w, results, output.Options{}) },
+		"no match": func(w *bytes.Buffer) error { return output.JSON{}.Format(w, nil, output.Options{}) },
+		"multi":    func(w *bytes.Buffer) error { return output.JSON{}.FormatMulti(w, concepts, output.Options{}) },
+	}
+	schema := output.Schema()
+	for name, write := range documents {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var decoded any
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("%s output does not parse: %v", name, err)
+		}
+		checkSchema(t, name, decoded, schema)
+	}
+
+	// Each alternative rejects the others, so a document matches only one
+	if problems := schemaProblems("no match", map[string]any{"error": "No matches found", "results": []any{"x"}}, schema); len(problems) == 0 {
+		t.Error("no-match document with results passes the schema")
+	}
+}
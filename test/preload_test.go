@@ -0,0 +1,52 @@
+// Package test contains behavioral tests for preloading embeddings.
+package test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/cache"
+	"github.com/eda-labs/eda-embeddingsearch/internal/download"
+	"github.com/eda-labs/eda-embeddingsearch/internal/preload"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+func TestPreload(t *testing.T) {
+	embedDir := t.TempDir()
+	source, err := download.NewDownloaderWithDir(embedDir).Describe(models.SRL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A release already in the embed dir is not downloaded again
+	data, err := os.ReadFile(srlFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(source.Path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cachePath := cache.NewCacheManager().GetBinaryCachePath(source.Path)
+	entries := len(loadFixtureDB(t, srlFixture).Table)
+
+	timing, err := preload.New(embedDir).Platform(context.Background(), models.SRL, "")
+	if err != nil {
+		t.Fatalf("Platform: %v", err)
+	}
+	if timing.Path != source.Path || timing.Entries != entries || timing.Cached {
+		t.Errorf("first preload = %+v, want %s indexed with %d entries", timing, source.Path, entries)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("binary cache not written: %v", err)
+	}
+
+	// A fresh preloader finds the cache written by the first one
+	timing, err = preload.New(embedDir).Platform(context.Background(), models.SRL, "")
+	if err != nil {
+		t.Fatalf("Platform: %v", err)
+	}
+	if !timing.Cached || timing.Entries != entries {
+		t.Errorf("second preload = %+v, want %d entries loaded from cache", timing, entries)
+	}
+}
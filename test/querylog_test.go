@@ -0,0 +1,134 @@
+// Package test contains behavioral tests for the query log.
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/querylog"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+func TestQueryLogRecord(t *testing.T) {
+	results := newFixtureEngine(t, srlFixture).IndexedSearch("interfaces on leaf1")
+	if len(results) == 0 {
+		t.Fatal("no results")
+	}
+
+	var buf bytes.Buffer
+	logger := querylog.New(&buf)
+	logger.Log("interfaces on leaf1", models.SRL, results)
+	logger.Log("nothing", models.SROS, nil)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d log lines, want 2:\n%s", len(lines), buf.String())
+	}
+
+	var record struct {
+		Time     string  `json:"time"`
+		Query    string  `json:"query"`
+		Platform string  `json:"platform"`
+		Table    string  `json:"table"`
+		Score    float64 `json:"score"`
+		EQL      string  `json:"eql"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if record.Time == "" || record.Query != "interfaces on leaf1" || record.Platform != "srl" ||
+		record.Table != results[0].Key || record.Score != results[0].Score || record.EQL != results[0].EQLQuery.String() {
+		t.Errorf("record = %+v, want the query and its top match %s", record, results[0].Key)
+	}
+	if strings.Contains(lines[1], `"table"`) {
+		t.Errorf("record without results names a table: %s", lines[1])
+	}
+
+	// A nil logger is the disabled default and must be safe to use
+	var disabled *querylog.Logger
+	disabled.Log("query", models.SRL, results)
+	if err := disabled.Close(); err != nil {
+		t.Errorf("closing a nil logger: %v", err)
+	}
+}
+
+func TestQueryLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.log")
+	file, err := querylog.OpenRotatingFile(path, 100, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	record := []byte(strings.Repeat("x", 59) + "\n")
+	for i := 0; i < 5; i++ {
+		if _, err := file.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("missing %s: %v", name, err)
+		}
+		if len(data) != len(record) {
+			t.Errorf("%s holds %d bytes, want one %d byte record", name, len(data), len(record))
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("kept more than 2 backups (stat error %v)", err)
+	}
+}
+
+func TestQueryLogRotationFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.log")
+	file, err := querylog.OpenRotatingFile(path, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// A non-empty directory in place of the backup cannot be removed
+	blocker := filepath.Join(path+".1", "blocker")
+	if err := os.MkdirAll(blocker, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stderr.Close()
+	realStderr := os.Stderr
+	os.Stderr = stderr
+	defer func() { os.Stderr = realStderr }()
+
+	record := []byte(strings.Repeat("x", 59) + "\n")
+	for i := 0; i < 2; i++ {
+		if _, err := file.Write(record); err != nil {
+			t.Fatalf("write %d after a failed rotation: %v", i+1, err)
+		}
+	}
+	if data, _ := os.ReadFile(path); len(data) != 2*len(record) {
+		t.Errorf("%s holds %d bytes, want both records appended", path, len(data))
+	}
+	if warning, _ := os.ReadFile(stderr.Name()); !strings.Contains(string(warning), "failed to rotate query log") {
+		t.Errorf("stderr = %q, want the rotation error", warning)
+	}
+
+	// Rotation resumes once the obstacle is gone
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); len(data) != len(record) {
+		t.Errorf("%s holds %d bytes after rotating, want one record", path, len(data))
+	}
+}
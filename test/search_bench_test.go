@@ -0,0 +1,75 @@
+// Package test contains benchmarks for the search engine.
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/embedding"
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+const benchmarkDBSize = 5000
+
+func BenchmarkScoreEntry(b *testing.B) {
+	engine := search.NewEngine(embedding.NewSyntheticDB(benchmarkDBSize))
+	key := ".namespace.node.srl.interface.statistics"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := engine.ScoreEntry(key, "show interface statistics on leaf1"); !ok {
+			b.Fatalf("key %s not found in synthetic db", key)
+		}
+	}
+}
+
+func BenchmarkIndexedSearch(b *testing.B) {
+	engine := search.NewEngine(embedding.NewSyntheticDB(benchmarkDBSize))
+	queries := []string{
+		"show interface statistics on leaf1",
+		"top 5 processes by memory",
+		"bgp neighbors that are not established",
+		"sros port state",
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.IndexedSearch(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkInterfaceCandidates(b *testing.B) {
+	db := embedding.NewSyntheticDB(benchmarkDBSize)
+	for _, bench := range []struct {
+		name  string
+		limit int
+	}{
+		{"unlimited", 0},
+		{"limit-500", 500},
+		{"limit-50", 50},
+	} {
+		engine := search.NewEngine(db, search.WithInterfaceCandidateLimit(bench.limit))
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				engine.IndexedSearch("sros interfaces")
+			}
+		})
+	}
+}
+
+func BenchmarkBuildInvertedIndex(b *testing.B) {
+	table := embedding.NewSyntheticDB(benchmarkDBSize).Table
+
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				embedding.BuildInvertedIndexWithWorkers(models.NewEmbeddingDB(table), workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,1061 @@
+// Package test contains behavioral tests for the search engine's public API.
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eda-labs/eda-embeddingsearch/internal/constants"
+	"github.com/eda-labs/eda-embeddingsearch/internal/embedding"
+	"github.com/eda-labs/eda-embeddingsearch/internal/eql"
+	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
+)
+
+func TestIndexedSearchEmptyQuery(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	for _, query := range []string{"", "   ", "\t\n"} {
+		if results := engine.IndexedSearch(query); len(results) != 0 {
+			t.Errorf("IndexedSearch(%q) returned %d results, want none", query, len(results))
+		}
+	}
+}
+
+func TestScoreEntryShortQueries(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+	const key = ".namespace.node.srl.interface"
+
+	for _, query := range []string{"", "interface"} {
+		if _, ok := engine.ScoreEntry(key, query); !ok {
+			t.Errorf("ScoreEntry(%q, %q) did not find the key", key, query)
+		}
+	}
+
+	if results := engine.IndexedSearch("interface"); len(results) == 0 {
+		t.Error("single-word query returned no results")
+	}
+}
+
+func TestStatisticsAbbreviation(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+	const key = ".namespace.node.srl.interface.statistics"
+
+	want, _ := engine.ScoreEntry(key, "interface statistics")
+	for _, query := range []string{"interface stats", "intf stats"} {
+		if got, _ := engine.ScoreEntry(key, query); got != want {
+			t.Errorf("ScoreEntry(%q) = %.1f, want %.1f as for \"interface statistics\"", query, got, want)
+		}
+	}
+}
+
+func TestSynonymSubstringChecks(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	pairs := map[string]string{
+		"show iface stats":    "show interface statistics",
+		"intf stats on leaf1": "interface statistics on leaf1",
+	}
+	for query, spelledOut := range pairs {
+		got, want := engine.IndexedSearch(query), engine.IndexedSearch(spelledOut)
+		if len(got) == 0 || len(want) == 0 {
+			t.Fatalf("no results for %q or %q", query, spelledOut)
+		}
+		if got[0].Key != want[0].Key || got[0].Score != want[0].Score {
+			t.Errorf("%q: top %s (%.1f), want %s (%.1f) as for %q", query, got[0].Key, got[0].Score, want[0].Key, want[0].Score, spelledOut)
+		}
+	}
+}
+
+func TestQueryRewriter(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	rewriter := func(query string) string {
+		query = strings.ReplaceAll(query, "core-fabric", "spine1 and spine2")
+		return strings.TrimSpace(strings.TrimPrefix(query, "INC-1234:"))
+	}
+	engine := search.NewEngine(db, search.WithQueryRewriter(rewriter))
+
+	results := engine.IndexedSearch("INC-1234: bgp neighbors on core-fabric")
+	if len(results) == 0 {
+		t.Fatal("expected results")
+	}
+
+	const want = `.namespace.node.name in ["spine1", "spine2"]`
+	if !strings.Contains(results[0].EQLQuery.WhereClause, want) {
+		t.Errorf("where clause %q does not contain %q", results[0].EQLQuery.WhereClause, want)
+	}
+}
+
+func TestMaxFields(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	const query = "show errors traffic packets on interface statistics"
+
+	capped := search.NewEngine(db, search.WithMaxFields(1)).IndexedSearch(query)
+	if len(capped) == 0 || len(capped[0].EQLQuery.Fields) != 1 {
+		t.Fatalf("WithMaxFields(1) results = %+v, want one field", capped)
+	}
+
+	unlimited := search.NewEngine(db, search.WithMaxFields(0)).IndexedSearch(query)
+	if len(unlimited) == 0 || len(unlimited[0].EQLQuery.Fields) <= constants.MaxExtractedFields {
+		t.Fatalf("WithMaxFields(0) results = %+v, want more than %d fields", unlimited, constants.MaxExtractedFields)
+	}
+}
+
+func TestInMemoryDB(t *testing.T) {
+	const key = ".namespace.node.srl.interface.statistics"
+	db := embedding.FromTable(map[string]models.EmbeddingEntry{
+		key: models.NewEmbeddingEntry(key, "Interface statistics counters", []string{"in-octets", "out-octets"}),
+		".namespace.node.srl.platform.control.cpu": models.NewEmbeddingEntry(
+			".namespace.node.srl.platform.control.cpu", "CPU utilization", []string{"total"}),
+	})
+
+	if err := db.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	results := search.NewEngine(db).IndexedSearch("interface statistics")
+	if len(results) == 0 || results[0].Key != key {
+		t.Fatalf("top result = %+v, want %s", results, key)
+	}
+	if got := results[0].AvailableFields; !slices.Equal(got, []string{"in-octets", "out-octets"}) {
+		t.Errorf("available fields = %v", got)
+	}
+
+	if db := models.NewEmbeddingDB(nil); db.Table == nil || len(db.Table) != 0 {
+		t.Errorf("NewEmbeddingDB(nil) table = %v, want empty map", db.Table)
+	}
+}
+
+func TestDescriptionFallback(t *testing.T) {
+	const rate = ".namespace.node.srl.interface.traffic-rate"
+	const info = ".namespace.node.srl.system.information"
+	filler := strings.Repeat("counter ", 40)
+	db := embedding.FromTable(map[string]models.EmbeddingEntry{
+		// The description words beyond the indexed Text tokens
+		rate: models.NewEmbeddingEntry(rate, "Rates per "+filler+"reflecting link utilization and health", []string{"in-bps"}),
+		info: models.NewEmbeddingEntry(info+" health overview", "System version", []string{"version"}),
+	})
+
+	engine := search.NewEngine(db)
+	results := engine.IndexedSearch("link utilization health")
+	if topKey(results) != rate {
+		t.Errorf("top match = %s, want %s found by its description", topKey(results), rate)
+	}
+
+	report := engine.Diagnose("link utilization health", rate)
+	if !strings.Contains(report, "Indexed candidate: yes, via description match on") {
+		t.Errorf("Diagnose does not report the description match:\n%s", report)
+	}
+	if strings.Contains(report, "interface table") {
+		t.Errorf("Diagnose reports a description match as an interface table:\n%s", report)
+	}
+}
+
+func TestAdditionalDBs(t *testing.T) {
+	const (
+		shared  = ".namespace.node.srl.interface.statistics"
+		srlOnly = ".namespace.node.srl.interface.traffic-rate"
+		extra   = ".namespace.node.srl.interface.ethernet.statistics"
+	)
+	newDB := func(name string, keys ...string) *models.EmbeddingDB {
+		table := make(map[string]models.EmbeddingEntry)
+		for _, key := range keys {
+			table[key] = models.NewEmbeddingEntry(key, "Interface statistics counters", []string{"in-octets"})
+		}
+		db := embedding.FromTable(table)
+		db.Name = name
+		return db
+	}
+	primary, secondary := newDB("primary", shared, srlOnly), newDB("secondary", shared, extra)
+
+	sources := func(engine *search.Engine) map[string]string {
+		sources := make(map[string]string)
+		for _, result := range engine.IndexedSearch("interface statistics counters") {
+			if _, seen := sources[result.Key]; seen {
+				t.Errorf("%s listed more than once", result.Key)
+			}
+			sources[result.Key] = result.Source
+		}
+		return sources
+	}
+
+	// Each table reports the database it came from; a table in both scores
+	// the same in each, so the database added first wins. Nil databases are
+	// skipped.
+	got := sources(search.NewEngine(primary, search.WithAdditionalDBs(nil, secondary)))
+	if want := map[string]string{shared: "primary", srlOnly: "primary", extra: "secondary"}; !maps.Equal(got, want) {
+		t.Errorf("result sources = %v, want %v", got, want)
+	}
+	got = sources(search.NewEngine(secondary, search.WithAdditionalDBs(primary)))
+	if want := map[string]string{shared: "secondary", srlOnly: "primary", extra: "secondary"}; !maps.Equal(got, want) {
+		t.Errorf("result sources with the databases swapped = %v, want %v", got, want)
+	}
+}
+
+func TestDedupKeepsBestScore(t *testing.T) {
+	const key = ".namespace.node.srl.interface.statistics"
+	table := func(description string) *models.EmbeddingDB {
+		return embedding.FromTable(map[string]models.EmbeddingEntry{
+			key: models.NewEmbeddingEntry(key, description, []string{"in-octets"}),
+		})
+	}
+	weak, strong := table("Counters"), table("Interface statistics counters")
+	weak.Name, strong.Name = "weak", "strong"
+
+	results := search.NewEngine(weak, search.WithAdditionalDBs(strong)).IndexedSearch("interface statistics counters")
+	if len(results) != 1 || results[0].Source != "strong" {
+		t.Fatalf("results = %+v, want one result from the strong database", results)
+	}
+
+	all := search.NewEngine(weak, search.WithAdditionalDBs(strong), search.WithDuplicateKeys()).IndexedSearch("interface statistics counters")
+	if len(all) != 2 || all[0].Source != "strong" || all[1].Source != "weak" {
+		t.Errorf("WithDuplicateKeys results = %+v, want both databases, strong first", all)
+	}
+}
+
+func TestInterfaceCandidatesNotDoubleCounted(t *testing.T) {
+	engine := newFixtureEngine(t, srosFixture)
+	const query = "router interface state"
+
+	results := engine.IndexedSearch(query)
+	for _, result := range results {
+		want, _ := engine.ScoreEntry(result.Key, query)
+		if result.Score != want {
+			t.Errorf("%s scored %.1f in search, %.1f by ScoreEntry", result.Key, result.Score, want)
+		}
+	}
+}
+
+func TestQueryCache(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	engine := search.NewEngine(db, search.WithQueryCache(2))
+	uncached := search.NewEngine(db)
+
+	const query = "interface statistics on leaf1"
+	first := engine.IndexedSearch(query)
+	if len(first) == 0 {
+		t.Fatal("expected results")
+	}
+	first[0].Key = "mutated"
+
+	second := engine.IndexedSearch("  interface   statistics on leaf1 ")
+	want := uncached.IndexedSearch(query)
+	if second[0].Key != want[0].Key || second[0].EQLQuery.String() != want[0].EQLQuery.String() {
+		t.Errorf("cached top result = %s, want %s", second[0].EQLQuery.String(), want[0].EQLQuery.String())
+	}
+
+	// Different node names must not share an entry
+	other := engine.IndexedSearch("interface statistics on leaf2")
+	if other[0].EQLQuery.WhereClause == second[0].EQLQuery.WhereClause {
+		t.Errorf("leaf2 query reused leaf1 results: %s", other[0].EQLQuery.WhereClause)
+	}
+
+	// Adding a table invalidates cached results
+	engine.IndexedSearch("interface statistics counters")
+	const key = ".namespace.node.srl.interface.statistics.extra-counters"
+	db.Table[key] = models.NewEmbeddingEntry(key, "Extra interface statistics counters", []string{"crc-errors"})
+	db.InvertedIndex["counters"] = append(db.InvertedIndex["counters"], key)
+
+	if results := engine.IndexedSearch("interface statistics counters"); !containsKey(results, key) {
+		t.Errorf("results after adding %s do not include it", key)
+	}
+	engine.ClearQueryCache()
+}
+
+func TestQueryCacheNestedWrites(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	engine := search.NewEngine(db, search.WithQueryCache(2))
+	const query = "compare interface statistics by in-octets between leaf1 and leaf2 every 5 seconds"
+	want := search.NewEngine(db).IndexedSearch(query)
+
+	first := engine.IndexedSearch(query)
+	top := &first[0]
+	if len(top.Comparison) == 0 || len(top.EQLQuery.Fields) == 0 || top.EQLQuery.Delta == nil || len(top.AvailableFields) == 0 {
+		t.Fatalf("top result lacks nested data to mutate: %+v", *top)
+	}
+
+	// The CLI rewrites comparison queries in place, e.g. for -namespace
+	top.Comparison[0].EQLQuery = top.Comparison[0].EQLQuery.WithNamespace("eda")
+	top.Comparison[1].EQLQuery.Fields[0] = "mutated"
+	top.EQLQuery.Fields[0] = "mutated"
+	top.EQLQuery.Delta.Value = 99
+	top.AvailableFields[0] = "mutated"
+
+	second := engine.IndexedSearch(query)[0]
+	if !reflect.DeepEqual(second, want[0]) {
+		t.Errorf("cached result changed by writes to a returned copy:\n got %+v\nwant %+v", second, want[0])
+	}
+}
+
+func TestQueryCacheConcurrent(t *testing.T) {
+	engine := search.NewEngine(loadFixtureDB(t, srlFixture), search.WithQueryCache(4))
+	queries := []string{"bgp neighbors", "interfaces that are up", "cpu on leaf1", "top 5 processes by memory", "arp entries"}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				if i%4 == 0 && j%10 == 0 {
+					engine.ClearQueryCache()
+				}
+				engine.IndexedSearch(queries[(i+j)%len(queries)])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func containsKey(results []models.SearchResult, key string) bool {
+	for _, result := range results {
+		if result.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func TestConversationalPrefixes(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+	const key = ".namespace.node.srl.interface"
+
+	want, _ := engine.ScoreEntry(key, "interfaces")
+	for _, query := range []string{
+		"show me the interfaces",
+		"Can you please show me the interfaces",
+		"I want to see the interfaces",
+	} {
+		if got, _ := engine.ScoreEntry(key, query); got != want {
+			t.Errorf("ScoreEntry(%q) = %.2f, want %.2f", query, got, want)
+		}
+	}
+
+	results := engine.IndexedSearch("show me the interfaces on leaf1")
+	if len(results) == 0 || !strings.Contains(results[0].EQLQuery.WhereClause, `"leaf1"`) {
+		t.Errorf("node filter lost after stripping lead-in: %+v", results)
+	}
+}
+
+func TestSearchWithStats(t *testing.T) {
+	engine := search.NewEngine(loadFixtureDB(t, srlFixture), search.WithQueryCache(1))
+
+	results, stats := engine.SearchWithStats("bgp neighbors")
+	if stats.Path != search.PathIndexed || stats.Cached {
+		t.Errorf("stats = %+v, want uncached indexed search", stats)
+	}
+	if stats.CandidateKeys < stats.Scored || stats.Scored < stats.Results || stats.Results != len(results) {
+		t.Errorf("inconsistent counts: %+v with %d results", stats, len(results))
+	}
+
+	if _, stats := engine.SearchWithStats("bgp neighbors"); !stats.Cached || stats.Results != len(results) {
+		t.Errorf("repeated query stats = %+v, want cached with %d results", stats, len(results))
+	}
+
+	// A cache hit reports the path the results were first found with
+	engine.SearchWithStats("neighbo")
+	if _, stats := engine.SearchWithStats("neighbo"); !stats.Cached || !stats.Fallback || stats.Path != search.PathFull {
+		t.Errorf("repeated fallback query stats = %+v, want cached full scan fallback", stats)
+	}
+}
+
+func TestFullSearch(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	indexed := engine.IndexedSearch("bgp neighbors")
+	full, stats := engine.FullSearchWithStats("bgp neighbors")
+	if stats.Path != search.PathFull || stats.Fallback {
+		t.Errorf("stats = %+v, want forced full scan", stats)
+	}
+	if len(full) == 0 || full[0].Key != indexed[0].Key {
+		t.Errorf("full scan top result differs from indexed search: %+v", full)
+	}
+
+	// A partial word is in no index entry, so the indexed path falls back
+	results, stats := engine.SearchWithStats("neighbo")
+	if !stats.Fallback || stats.Path != search.PathFull || len(results) == 0 {
+		t.Errorf("partial word stats = %+v with %d results, want fallback results", stats, len(results))
+	}
+
+	if results := engine.IndexedSearch("xyzzy quux"); len(results) != 0 {
+		t.Errorf("nonsense query returned %d results", len(results))
+	}
+}
+
+func TestMinScore(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	const query = "interfaces that are up"
+
+	all := search.NewEngine(db).IndexedSearch(query)
+	if len(all) < 3 {
+		t.Fatalf("expected several results, got %d", len(all))
+	}
+	threshold := all[1].Score
+
+	filtered := search.NewEngine(db, search.WithMinScore(threshold)).IndexedSearch(query)
+	for _, result := range filtered {
+		if result.Score < threshold {
+			t.Errorf("result %s scored %.2f, below %.2f", result.Key, result.Score, threshold)
+		}
+	}
+	if len(filtered) == 0 || len(filtered) >= len(all) {
+		t.Errorf("got %d results with min score, want between 1 and %d", len(filtered), len(all)-1)
+	}
+
+	if results := search.NewEngine(db, search.WithMinScore(all[0].Score+1)).IndexedSearch(query); len(results) != 0 {
+		t.Errorf("threshold above the top score returned %d results", len(results))
+	}
+}
+
+func TestNormalizedScore(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+
+	results := search.NewEngine(db).IndexedSearch("interfaces that are up")
+	if len(results) < 3 {
+		t.Fatalf("expected several results, got %d", len(results))
+	}
+	if results[0].NormalizedScore != 1 {
+		t.Errorf("top result normalized to %v, want 1", results[0].NormalizedScore)
+	}
+	for i, result := range results[1:] {
+		if result.NormalizedScore <= 0 || result.NormalizedScore > 1 {
+			t.Errorf("%s normalized to %v, want within (0, 1]", result.Key, result.NormalizedScore)
+		}
+		if want := result.Score / results[0].Score; math.Abs(result.NormalizedScore-want) > 1e-9 {
+			t.Errorf("%s normalized to %v, want its score relative to the top, %v", result.Key, result.NormalizedScore, want)
+		}
+		if result.NormalizedScore > results[i].NormalizedScore {
+			t.Errorf("%s normalized above the result ranked before it", result.Key)
+		}
+	}
+
+	// A best score of zero or less leaves nothing to normalize against
+	config := search.DefaultScoringConfig()
+	config.DeprecatedPathPatterns = []string{"namespace"}
+	config.DeprecatedPathPenalty = -10000
+	config.CandidateThreshold = math.Inf(-1)
+	negative := search.NewEngine(db, search.WithScoringConfig(config), search.WithMinScore(math.Inf(-1))).IndexedSearch("interfaces that are up")
+	if len(negative) == 0 || negative[0].Score > 0 {
+		t.Fatalf("expected results with negative scores, got %s", describeRanking(negative))
+	}
+	for _, result := range negative {
+		if result.NormalizedScore != 0 {
+			t.Errorf("%s scored %.1f normalized to %v, want 0", result.Key, result.Score, result.NormalizedScore)
+		}
+	}
+}
+
+func TestNeighborDisambiguation(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+	const (
+		bgpNeighbor = ".namespace.node.srl.network-instance.protocols.bgp.neighbor"
+		arpNeighbor = ".namespace.node.srl.interface.subinterface.ipv4.arp.neighbor"
+		ndNeighbor  = ".namespace.node.srl.interface.subinterface.ipv6.neighbor-discovery.neighbor"
+	)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"bgp neighbor state", bgpNeighbor},
+		{"ebgp neighbor session state", bgpNeighbor},
+		{"neighbors in as 65001", bgpNeighbor},
+		{"arp neighbor state", arpNeighbor},
+		{"ip neighbor entries", arpNeighbor},
+		{"ipv6 neighbor discovery state", ndNeighbor},
+	}
+	for _, tt := range tests {
+		results := engine.IndexedSearch(tt.query)
+		if len(results) == 0 || results[0].Key != tt.want {
+			t.Errorf("IndexedSearch(%q) top result = %v, want %s", tt.query, topKey(results), tt.want)
+		}
+	}
+
+	bgpScore, _ := engine.ScoreEntry(bgpNeighbor, "arp neighbor state")
+	ndScore, _ := engine.ScoreEntry(ndNeighbor, "arp neighbor state")
+	if bgpScore >= ndScore {
+		t.Errorf("BGP neighbor scored %.2f for an ARP query, want below neighbor discovery (%.2f)", bgpScore, ndScore)
+	}
+}
+
+func TestMACOnlyQuery(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	results := engine.IndexedSearch("who has aa-bb-cc-dd-ee-ff")
+	if len(results) == 0 || !strings.Contains(results[0].EQLQuery.WhereClause, `"AA:BB:CC:DD:EE:FF"`) {
+		t.Errorf("MAC-only query results = %+v, want a MAC table filtered on the address", results)
+	}
+}
+
+func TestStreamingMultipleFields(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	results := engine.IndexedSearch("stream interface in-octets and out-octets every 5 seconds on leaf1")
+	if len(results) == 0 {
+		t.Fatal("no results")
+	}
+
+	const expected = `.namespace.node.srl.interface.statistics fields [in-octets, out-octets] where (.namespace.node.name = "leaf1") delta seconds 5`
+	if got := results[0].EQLQuery.String(); got != expected {
+		t.Errorf("EQL = %s, want %s", got, expected)
+	}
+}
+
+func TestCandidateThresholdConfig(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	const query = "interface statistics"
+
+	strict := search.DefaultScoringConfig()
+	strict.CandidateThreshold = 1000
+	if results := search.NewEngine(db, search.WithScoringConfig(strict)).IndexedSearch(query); len(results) != 0 {
+		t.Errorf("strict threshold kept %d results, want none", len(results))
+	}
+
+	// The SR OS threshold leaves SR Linux tables alone
+	srosOnly := search.DefaultScoringConfig()
+	srosOnly.SROSCandidateThreshold = 1000
+	got := search.NewEngine(db, search.WithScoringConfig(srosOnly)).IndexedSearch(query)
+	if defaults := search.NewEngine(db).IndexedSearch(query); len(got) != len(defaults) {
+		t.Errorf("SR OS threshold changed SR Linux results: %d, want %d", len(got), len(defaults))
+	}
+}
+
+func TestFleetWideResults(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	results := engine.IndexedSearch("interfaces on every node")
+	if len(results) == 0 || !results[0].FleetWide || results[0].EQLQuery.WhereClause != "" {
+		t.Fatalf("fleet-wide results = %+v, want an unfiltered fleet-wide top match", results)
+	}
+	if engine.IndexedSearch("interfaces")[0].Key != results[0].Key {
+		t.Error("fleet-wide wording changed the top table")
+	}
+
+	data, err := json.Marshal(&results[0])
+	if err != nil || !strings.Contains(string(data), `"fleetWide":true`) {
+		t.Errorf("JSON = %s (%v), want fleetWide", data, err)
+	}
+}
+
+func TestReferenceText(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	const query = "interface statistics"
+
+	if results := search.NewEngine(db).IndexedSearch(query); len(results) == 0 || results[0].ReferenceText != "" {
+		t.Errorf("reference text set by default: %+v", results)
+	}
+
+	results := search.NewEngine(db, search.WithReferenceText()).IndexedSearch(query)
+	if len(results) == 0 || results[0].ReferenceText != db.Table[results[0].Key].ReferenceText {
+		t.Fatalf("results = %+v, want the entry's reference text", results)
+	}
+	data, err := json.Marshal(&results[0])
+	if err != nil || !strings.Contains(string(data), `"referenceText":`) {
+		t.Errorf("JSON = %s (%v), want referenceText", data, err)
+	}
+}
+
+func TestAFISAFIRanking(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	const afiSafi = ".namespace.node.srl.network-instance.protocols.bgp.neighbor.afi-safi"
+	if got := topKey(engine.IndexedSearch("ipv6 bgp neighbors")); got != afiSafi {
+		t.Errorf("top match for ipv6 bgp neighbors = %s, want %s", got, afiSafi)
+	}
+	if got := topKey(engine.IndexedSearch("bgp neighbors")); got == afiSafi {
+		t.Error("AFI-SAFI table ranked first without an address family")
+	}
+}
+
+func topKey(results []models.SearchResult) string {
+	if len(results) == 0 {
+		return "<none>"
+	}
+	return results[0].Key
+}
+
+func TestScoreAll(t *testing.T) {
+	db := loadFixtureDB(t, srlFixture)
+	engine := search.NewEngine(db)
+	const query = "interface statistics"
+
+	scored := engine.ScoreAll(query)
+	if len(scored) <= len(engine.IndexedSearch(query)) {
+		t.Errorf("ScoreAll returned %d entries, want more than IndexedSearch", len(scored))
+	}
+	for i, entry := range scored {
+		if entry.Score <= 0 {
+			t.Errorf("entry %s scored %.2f, want only positive scores", entry.Key, entry.Score)
+		}
+		if i > 0 && entry.Score > scored[i-1].Score {
+			t.Errorf("entries not sorted: %s (%.2f) after %s (%.2f)", entry.Key, entry.Score, scored[i-1].Key, scored[i-1].Score)
+		}
+		if want, _ := engine.ScoreEntry(entry.Key, query); entry.Score != want {
+			t.Errorf("ScoreAll scored %s %.2f, ScoreEntry %.2f", entry.Key, entry.Score, want)
+		}
+	}
+
+	threshold := scored[len(scored)/2].Score
+	for _, entry := range search.NewEngine(db, search.WithMinScore(threshold)).ScoreAll(query) {
+		if entry.Score < threshold {
+			t.Errorf("entry %s scored %.2f, below %.2f", entry.Key, entry.Score, threshold)
+		}
+	}
+
+	if scored := engine.ScoreAll("   "); len(scored) != 0 {
+		t.Errorf("blank query scored %d entries", len(scored))
+	}
+}
+
+func TestInterfaceCandidateLimit(t *testing.T) {
+	table := map[string]models.EmbeddingEntry{
+		".namespace.node.sros.state.port": models.NewEmbeddingEntry(".namespace.node.sros.state.port Port state", "Port state", []string{"oper-state"}),
+	}
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf(".namespace.node.srl.subinterfaces.group-%d", i)
+		table[key] = models.NewEmbeddingEntry(key+" Subinterface group", "Subinterface group", []string{"index"})
+	}
+	db := models.NewEmbeddingDB(table)
+	embedding.BuildInvertedIndex(db)
+
+	_, stats := search.NewEngine(db).SearchWithStats("sros interfaces")
+	if stats.CandidateKeys != len(table) {
+		t.Errorf("uncapped search had %d candidates, want %d", stats.CandidateKeys, len(table))
+	}
+
+	_, stats = search.NewEngine(db, search.WithInterfaceCandidateLimit(5)).SearchWithStats("sros interfaces")
+	if stats.CandidateKeys != 5 {
+		t.Errorf("capped search had %d candidates, want 5", stats.CandidateKeys)
+	}
+}
+
+func TestCompareQueries(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	results := engine.IndexedSearch("compare interface errors between leaf1 and leaf2")
+	if len(results) == 0 {
+		t.Fatal("no results")
+	}
+	top := results[0]
+	if len(top.Comparison) != 2 {
+		t.Fatalf("comparison = %+v, want one query per node", top.Comparison)
+	}
+	for i, node := range []string{"leaf1", "leaf2"} {
+		nq := top.Comparison[i]
+		if nq.Node != node || nq.EQLQuery.WhereClause != `.namespace.node.name = "`+node+`"` {
+			t.Errorf("comparison[%d] = %+v, want a filter on %s only", i, nq, node)
+		}
+		if nq.EQLQuery.Table != top.EQLQuery.Table || !slices.Equal(nq.EQLQuery.Fields, top.EQLQuery.Fields) {
+			t.Errorf("comparison[%d] differs from the combined query beyond the node filter: %+v", i, nq.EQLQuery)
+		}
+	}
+
+	data, err := json.Marshal(&top)
+	if err != nil || !strings.Contains(string(data), `"comparison":[{"node":"leaf1"`) {
+		t.Errorf("JSON = %s (%v), want per-node comparison queries", data, err)
+	}
+
+	if results := engine.IndexedSearch("interface errors on leaf1 and leaf2"); len(results) == 0 || results[0].Comparison != nil {
+		t.Errorf("comparison added without compare intent: %+v", results)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	report := engine.Diagnose("interface statistics", ".namespace.node.srl.interface.statistics")
+	for _, want := range []string{"Indexed candidate: yes, via interface, statistics", "Score: ", "keywords:", "Candidate threshold: 10.00 (passed)", "Rank: 1 of "} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+
+	report = engine.Diagnose("interface statistics", ".namespace.node.srl.system.information")
+	for _, want := range []string{"Indexed candidate: no", "Rank: not returned"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report lacks %q:\n%s", want, report)
+		}
+	}
+
+	if report := engine.Diagnose("interfaces", ".no.such.table"); !strings.Contains(report, "not in any database") {
+		t.Errorf("report for a missing table:\n%s", report)
+	}
+}
+
+func TestMultiSearch(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	concepts := engine.MultiSearch("show bgp neighbors and their interfaces on leaf1")
+	want := []string{
+		".namespace.node.srl.network-instance.protocols.bgp.neighbor",
+		".namespace.node.srl.interface",
+	}
+	if len(concepts) != len(want) {
+		t.Fatalf("got %d concepts, want %d: %+v", len(concepts), len(want), concepts)
+	}
+	for i, concept := range concepts {
+		if topKey(concept.Results) != want[i] {
+			t.Errorf("concept %q top result = %s, want %s", concept.Concept, topKey(concept.Results), want[i])
+		}
+		if !strings.Contains(concept.Results[0].EQLQuery.WhereClause, `"leaf1"`) {
+			t.Errorf("concept %q lost the node filter: %s", concept.Concept, concept.Results[0].EQLQuery.WhereClause)
+		}
+	}
+
+	// Both halves name the same table, so they stay one concept
+	concepts = engine.MultiSearch("bgp neighbors and bgp neighbor state")
+	if len(concepts) != 1 || concepts[0].Concept != "bgp neighbors and bgp neighbor state" {
+		t.Errorf("same-table halves = %+v, want a single concept", concepts)
+	}
+
+	// A leading part that finds nothing joins the next part
+	concepts = engine.MultiSearch("zzqqx and bgp neighbors and their interfaces on leaf1")
+	if len(concepts) != 2 || concepts[0].Concept != "zzqqx and bgp neighbors" || topKey(concepts[0].Results) != want[0] {
+		t.Errorf("leading unmatched part = %+v, want it folded into the bgp neighbors concept", concepts)
+	}
+}
+
+func TestTopTalkers(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+	const key = ".namespace.node.srl.interface.statistics"
+
+	for query, limit := range map[string]int{
+		"top talkers":            constants.DefaultTopLimit,
+		"heavy hitters on leaf1": constants.DefaultTopLimit,
+		"top 3 talkers":          3,
+	} {
+		results := engine.IndexedSearch(query)
+		if got := topKey(results); got != key {
+			t.Errorf("%q: top match = %s, want %s", query, got, key)
+			continue
+		}
+		q := results[0].EQLQuery
+		if !slices.Equal(q.Fields, []string{"in-octets", "out-octets"}) {
+			t.Errorf("%q: fields = %v, want in-octets and out-octets", query, q.Fields)
+		}
+		if len(q.OrderBy) != 2 || q.OrderBy[0].Field != "in-octets" || q.OrderBy[0].Direction != "descending" ||
+			q.OrderBy[1].Field != "out-octets" || q.OrderBy[1].Direction != "descending" {
+			t.Errorf("%q: order by = %+v, want in-octets then out-octets descending", query, q.OrderBy)
+		}
+		if q.Limit != limit {
+			t.Errorf("%q: limit = %d, want %d", query, q.Limit, limit)
+		}
+	}
+}
+
+func TestFlapRanking(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+	const key = ".namespace.node.srl.interface.statistics"
+
+	for query, limit := range map[string]int{
+		"interfaces with the most flaps": constants.DefaultTopLimit,
+		"flappiest interfaces on leaf1":  constants.DefaultTopLimit,
+		"top 3 flapping interfaces":      3,
+	} {
+		results := engine.IndexedSearch(query)
+		if got := topKey(results); got != key {
+			t.Errorf("%q: top match = %s, want %s", query, got, key)
+			continue
+		}
+		q := results[0].EQLQuery
+		if len(q.OrderBy) != 1 || q.OrderBy[0].Field != "carrier-transitions" || q.OrderBy[0].Direction != "descending" {
+			t.Errorf("%q: order by = %+v, want carrier-transitions descending", query, q.OrderBy)
+		}
+		if q.Limit != limit {
+			t.Errorf("%q: limit = %d, want %d", query, q.Limit, limit)
+		}
+	}
+
+	// Tables without a transition counter get no flap sort
+	entry := &models.EmbeddingEntry{Text: `{"Fields":["name","oper-state","last-change"]}`}
+	if got := eql.ExtractOrderBy("interfaces with the most flaps", ".namespace.node.srl.interface", entry); len(got) != 0 {
+		t.Errorf("order by without a transition counter = %+v, want none", got)
+	}
+}
+
+func TestOpticalPowerQuery(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+	const key = ".namespace.node.srl.interface.transceiver.channel"
+
+	results := engine.IndexedSearch("transceivers with rx power below -10 dBm")
+	if got := topKey(results); got != key {
+		t.Fatalf("top match = %s, want %s", got, key)
+	}
+	if where := results[0].EQLQuery.WhereClause; where != "input-power < -10" {
+		t.Errorf("where = %q, want input-power < -10", where)
+	}
+
+	// Transceiver inventory queries keep the transceiver table
+	if got := topKey(engine.IndexedSearch("transceiver vendor and serial numbers")); got != ".namespace.node.srl.interface.transceiver" {
+		t.Errorf("inventory top match = %s", got)
+	}
+}
+
+func TestLazyIndexBuild(t *testing.T) {
+	indexed := loadFixtureDB(t, srlFixture)
+	want := search.NewEngine(indexed).IndexedSearch("interface statistics")
+
+	// A database without indexes, shared by two engines searching at once
+	db := models.NewEmbeddingDB(indexed.Table)
+	engines := []*search.Engine{search.NewEngine(db), search.NewEngine(db)}
+
+	var wg sync.WaitGroup
+	got := make([][]models.SearchResult, 8)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got[i] = engines[i%len(engines)].IndexedSearch("interface statistics")
+		}()
+	}
+	wg.Wait()
+
+	for i, results := range got {
+		if topKey(results) != topKey(want) || len(results) != len(want) {
+			t.Errorf("search %d on the unindexed database returned %d results topped by %s, want %d topped by %s",
+				i, len(results), topKey(results), len(want), topKey(want))
+		}
+	}
+	if !reflect.DeepEqual(db.InvertedIndex, indexed.InvertedIndex) || !reflect.DeepEqual(db.DescriptionIndex, indexed.DescriptionIndex) {
+		t.Error("lazily built indexes differ from the loader's")
+	}
+
+	// The index answers the search instead of the full-scan fallback
+	_, stats := search.NewEngine(models.NewEmbeddingDB(indexed.Table)).SearchWithStats("interface statistics")
+	if stats.Path != search.PathIndexed || stats.Fallback {
+		t.Errorf("search on an unindexed database took path %s (fallback %v), want the index", stats.Path, stats.Fallback)
+	}
+}
+
+func TestEngineClose(t *testing.T) {
+	engine := search.NewEngine(loadFixtureDB(t, srlFixture), search.WithQueryCache(8))
+	if results := engine.IndexedSearch("interface statistics"); len(results) == 0 {
+		t.Fatal("no results before Close")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := engine.Close(); err != nil {
+			t.Fatalf("Close #%d: %v", i+1, err)
+		}
+	}
+	if results := engine.IndexedSearch("interface statistics"); len(results) != 0 {
+		t.Errorf("closed engine returned %d results, want none", len(results))
+	}
+}
+
+func TestByFieldDoesNotRankOffTopicTables(t *testing.T) {
+	engine := newFixtureEngine(t, srlFixture)
+
+	// The fixture has no process table; tables merely having a name field
+	// are not matches
+	if results := engine.IndexedSearch("processes by name"); len(results) != 0 {
+		t.Errorf("off-topic by-name query matched %s", topKey(results))
+	}
+	score, ok := engine.ScoreEntry(".namespace.node.srl.network-instance", "processes by name")
+	if !ok || score >= search.DefaultScoringConfig().ByFieldMatch {
+		t.Errorf("network-instance scored %.2f for an off-topic by-name query, want less than the by-field bonus", score)
+	}
+
+	// On topic, the table holding the named field still wins
+	if got := topKey(engine.IndexedSearch("top 10 interfaces by out-discards")); got != ".namespace.node.srl.interface.statistics" {
+		t.Errorf("top match = %s, want interface statistics", got)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	srl := models.NewEmbeddingDB(map[string]models.EmbeddingEntry{
+		".namespace.node.srl.interface":            models.NewEmbeddingEntry("", "Network interfaces", []string{"name"}),
+		".namespace.node.srl.interface.statistics": models.NewEmbeddingEntry("", "Interface counters", []string{"in-octets"}),
+		".namespace.node.srl.system.information":   models.NewEmbeddingEntry("", "System information", []string{"version"}),
+		".namespace.node.srl.network-instance":     models.NewEmbeddingEntry("", "Virtual routing instances", []string{"name"}),
+		".namespace.node.srl.route-table":          models.NewEmbeddingEntry("", "Routes", []string{"prefix"}),
+	})
+	sros := models.NewEmbeddingDB(map[string]models.EmbeddingEntry{
+		".namespace.node.sros.state.port": models.NewEmbeddingEntry("", "Physical ports", []string{"oper-speed"}),
+		".namespace.node.srl.interface":   models.NewEmbeddingEntry("", "Network interfaces", []string{"name"}),
+	})
+	engine := search.NewEngine(srl, search.WithAdditionalDBs(sros))
+
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []string
+	}{
+		// Shorter paths first when the hits tie
+		{"inter", 0, []string{".namespace.node.srl.interface", ".namespace.node.srl.interface.statistics"}},
+		// More prefixed terms rank first, then shorter paths
+		{"in", 0, []string{
+			".namespace.node.srl.interface",
+			".namespace.node.srl.network-instance",
+			".namespace.node.srl.system.information",
+			".namespace.node.srl.interface.statistics",
+		}},
+		{"in", 2, []string{".namespace.node.srl.interface", ".namespace.node.srl.network-instance"}},
+		// Path matches rank before description matches
+		{"rout", 0, []string{".namespace.node.srl.route-table", ".namespace.node.srl.network-instance"}},
+		// Completed words must all match
+		{"interface stat", 0, []string{".namespace.node.srl.interface.statistics"}},
+		{"interface ", 0, []string{".namespace.node.srl.interface", ".namespace.node.srl.interface.statistics"}},
+		// Matches come from every database, each path listed once
+		{"po", 0, []string{".namespace.node.sros.state.port"}},
+		{"zzz", 0, nil},
+		{"", 0, nil},
+	}
+	for _, tt := range tests {
+		if got := engine.Suggest(tt.prefix, tt.limit); !slices.Equal(got, tt.want) {
+			t.Errorf("Suggest(%q, %d) = %q, want %q", tt.prefix, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestContiguousPhraseOutranksScatteredWords(t *testing.T) {
+	const (
+		// The scattered table would win a tie on path order
+		contiguous = ".namespace.node.srl.qos.bravo"
+		scattered  = ".namespace.node.srl.qos.alpha"
+	)
+	db := models.NewEmbeddingDB(map[string]models.EmbeddingEntry{
+		contiguous: models.NewEmbeddingEntry("", "Egress queue depth per port", []string{"depth"}),
+		scattered:  models.NewEmbeddingEntry("", "Egress depth of each port queue", []string{"depth"}),
+	})
+	engine := search.NewEngine(db)
+
+	const query = "queue depth"
+	contiguousScore, _ := engine.ScoreEntry(contiguous, query)
+	scatteredScore, _ := engine.ScoreEntry(scattered, query)
+	if contiguousScore <= scatteredScore {
+		t.Errorf("contiguous phrase scored %.1f, scattered words %.1f; want the phrase higher", contiguousScore, scatteredScore)
+	}
+	if top := topKey(engine.IndexedSearch(query)); top != contiguous {
+		t.Errorf("top result for %q = %s, want %s", query, top, contiguous)
+	}
+}
+
+func TestDeprecatedPathPenalty(t *testing.T) {
+	const (
+		normal = ".namespace.node.srl.system.logging"
+		debug  = ".namespace.node.srl.debug.logging"
+		legacy = ".namespace.node.srl.legacy.logging"
+	)
+	db := models.NewEmbeddingDB(map[string]models.EmbeddingEntry{
+		normal: models.NewEmbeddingEntry("", "System logging buffers", []string{"name"}),
+		debug:  models.NewEmbeddingEntry("", "System logging buffers", []string{"name"}),
+		legacy: models.NewEmbeddingEntry("", "System logging buffers", []string{"name"}),
+	})
+	noPenalty := search.DefaultScoringConfig()
+	noPenalty.DeprecatedPathPenalty = 0
+	penalty := search.DefaultScoringConfig().DeprecatedPathPenalty
+
+	engine := search.NewEngine(db)
+	unpenalized := search.NewEngine(db, search.WithScoringConfig(noPenalty))
+	penaltyFor := func(key, query string) float64 {
+		score, _ := engine.ScoreEntry(key, query)
+		base, _ := unpenalized.ScoreEntry(key, query)
+		return score - base
+	}
+
+	if got := penaltyFor(debug, "logging buffers"); got != penalty {
+		t.Errorf("penalty on %s = %.1f, want %.1f", debug, got, penalty)
+	}
+	if got := penaltyFor(normal, "logging buffers"); got != 0 {
+		t.Errorf("penalty on %s = %.1f, want none", normal, got)
+	}
+	if got := penaltyFor(debug, "debug logging buffers"); got != 0 {
+		t.Errorf("penalty on %s when the query names debug = %.1f, want none", debug, got)
+	}
+
+	rank := func(results []models.SearchResult, key string) int {
+		return slices.IndexFunc(results, func(r models.SearchResult) bool { return r.Key == key })
+	}
+	results := engine.IndexedSearch("logging buffers")
+	if rank(results, debug) < rank(results, normal) {
+		t.Errorf("want %s below %s\n%s", debug, normal, describeRanking(results))
+	}
+	if top := topKey(engine.IndexedSearch("debug logging buffers")); top != debug {
+		t.Errorf("top result when the query names debug = %s, want %s", top, debug)
+	}
+
+	// Custom patterns replace the defaults
+	custom := search.DefaultScoringConfig()
+	custom.DeprecatedPathPatterns = []string{"legacy"}
+	customEngine := search.NewEngine(db, search.WithScoringConfig(custom))
+	legacyScore, _ := customEngine.ScoreEntry(legacy, "logging buffers")
+	debugScore, _ := customEngine.ScoreEntry(debug, "logging buffers")
+	if legacyScore-debugScore != penalty {
+		t.Errorf("custom pattern: %s scored %.1f, %s %.1f; want only the legacy path penalized", legacy, legacyScore, debug, debugScore)
+	}
+}
+
+func TestPathFilterMatch(t *testing.T) {
+	const (
+		iface = ".namespace.node.srl.interface.statistics"
+		bgp   = ".namespace.node.srl.network-instance.protocols.bgp.neighbor"
+		sros  = ".namespace.node.sros.state.port"
+	)
+	tests := []struct {
+		name   string
+		filter search.PathFilter
+		key    string
+		want   bool
+	}{
+		{"empty", search.PathFilter{}, iface, true},
+		{"include match", search.PathFilter{Include: []string{"interface"}}, iface, true},
+		{"include miss", search.PathFilter{Include: []string{"interface"}}, bgp, false},
+		{"include ignores case", search.PathFilter{Include: []string{"INTERFACE"}}, iface, true},
+		{"exclude match", search.PathFilter{Exclude: []string{"bgp"}}, bgp, false},
+		{"exclude miss", search.PathFilter{Exclude: []string{"bgp"}}, iface, true},
+		{"both, included", search.PathFilter{Include: []string{"srl"}, Exclude: []string{"bgp"}}, iface, true},
+		{"both, excluded", search.PathFilter{Include: []string{"srl"}, Exclude: []string{"bgp"}}, bgp, false},
+		{"both, not included", search.PathFilter{Include: []string{"srl"}, Exclude: []string{"bgp"}}, sros, false},
+		{"exclude wins over include", search.PathFilter{Include: []string{"bgp"}, Exclude: []string{"neighbor"}}, bgp, false},
+		{"repeated include, first", search.PathFilter{Include: []string{"interface", "bgp"}}, iface, true},
+		{"repeated include, second", search.PathFilter{Include: []string{"interface", "bgp"}}, bgp, true},
+		{"repeated include, neither", search.PathFilter{Include: []string{"interface", "bgp"}}, sros, false},
+		{"repeated exclude, first", search.PathFilter{Exclude: []string{"sros", "bgp"}}, sros, false},
+		{"repeated exclude, second", search.PathFilter{Exclude: []string{"sros", "bgp"}}, bgp, false},
+		{"repeated exclude, neither", search.PathFilter{Exclude: []string{"sros", "bgp"}}, iface, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.key); got != tt.want {
+				t.Errorf("%+v.Match(%s) = %v, want %v", tt.filter, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualScoresTieBreak(t *testing.T) {
+	// Same depth and description, so every table scores the same
+	want := []string{
+		".namespace.node.srl.qos.zz",
+		".namespace.node.srl.qos.aaa",
+		".namespace.node.srl.qos.bbb",
+		".namespace.node.srl.qos.ccc",
+	}
+	table := make(map[string]models.EmbeddingEntry)
+	for _, key := range want {
+		table[key] = models.NewEmbeddingEntry("", "Egress buffer depth", []string{"depth"})
+	}
+
+	// Candidates are gathered from maps, so repeat to cover several orders
+	for range 10 {
+		results := search.NewEngine(models.NewEmbeddingDB(table)).IndexedSearch("buffer depth")
+		var got []string
+		for _, result := range results {
+			if result.Score != results[0].Score {
+				t.Fatalf("scores differ, the test needs a tie:\n%s", describeRanking(results))
+			}
+			got = append(got, result.Key)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("tied results = %v, want shorter paths first, then lexical order %v", got, want)
+		}
+	}
+}
@@ -6,9 +6,11 @@ package test
 import (
 	"reflect"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/eda-labs/eda-embeddingsearch/internal/search"
+	"github.com/eda-labs/eda-embeddingsearch/pkg/models"
 )
 
 func TestTokenize(t *testing.T) {
@@ -98,6 +100,35 @@ func TestExpandSynonyms(t *testing.T) {
 	}
 }
 
+func TestAppliedSynonyms(t *testing.T) {
+	got := search.AppliedSynonyms([]string{"stats", "inferface", "mtu", "stats", "bgp"})
+	want := []search.Synonym{{From: "stats", To: "statistics"}, {From: "inferface", To: "interface"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("AppliedSynonyms = %v, want %v", got, want)
+	}
+
+	engine := search.NewEngine(&models.EmbeddingDB{Table: map[string]models.EmbeddingEntry{}},
+		search.WithQueryRewriter(func(q string) string { return strings.ReplaceAll(q, "ifs", "ifaces") }))
+	got = engine.AppliedSynonyms(`show me "ifs" on ethernet-1/1 for 10.0.0.1`)
+	want = []search.Synonym{{From: "ifaces", To: "interface"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Engine.AppliedSynonyms = %v, want %v", got, want)
+	}
+}
+
+func TestTypoCorrections(t *testing.T) {
+	got := search.TypoCorrections([]string{"stats", "inferface", "statistcs", "inferface"})
+	want := []search.Synonym{{From: "inferface", To: "interface"}, {From: "statistcs", To: "statistics"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("TypoCorrections = %v, want %v", got, want)
+	}
+
+	engine := search.NewEngine(&models.EmbeddingDB{Table: map[string]models.EmbeddingEntry{}})
+	if got := engine.TypoCorrections("show interface stats on leaf1"); len(got) != 0 {
+		t.Errorf("Engine.TypoCorrections = %v, want none for synonyms only", got)
+	}
+}
+
 func TestContains(t *testing.T) {
 	tests := []struct {
 		name     string